@@ -9,6 +9,7 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/akamai/cli/v2/pkg/commands"
 	"github.com/akamai/cli/v2/pkg/config"
 	"github.com/akamai/cli/v2/pkg/terminal"
 	"github.com/akamai/cli/v2/pkg/tools"
@@ -111,8 +112,7 @@ func firstRunCheckInPath(ctx context.Context) (bool, error) {
 			return false, err
 		}
 		if !answer {
-			cfg.SetValue("cli", "install-in-path", "no")
-			if err := cfg.Save(ctx); err != nil {
+			if err := commands.SaveConfigValue(ctx, "cli", "install-in-path", "no"); err != nil {
 				return false, err
 			}
 			if _, err = firstRunCheckUpgrade(ctx, cfg, true); err != nil {
@@ -178,15 +178,13 @@ func firstRunCheckUpgrade(ctx context.Context, cfg config.Config, bannerShown bo
 		return false, err
 	}
 	if !answer {
-		cfg.SetValue("cli", "last-upgrade-check", "ignore")
-		if err := cfg.Save(ctx); err != nil {
+		if err := commands.SaveConfigValue(ctx, "cli", "last-upgrade-check", "ignore"); err != nil {
 			return false, err
 		}
 		return bannerShown, nil
 	}
 
-	cfg.SetValue("cli", "last-upgrade-check", "never")
-	if err := cfg.Save(ctx); err != nil {
+	if err := commands.SaveConfigValue(ctx, "cli", "last-upgrade-check", "never"); err != nil {
 		return false, err
 	}
 
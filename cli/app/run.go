@@ -6,15 +6,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"syscall"
 
 	"github.com/akamai/cli/v2/pkg/app"
+	"github.com/akamai/cli/v2/pkg/color"
 	"github.com/akamai/cli/v2/pkg/commands"
 	"github.com/akamai/cli/v2/pkg/config"
 	"github.com/akamai/cli/v2/pkg/log"
 	"github.com/akamai/cli/v2/pkg/terminal"
 	"github.com/akamai/cli/v2/pkg/tools"
+	"github.com/akamai/cli/v2/pkg/vault"
 	"github.com/akamai/cli/v2/pkg/version"
 	"github.com/urfave/cli/v2"
 )
@@ -24,6 +28,10 @@ func Run() int {
 	ctx := context.Background()
 	term := terminal.Color()
 	logger := log.FromContext(ctx)
+	timer := newTimings(timingsFlag(os.Args))
+	defer func() {
+		timer.write(term.Error())
+	}()
 
 	var pathErr *os.PathError
 	if err := cleanupUpgrade(); err != nil && errors.As(err, &pathErr) && pathErr.Err != syscall.ENOENT {
@@ -44,39 +52,71 @@ func Run() int {
 		return 2
 	}
 	ctx = config.Context(ctx, cfg)
+	timer.mark("load config")
 
-	cachePath, ok := cfg.GetValue("cli", "cache-path")
-	if !ok {
-		cliHome, _ := tools.GetAkamaiCliPath()
+	ctx = terminal.Context(ctx, term)
 
-		cachePath = filepath.Join(cliHome, "cache")
-		if err := os.MkdirAll(cachePath, 0700); err != nil {
-			term.WriteErrorf("Unable to create cache directory: %s", err.Error())
-			return 2
-		}
+	vaultPath, err := vault.DefaultPath()
+	if err != nil {
+		term.WriteErrorf("Unable to locate secrets vault: %s", err.Error())
+		return 2
 	}
+	ctx = vault.Context(ctx, vault.NewSession(vaultPath, vault.PromptPassphrase(term)))
 
-	ctx = terminal.Context(ctx, term)
+	if err := useProfile(cfg, term, os.Args); err != nil {
+		term.WriteErrorf("Unable to use config profile: %s", err.Error())
+		return 2
+	}
+	timer.mark("profile")
 
-	cfg.SetValue("cli", "cache-path", cachePath)
-	if err := cfg.Save(ctx); err != nil {
-		return 3
+	// a dry run must not change the CLI home, so the config is left as is and the first run and upgrade checks are skipped
+	dryRun := isDryRun(os.Args)
+	// the config is only written when something changes, so parallel processes do not rewrite it on every start
+	if !dryRun && configNeedsUpdate(ctx, cfg, os.Args) {
+		lock, err := commands.LockCliHome(ctx)
+		if err != nil {
+			term.WriteErrorf("Unable to lock akamai cli home: %s", err.Error())
+			return 3
+		}
+		// another process may have changed the config since it was loaded
+		if err := cfg.Reload(); err != nil {
+			_ = lock.Unlock()
+			term.WriteErrorf("Unable to open cli config: %s", err.Error())
+			return 2
+		}
+		code := updateConfig(ctx, cfg, term, os.Args)
+		if err := lock.Unlock(); err != nil {
+			logger.Warn(fmt.Sprintf("Unable to release lock: %v", err))
+		}
+		if code != 0 {
+			return code
+		}
 	}
-	if err := cfg.ExportEnv(ctx); err != nil {
-		term.WriteErrorf("Unable to export required envs: %s", err.Error())
+	// 'config migrate' reports pending migrations itself, so the config is left as is until then
+	if !isConfigMigrate(os.Args) && !dryRun {
+		if err := cfg.ExportEnv(ctx); err != nil {
+			term.WriteErrorf("Unable to export required envs: %s", err.Error())
+		}
 	}
+	timer.mark("migrate config")
 
 	cliApp := app.CreateApp(ctx)
+	cliApp.BashComplete = commands.CompleteGlobalFlags
 	ctx = log.SetupContext(ctx, cliApp.Writer)
 
 	cmds := commands.CommandLocator(ctx)
 	cliApp.Commands = append(cmds, cliApp.Commands...)
+	timer.mark("load commands")
 
-	if err := firstRun(ctx); err != nil {
-		return 5
-	}
-	if err := checkUpgrade(ctx); err != nil {
-		return 1
+	if !dryRun {
+		if err := firstRun(ctx); err != nil {
+			return 5
+		}
+		timer.mark("first run")
+		if err := checkUpgrade(ctx); err != nil {
+			return 1
+		}
+		timer.mark("upgrade check")
 	}
 
 	// check command collision
@@ -85,7 +125,9 @@ func Run() int {
 		return 7
 	}
 
-	if err := cliApp.RunContext(ctx, os.Args); err != nil {
+	err = cliApp.RunContext(ctx, os.Args)
+	timer.mark("run command")
+	if err != nil {
 		return 6
 	}
 
@@ -97,7 +139,7 @@ func findCollisions(availableCmds []*cli.Command, args []string) error {
 		// check names and aliases
 
 		// for some built in commands, we need to check their first parameter (args[2])
-		metaCmds := []string{"help", "uninstall", "update"}
+		metaCmds := []string{"help", "rollback", "uninstall", "update"}
 		for _, c := range metaCmds {
 			if c == args[1] && len(args) > 2 {
 				return findDuplicate(availableCmds, args[2])
@@ -136,6 +178,156 @@ func findDuplicate(availableCmds []*cli.Command, cmdName string) error {
 	return nil
 }
 
+// configNeedsUpdate reports whether the config has no cache path yet or has to be migrated
+func configNeedsUpdate(ctx context.Context, cfg config.Config, args []string) bool {
+	if _, ok := cfg.GetValue("cli", "cache-path"); !ok {
+		return true
+	}
+	if isConfigMigrate(args) {
+		return false
+	}
+	result, err := cfg.Migrate(ctx, true)
+	return err != nil || len(result.Migrations) > 0
+}
+
+// updateConfig sets the default cache path and migrates the config, while the caller holds the lock on the CLI home.
+// It returns the exit code of the CLI on failure, or 0.
+func updateConfig(ctx context.Context, cfg config.Config, term terminal.Terminal, args []string) int {
+	if _, ok := cfg.GetValue("cli", "cache-path"); !ok {
+		cliHome, _ := tools.GetAkamaiCliPath()
+
+		cachePath := filepath.Join(cliHome, "cache")
+		if err := os.MkdirAll(cachePath, 0700); err != nil {
+			term.WriteErrorf("Unable to create cache directory: %s", err.Error())
+			return 2
+		}
+
+		// a config file created now is already at the current version, there is nothing to migrate
+		if len(cfg.UserValues()) == 0 {
+			cfg.SetValue("cli", "config-version", config.DefaultValue("cli", "config-version"))
+		}
+		cfg.SetValue("cli", "cache-path", cachePath)
+		if err := cfg.Save(ctx); err != nil {
+			return 3
+		}
+	}
+	if isConfigMigrate(args) {
+		return 0
+	}
+	result, err := cfg.Migrate(ctx, false)
+	if err != nil {
+		term.WriteErrorf("Unable to migrate cli config: %s", err.Error())
+		return 4
+	}
+	if result.Backup != "" {
+		_, _ = fmt.Fprintln(term.Error(), color.CyanString("Migrated cli config to version %s, the previous config was saved to %s", result.Version, result.Backup))
+	}
+	return 0
+}
+
+func isConfigMigrate(args []string) bool {
+	for i := 1; i < len(args)-1; i++ {
+		if args[i] == "config" {
+			return args[i+1] == "migrate"
+		}
+	}
+	return false
+}
+
+// dryRunCommands are the built-in commands with a --dry-run flag
+var dryRunCommands = []string{"install", "update", "uninstall", "config"}
+
+// isDryRun checks whether a built-in command is run with the --dry-run flag.
+// Package commands are not checked, as they may define a --dry-run flag of their own.
+func isDryRun(args []string) bool {
+	i := 1
+	for ; i < len(args) && strings.HasPrefix(args[i], "-"); i++ {
+		name, _, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if !hasValue && slices.Contains(globalValueFlags, name) {
+			i++
+		}
+	}
+	if i >= len(args) || !slices.Contains(dryRunCommands, args[i]) {
+		return false
+	}
+	for _, arg := range args[i+1:] {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name == "dry-run" {
+			enabled, err := strconv.ParseBool(value)
+			return !hasValue || (err == nil && enabled)
+		}
+	}
+	return false
+}
+
+// useProfile activates the profile given with the --profile flag, the AKAMAI_CLI_PROFILE variable or the cli.profile config value.
+// A profile stored in the config which no longer exists is reported, but does not prevent running commands, so that it can be fixed.
+func useProfile(cfg config.Config, term terminal.Terminal, args []string) error {
+	name := profileFlag(args)
+	if name == "" {
+		var ok bool
+		if name, ok = cfg.GetValue("cli", "profile"); !ok || name == "" {
+			return nil
+		}
+		if origin, _ := cfg.Origin("cli", "profile"); origin.Scope != config.ScopeEnv && !slices.Contains(cfg.Profiles(), name) {
+			_, _ = fmt.Fprintln(term.Error(), color.YellowString("Profile %s set in cli.profile does not exist, no profile is used", name))
+			return nil
+		}
+	}
+	return cfg.UseProfile(name)
+}
+
+// globalValueFlags are the global flags taking a value, which may be given as the next argument
+var globalValueFlags = []string{"edgerc", "e", "section", "s", "accountkey", "account-key", "proxy", "profile", "output"}
+
+// profileFlag returns the value of the global --profile flag. Only flags given before the command name are checked,
+// as packages may define a --profile flag of their own.
+func profileFlag(args []string) string {
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			return ""
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name == "profile" {
+			if hasValue {
+				return value
+			}
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+			return ""
+		}
+		if !hasValue && slices.Contains(globalValueFlags, name) {
+			i++
+		}
+	}
+	return ""
+}
+
+// timingsFlag checks whether the global --timings flag is given. It is read before the app is created, so that the time
+// spent loading the config and the commands can be reported.
+func timingsFlag(args []string) bool {
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			return false
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name == "timings" {
+			enabled, err := strconv.ParseBool(value)
+			return !hasValue || (err == nil && enabled)
+		}
+		if !hasValue && slices.Contains(globalValueFlags, name) {
+			i++
+		}
+	}
+	return false
+}
+
 func cleanupUpgrade() error {
 	filename := filepath.Base(os.Args[0])
 	var oldExe string
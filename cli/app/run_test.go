@@ -1,8 +1,14 @@
 package app
 
 import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/terminal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
@@ -455,3 +461,152 @@ func TestFindDuplicate(t *testing.T) {
 		})
 	}
 }
+
+func TestIsConfigMigrate(t *testing.T) {
+	tests := map[string]struct {
+		args     []string
+		expected bool
+	}{
+		"config migrate":                {args: []string{"akamai", "config", "migrate"}, expected: true},
+		"config migrate with flags":     {args: []string{"akamai", "--output", "json", "config", "migrate", "--dry-run"}, expected: true},
+		"other config command":          {args: []string{"akamai", "config", "list"}},
+		"package command named migrate": {args: []string{"akamai", "migrate"}},
+		"no command":                    {args: []string{"akamai"}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, isConfigMigrate(test.args))
+		})
+	}
+}
+
+func TestIsDryRun(t *testing.T) {
+	tests := map[string]struct {
+		args     []string
+		expected bool
+	}{
+		"install dry run":                 {args: []string{"akamai", "install", "--dry-run", "purge"}, expected: true},
+		"dry run after global flags":      {args: []string{"akamai", "--profile", "prod", "update", "echo", "--dry-run"}, expected: true},
+		"config import dry run":           {args: []string{"akamai", "config", "import", "--dry-run=true", "laptop.json"}, expected: true},
+		"dry run disabled":                {args: []string{"akamai", "uninstall", "--dry-run=false", "echo"}},
+		"dry run flag of package command": {args: []string{"akamai", "purge", "--dry-run"}},
+		"no dry run":                      {args: []string{"akamai", "install", "purge"}},
+		"no command":                      {args: []string{"akamai", "--dry-run"}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, isDryRun(test.args))
+		})
+	}
+}
+
+func TestConfigNeedsUpdate(t *testing.T) {
+	tests := map[string]struct {
+		args     []string
+		init     func(*config.Mock)
+		expected bool
+	}{
+		"missing cache path": {
+			args: []string{"akamai", "list"},
+			init: func(m *config.Mock) {
+				m.On("GetValue", "cli", "cache-path").Return("", false).Once()
+			},
+			expected: true,
+		},
+		"pending migration": {
+			args: []string{"akamai", "list"},
+			init: func(m *config.Mock) {
+				m.On("GetValue", "cli", "cache-path").Return("/cache", true).Once()
+				m.On("Migrate", true).Return(&config.MigrationResult{Migrations: []config.Migration{{From: "1", To: "1.1"}}}, nil).Once()
+			},
+			expected: true,
+		},
+		"migration left to config migrate": {
+			args: []string{"akamai", "config", "migrate"},
+			init: func(m *config.Mock) {
+				m.On("GetValue", "cli", "cache-path").Return("/cache", true).Once()
+			},
+		},
+		"up to date": {
+			args: []string{"akamai", "list"},
+			init: func(m *config.Mock) {
+				m.On("GetValue", "cli", "cache-path").Return("/cache", true).Once()
+				m.On("Migrate", true).Return(&config.MigrationResult{}, nil).Once()
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &config.Mock{}
+			test.init(m)
+			assert.Equal(t, test.expected, configNeedsUpdate(context.Background(), m, test.args))
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateConfigOnFreshHome(t *testing.T) {
+	cliHome := t.TempDir()
+	t.Setenv("AKAMAI_CLI_HOME", cliHome)
+	cfg, err := config.NewIni()
+	require.NoError(t, err)
+	errOut := &bytes.Buffer{}
+	term := terminal.New(terminal.DiscardWriter(), nil, errOut)
+	ctx := terminal.Context(context.Background(), term)
+
+	assert.Equal(t, 0, updateConfig(ctx, cfg, term, []string{"akamai", "list"}))
+	assert.Empty(t, errOut.String())
+
+	cfg, err = config.NewIni()
+	require.NoError(t, err)
+	version, _ := cfg.GetValue("cli", "config-version")
+	assert.Equal(t, config.DefaultValue("cli", "config-version"), version)
+	cachePath, _ := cfg.GetValue("cli", "cache-path")
+	assert.Equal(t, filepath.Join(cliHome, ".akamai-cli", "cache"), cachePath)
+	backups, err := filepath.Glob(filepath.Join(cliHome, ".akamai-cli", "config.*.bak"))
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+	assert.False(t, configNeedsUpdate(ctx, cfg, []string{"akamai", "list"}))
+	_, err = os.Stat(cachePath)
+	assert.NoError(t, err)
+}
+
+func TestProfileFlag(t *testing.T) {
+	tests := map[string]struct {
+		args     []string
+		expected string
+	}{
+		"profile flag":                    {args: []string{"akamai", "--profile", "prod", "list"}, expected: "prod"},
+		"profile flag with equals sign":   {args: []string{"akamai", "--profile=prod", "list"}, expected: "prod"},
+		"profile after other global flag": {args: []string{"akamai", "--section", "default", "-profile", "staging", "config", "list"}, expected: "staging"},
+		"profile flag of package command": {args: []string{"akamai", "property-manager", "--profile", "prod"}},
+		"value of other global flag":      {args: []string{"akamai", "--edgerc", "--profile", "list"}},
+		"no flags":                        {args: []string{"akamai", "list"}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, profileFlag(test.args))
+		})
+	}
+}
+
+func TestTimingsFlag(t *testing.T) {
+	tests := map[string]struct {
+		args     []string
+		expected bool
+	}{
+		"timings flag":                    {args: []string{"akamai", "--timings", "list"}, expected: true},
+		"timings flag set to true":        {args: []string{"akamai", "--timings=true", "list"}, expected: true},
+		"timings flag set to false":       {args: []string{"akamai", "--timings=false", "list"}},
+		"timings after other global flag": {args: []string{"akamai", "--output", "json", "-timings", "list"}, expected: true},
+		"timings after profile flag":      {args: []string{"akamai", "--profile", "prod", "--timings", "list"}, expected: true},
+		"timings flag of package command": {args: []string{"akamai", "property-manager", "--timings"}},
+		"value of other global flag":      {args: []string{"akamai", "--section", "--timings", "list"}},
+		"no flags":                        {args: []string{"akamai", "list"}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, timingsFlag(test.args))
+		})
+	}
+}
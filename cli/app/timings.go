@@ -0,0 +1,48 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+type (
+	// timings records the time spent in each startup phase, as requested with the --timings flag
+	timings struct {
+		enabled bool
+		start   time.Time
+		last    time.Time
+		phases  []phase
+	}
+
+	phase struct {
+		name     string
+		duration time.Duration
+	}
+)
+
+func newTimings(enabled bool) *timings {
+	now := time.Now()
+	return &timings{enabled: enabled, start: now, last: now}
+}
+
+// mark ends the current phase, giving it a name
+func (t *timings) mark(name string) {
+	if !t.enabled {
+		return
+	}
+	now := time.Now()
+	t.phases = append(t.phases, phase{name: name, duration: now.Sub(t.last)})
+	t.last = now
+}
+
+// write outputs the recorded phases followed by the total time
+func (t *timings) write(w io.Writer) {
+	if !t.enabled {
+		return
+	}
+	for _, p := range t.phases {
+		_, _ = fmt.Fprintf(w, "%-16s %10s\n", p.name, p.duration.Round(time.Microsecond))
+	}
+	_, _ = fmt.Fprintf(w, "%-16s %10s\n", "total", t.last.Sub(t.start).Round(time.Microsecond))
+}
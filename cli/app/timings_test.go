@@ -0,0 +1,34 @@
+package app
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimings(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		timer := newTimings(true)
+		timer.mark("load config")
+		timer.mark("run command")
+		var out bytes.Buffer
+		timer.write(&out)
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Len(t, lines, 3)
+		assert.True(t, strings.HasPrefix(lines[0], "load config "))
+		assert.True(t, strings.HasPrefix(lines[1], "run command "))
+		assert.True(t, strings.HasPrefix(lines[2], "total "))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		timer := newTimings(false)
+		timer.mark("load config")
+		var out bytes.Buffer
+		timer.write(&out)
+
+		assert.Empty(t, timer.phases)
+		assert.Empty(t, out.String())
+	})
+}
@@ -10,6 +10,9 @@ import (
 
 	"github.com/akamai/cli/v2/pkg/apphelp"
 	"github.com/akamai/cli/v2/pkg/autocomplete"
+	"github.com/akamai/cli/v2/pkg/color"
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/log"
 	"github.com/akamai/cli/v2/pkg/terminal"
 	"github.com/akamai/cli/v2/pkg/tools"
 	"github.com/akamai/cli/v2/pkg/version"
@@ -37,12 +40,27 @@ func CreateApp(ctx context.Context) *cli.App {
 			Name:  "proxy",
 			Usage: "Set a proxy to use",
 		},
+		&cli.StringFlag{
+			Name:    "profile",
+			Usage:   "Config `profile` to use",
+			EnvVars: []string{"AKAMAI_CLI_PROFILE"},
+		},
 		&cli.BoolFlag{
 			Name:    "daemon",
 			Usage:   "Keep Akamai CLI running in the background, particularly useful for Docker containers",
 			Hidden:  true,
 			EnvVars: []string{"AKAMAI_CLI_DAEMON"},
 		},
+		&cli.StringFlag{
+			Name:    "output",
+			Usage:   "Output `format` of built-in commands: text, json or yaml",
+			Value:   "text",
+			EnvVars: []string{"AKAMAI_CLI_OUTPUT"},
+		},
+		&cli.BoolFlag{
+			Name:  "timings",
+			Usage: "Print the time spent in each startup phase to stderr",
+		},
 	)
 
 	app.Action = func(c *cli.Context) error {
@@ -50,8 +68,12 @@ func CreateApp(ctx context.Context) *cli.App {
 	}
 
 	app.Before = func(c *cli.Context) error {
-		if c.IsSet("proxy") {
-			proxy := c.String("proxy")
+		proxy := c.String("proxy")
+		if !c.IsSet("proxy") {
+			// the proxy may be set for the selected profile
+			proxy, _ = config.Get(c.Context).GetValue("cli", "proxy")
+		}
+		if proxy != "" {
 			if !strings.HasPrefix(proxy, "http://") && !strings.HasPrefix(proxy, "https://") {
 				proxy = fmt.Sprintf("http://%s", proxy)
 			}
@@ -63,6 +85,21 @@ func CreateApp(ctx context.Context) *cli.App {
 			}
 		}
 
+		switch output := c.String("output"); output {
+		case "", "text":
+		case "json", "yaml":
+			color.Disable()
+		default:
+			return cli.Exit(color.RedString("Invalid output format %q, expected one of: text, json, yaml", output), 1)
+		}
+
+		if err := useCredentialContext(c); err != nil {
+			return err
+		}
+		if err := expandAccountAlias(c); err != nil {
+			return err
+		}
+
 		if c.IsSet("daemon") {
 			for {
 				time.Sleep(sleep24HDuration)
@@ -74,6 +111,46 @@ func CreateApp(ctx context.Context) *cli.App {
 	return app
 }
 
+// useCredentialContext sets the edgerc, section and accountkey flags not given explicitly to the values of the active context.
+// A selected context which does not exist is reported, but does not prevent running commands, so that it can be fixed.
+func useCredentialContext(c *cli.Context) error {
+	logger := log.FromContext(c.Context)
+	cfg := config.Get(c.Context)
+	current, ok, err := config.CurrentCredentialContext(cfg)
+	if err != nil {
+		_, _ = fmt.Fprintln(terminal.Get(c.Context).Error(), color.YellowString("Context set in cli.context is not used: %v", err))
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+
+	for _, flag := range [][2]string{{"edgerc", current.Edgerc}, {"section", current.Section}, {"accountkey", current.AccountKey}} {
+		if flag[1] == "" || c.IsSet(flag[0]) {
+			continue
+		}
+		if err := c.Set(flag[0], flag[1]); err != nil {
+			return err
+		}
+	}
+	logger.Info(fmt.Sprintf("Using context %s: edgerc %s, section %s, account key %s", current.Name, c.String("edgerc"), c.String("section"), c.String("accountkey")))
+	return nil
+}
+
+// expandAccountAlias replaces an account alias given with the accountkey flag, or by the active context, with its account switch key
+func expandAccountAlias(c *cli.Context) error {
+	alias := c.String("accountkey")
+	key, ok := config.ExpandAccountKey(config.Get(c.Context), alias)
+	if !ok {
+		return nil
+	}
+	if err := c.Set("accountkey", key); err != nil {
+		return err
+	}
+	log.FromContext(c.Context).Debug(fmt.Sprintf("Using account key %s of account %s", key, alias))
+	return nil
+}
+
 // CreateAppTemplate creates a basic *cli.App template
 func CreateAppTemplate(ctx context.Context, commandName, usage, description, version string) *cli.App {
 	return createAppTemplate(ctx, commandName, usage, description, version, true)
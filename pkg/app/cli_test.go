@@ -8,6 +8,7 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/akamai/cli/v2/pkg/config"
 	"github.com/akamai/cli/v2/pkg/log"
 	"github.com/akamai/cli/v2/pkg/terminal"
 	"github.com/akamai/cli/v2/pkg/version"
@@ -30,6 +31,7 @@ func TestCreateApp(t *testing.T) {
 	assert.True(t, hasFlag(app, "zsh"))
 	assert.True(t, hasFlag(app, "proxy"))
 	assert.True(t, hasFlag(app, "daemon"))
+	assert.True(t, hasFlag(app, "output"))
 	assert.NotNil(t, app.Before)
 }
 
@@ -81,6 +83,7 @@ func TestVersion(t *testing.T) {
 func TestCreateAppProxy(t *testing.T) {
 	tests := map[string]struct {
 		proxyValue   string
+		configProxy  string
 		expectedEnvs map[string]string
 	}{
 		"no proxy": {
@@ -103,6 +106,21 @@ func TestCreateAppProxy(t *testing.T) {
 				"HTTPS_PROXY": "http://test.akamai.com",
 			},
 		},
+		"proxy set in config": {
+			configProxy: "proxy.example.com:3128",
+			expectedEnvs: map[string]string{
+				"HTTP_PROXY":  "http://proxy.example.com:3128",
+				"HTTPS_PROXY": "http://proxy.example.com:3128",
+			},
+		},
+		"proxy flag overrides config": {
+			proxyValue:  "https://test.akamai.com",
+			configProxy: "proxy.example.com:3128",
+			expectedEnvs: map[string]string{
+				"HTTP_PROXY":  "https://test.akamai.com",
+				"HTTPS_PROXY": "https://test.akamai.com",
+			},
+		},
 		"proxy set with https": {
 			proxyValue: "https://test.akamai.com",
 			expectedEnvs: map[string]string{
@@ -114,11 +132,15 @@ func TestCreateAppProxy(t *testing.T) {
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			term := terminal.Color()
-			ctx := terminal.Context(context.Background(), term)
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "cli", "context").Return("", false)
+			cfg.On("GetValue", "cli", "proxy").Return(test.configProxy, test.configProxy != "").Maybe()
+			ctx := config.Context(terminal.Context(context.Background(), term), cfg)
 			app := CreateApp(ctx)
 			set := flag.NewFlagSet("test", 0)
 			set.String("proxy", "", "")
 			cliCtx := cli.NewContext(app, set, nil)
+			cliCtx.Context = ctx
 			if test.proxyValue != "" {
 				require.NoError(t, cliCtx.Set("proxy", test.proxyValue))
 			}
@@ -133,6 +155,110 @@ func TestCreateAppProxy(t *testing.T) {
 	}
 }
 
+func TestCreateAppOutput(t *testing.T) {
+	tests := map[string]struct {
+		output    string
+		withError string
+	}{
+		"text":    {output: "text"},
+		"json":    {output: "json"},
+		"yaml":    {output: "yaml"},
+		"invalid": {output: "xml", withError: `Invalid output format "xml", expected one of: text, json, yaml`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			term := terminal.Color()
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "cli", "context").Return("", false).Maybe()
+			cfg.On("GetValue", "cli", "proxy").Return("", false).Maybe()
+			ctx := config.Context(terminal.Context(context.Background(), term), cfg)
+			app := CreateApp(ctx)
+			set := flag.NewFlagSet("test", 0)
+			set.String("output", "", "")
+			cliCtx := cli.NewContext(app, set, nil)
+			cliCtx.Context = ctx
+			require.NoError(t, cliCtx.Set("output", test.output))
+
+			err := app.Before(cliCtx)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCreateAppContext(t *testing.T) {
+	tests := map[string]struct {
+		context      string
+		flags        map[string]string
+		expectedArgs map[string]string
+		withWarning  string
+	}{
+		"no context": {
+			expectedArgs: map[string]string{"edgerc": "", "section": "", "accountkey": ""},
+		},
+		"context sets flags": {
+			context:      "prod",
+			expectedArgs: map[string]string{"edgerc": "/creds/.edgerc", "section": "production", "accountkey": "1-ABCD"},
+		},
+		"given flags take precedence": {
+			context:      "prod",
+			flags:        map[string]string{"section": "staging"},
+			expectedArgs: map[string]string{"edgerc": "/creds/.edgerc", "section": "staging", "accountkey": "1-ABCD"},
+		},
+		"account alias expanded": {
+			flags:        map[string]string{"accountkey": "acme"},
+			expectedArgs: map[string]string{"edgerc": "", "section": "", "accountkey": "1-ABC:1-2XYZ"},
+		},
+		"account alias of context expanded": {
+			context:      "acme",
+			expectedArgs: map[string]string{"edgerc": "", "section": "", "accountkey": "1-ABC:1-2XYZ"},
+		},
+		"unknown context": {
+			context:      "dev",
+			expectedArgs: map[string]string{"edgerc": "", "section": "", "accountkey": ""},
+			withWarning:  "Context set in cli.context is not used: unknown context: dev",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			term := &terminal.Mock{}
+			errOut := &bytes.Buffer{}
+			term.On("Error").Return(errOut).Maybe()
+			cfg := &config.Mock{}
+			cfg.On("GetValue", "cli", "context").Return(test.context, test.context != "")
+			cfg.On("GetValue", "cli", "proxy").Return("", false).Maybe()
+			cfg.On("Values").Return(map[string]map[string]string{
+				"cli":          {"context": test.context},
+				"context prod": {"edgerc": "/creds/.edgerc", "section": "production", "account-key": "1-ABCD"},
+				"context acme": {"account-key": "acme"},
+				"account acme": {"key": "1-ABC:1-2XYZ"},
+			}).Maybe()
+			ctx := config.Context(terminal.Context(context.Background(), term), cfg)
+			app := CreateApp(ctx)
+			set := flag.NewFlagSet("test", 0)
+			for _, name := range []string{"edgerc", "section", "accountkey", "output"} {
+				set.String(name, "", "")
+			}
+			cliCtx := cli.NewContext(app, set, nil)
+			cliCtx.Context = ctx
+			for name, value := range test.flags {
+				require.NoError(t, cliCtx.Set(name, value))
+			}
+
+			require.NoError(t, app.Before(cliCtx))
+			for name, value := range test.expectedArgs {
+				assert.Equal(t, value, cliCtx.String(name), name)
+			}
+			assert.Contains(t, errOut.String(), test.withWarning)
+			cfg.AssertExpectations(t)
+		})
+	}
+}
+
 func hasFlag(app *cli.App, name string) bool {
 	for _, f := range app.Flags {
 		if f.Names()[0] == name {
@@ -0,0 +1,215 @@
+// Package broker serves EdgeGrid credentials to package commands over a Unix socket, so that packages do not have to
+// read the credentials file themselves. A broker lives for a single package command run.
+package broker
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/akamai/cli/v2/pkg/edgegrid"
+	"github.com/akamai/cli/v2/pkg/edgerc"
+	"github.com/akamai/cli/v2/pkg/log"
+)
+
+const (
+	// EnvAddress is the environment variable holding the path of the broker socket
+	EnvAddress = "AKAMAI_CLI_CREDENTIAL_BROKER"
+	// EnvToken is the environment variable holding the token to send as 'Authorization: Bearer <token>'
+	EnvToken = "AKAMAI_CLI_CREDENTIAL_BROKER_TOKEN"
+
+	socketName = "broker.sock"
+)
+
+type (
+	// Source provides the credentials of a section, such as the credentials file
+	Source interface {
+		Credentials(section string) (edgerc.Credentials, error)
+	}
+
+	// Broker serves the credentials of a single section
+	Broker struct {
+		dir      string
+		token    string
+		section  string
+		creds    edgerc.Credentials
+		signer   *edgegrid.Signer
+		listener net.Listener
+		server   *http.Server
+	}
+
+	// CredentialsResponse is returned by GET /v1/credentials
+	CredentialsResponse struct {
+		Section string `json:"section"`
+		edgerc.Credentials
+	}
+
+	// SignRequest is sent to POST /v1/sign, describing the request to sign
+	SignRequest struct {
+		Method string `json:"method"`
+		URL    string `json:"url"`
+		Body   []byte `json:"body,omitempty"`
+	}
+
+	// SignResponse holds the URL to send the signed request to, which includes the account switch key if any,
+	// and the headers to add to it
+	SignResponse struct {
+		URL     string            `json:"url"`
+		Headers map[string]string `json:"headers"`
+	}
+
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+)
+
+// Start serves the credentials of the section on a new socket, readable by the current user only.
+// A non-empty account switch key replaces the one of the credentials.
+func Start(ctx context.Context, source Source, section, accountKey string) (*Broker, error) {
+	creds, err := source.Credentials(section)
+	if err != nil {
+		return nil, err
+	}
+	if accountKey != "" {
+		creds.AccountKey = accountKey
+	}
+	signer, err := edgegrid.NewSigner(creds)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credentials in section %s: %w", section, err)
+	}
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "akamai-cli-broker-")
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", filepath.Join(dir, socketName))
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("unable to listen on credential broker socket: %w", err)
+	}
+
+	b := &Broker{dir: dir, token: token, section: section, creds: creds, signer: signer, listener: listener}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/credentials", b.authorized(b.handleCredentials))
+	mux.HandleFunc("/v1/sign", b.authorized(b.handleSign))
+	b.server = &http.Server{
+		Handler:     mux,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		if err := b.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.FromContext(ctx).Error(fmt.Sprintf("Credential broker stopped: %v", err))
+		}
+	}()
+	return b, nil
+}
+
+// Address returns the path of the broker socket
+func (b *Broker) Address() string {
+	return b.listener.Addr().String()
+}
+
+// Env returns the variables to add to the environment of the package command, and of that command only
+func (b *Broker) Env() []string {
+	return []string{EnvAddress + "=" + b.Address(), EnvToken + "=" + b.token}
+}
+
+// Close stops serving credentials and removes the socket
+func (b *Broker) Close() error {
+	err := b.server.Close()
+	if rmErr := os.RemoveAll(b.dir); rmErr != nil && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+func (b *Broker) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(b.token)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "missing or invalid broker token"})
+			return
+		}
+		log.FromContext(r.Context()).Debug(fmt.Sprintf("Credential broker request: %s %s", r.Method, r.URL.Path))
+		next(w, r)
+	}
+}
+
+func (b *Broker) handleCredentials(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "use GET to fetch credentials"})
+		return
+	}
+	writeJSON(w, http.StatusOK, CredentialsResponse{Section: b.section, Credentials: b.creds})
+}
+
+func (b *Broker) handleSign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "use POST to sign requests"})
+		return
+	}
+	var body SignRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid sign request: %v", err)})
+		return
+	}
+	u, err := url.Parse(body.URL)
+	if err != nil || u.Scheme == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("invalid request URL %q", body.URL)})
+		return
+	}
+	if host := strings.TrimSuffix(b.creds.Host, "/"); u.Host != host {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: fmt.Sprintf("only requests to %s can be signed", host)})
+		return
+	}
+	if b.creds.AccountKey != "" {
+		query := u.Query()
+		if !query.Has("accountSwitchKey") {
+			query.Set("accountSwitchKey", b.creds.AccountKey)
+			u.RawQuery = query.Encode()
+		}
+	}
+	method := strings.ToUpper(body.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), method, u.String(), strings.NewReader(string(body.Body)))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	if err := b.signer.Sign(req); err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, SignResponse{URL: u.String(), Headers: map[string]string{"Authorization": req.Header.Get("Authorization")}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.New("unable to generate credential broker token")
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,107 @@
+package broker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/akamai/cli/v2/pkg/edgerc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sourceMock map[string]edgerc.Credentials
+
+func (s sourceMock) Credentials(section string) (edgerc.Credentials, error) {
+	creds, ok := s[section]
+	if !ok {
+		return edgerc.Credentials{}, edgerc.ErrSectionNotFound
+	}
+	return creds, nil
+}
+
+var testSource = sourceMock{
+	"default": {
+		Host:         "akab-host.luna.akamaiapis.net",
+		ClientToken:  "akab-client-token",
+		ClientSecret: "secret",
+		AccessToken:  "akab-access-token",
+	},
+	"broken": {Host: "akab-host.luna.akamaiapis.net"},
+}
+
+func TestBroker(t *testing.T) {
+	b, err := Start(context.Background(), testSource, "default", "1-ABCD")
+	require.NoError(t, err)
+	env := b.Env()
+	require.Len(t, env, 2)
+	assert.Equal(t, EnvAddress+"="+b.Address(), env[0])
+	token := strings.TrimPrefix(env[1], EnvToken+"=")
+	info, err := os.Stat(b.dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", b.Address())
+		},
+	}}
+	call := func(method, path, token string, body interface{}, out interface{}) int {
+		var data bytes.Buffer
+		if body != nil {
+			require.NoError(t, json.NewEncoder(&data).Encode(body))
+		}
+		req, err := http.NewRequest(method, "http://broker"+path, &data)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, res.Body.Close())
+		}()
+		if out != nil {
+			require.NoError(t, json.NewDecoder(res.Body).Decode(out))
+		}
+		return res.StatusCode
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/v1/credentials", "", nil, nil))
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodGet, "/v1/credentials", "wrong", nil, nil))
+
+	var creds CredentialsResponse
+	assert.Equal(t, http.StatusOK, call(http.MethodGet, "/v1/credentials", token, nil, &creds))
+	assert.Equal(t, "default", creds.Section)
+	assert.Equal(t, "secret", creds.ClientSecret)
+	assert.Equal(t, "1-ABCD", creds.AccountKey, "account switch key given to the broker replaces the one of the section")
+
+	var signed SignResponse
+	assert.Equal(t, http.StatusOK, call(http.MethodPost, "/v1/sign", token,
+		SignRequest{Method: "post", URL: "https://akab-host.luna.akamaiapis.net/papi/v1/properties?groupId=grp_1", Body: []byte(`{}`)}, &signed))
+	assert.Equal(t, "https://akab-host.luna.akamaiapis.net/papi/v1/properties?accountSwitchKey=1-ABCD&groupId=grp_1", signed.URL)
+	assert.Regexp(t, `^EG1-HMAC-SHA256 client_token=akab-client-token;access_token=akab-access-token;timestamp=\S+;nonce=\S+;signature=\S{44}$`, signed.Headers["Authorization"])
+
+	var failed errorResponse
+	assert.Equal(t, http.StatusBadRequest, call(http.MethodPost, "/v1/sign", token, SignRequest{URL: "https://example.com/"}, &failed))
+	assert.Equal(t, "only requests to akab-host.luna.akamaiapis.net can be signed", failed.Error)
+	assert.Equal(t, http.StatusMethodNotAllowed, call(http.MethodGet, "/v1/sign", token, nil, nil))
+
+	require.NoError(t, b.Close())
+	_, err = os.Stat(b.dir)
+	assert.True(t, errors.Is(err, os.ErrNotExist), "socket directory is removed")
+}
+
+func TestStartErrors(t *testing.T) {
+	_, err := Start(context.Background(), testSource, "missing", "")
+	assert.ErrorIs(t, err, edgerc.ErrSectionNotFound)
+
+	_, err = Start(context.Background(), testSource, "broken", "")
+	assert.EqualError(t, err, "invalid credentials in section broken: client_secret is required to sign requests")
+}
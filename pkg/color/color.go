@@ -75,3 +75,8 @@ func FaintString(format string, a ...interface{}) string {
 	}
 	return faint.Sprintf(format, a...)
 }
+
+// Disable turns off all text attributes, for output meant to be read by other programs
+func Disable() {
+	color.NoColor = true
+}
@@ -0,0 +1,287 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/tools"
+)
+
+// maxVerificationFileSize limits the size of downloaded checksum and signature files
+const maxVerificationFileSize = 1 << 20
+
+// binChecksum is the checksum of a command binary set in cli.json. It is either a single value, used on every platform,
+// or an object with a value per platform, keyed by "<os>-<arch>", such as "linux-amd64" or "mac-arm64".
+type binChecksum struct {
+	value     string
+	platforms map[string]string
+}
+
+// UnmarshalJSON reads the checksum from a string or an object of per-platform checksums
+func (c *binChecksum) UnmarshalJSON(data []byte) error {
+	*c = binChecksum{}
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+	if err := json.Unmarshal(data, &c.value); err == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &c.platforms); err != nil {
+		return fmt.Errorf("checksum has to be a string or an object of checksums keyed by <os>-<arch>: %w", err)
+	}
+	return nil
+}
+
+// MarshalJSON writes the checksum in the form it was read in
+func (c binChecksum) MarshalJSON() ([]byte, error) {
+	if c.platforms != nil {
+		return json.Marshal(c.platforms)
+	}
+	return json.Marshal(c.value)
+}
+
+// isSet reports whether any checksum is given
+func (c binChecksum) isSet() bool {
+	return c.value != "" || len(c.platforms) > 0
+}
+
+// forPlatform returns the checksum of the binary built for the platform of cmd. A package listing per-platform checksums
+// which leaves out the platform fails verification rather than installing an unverified binary.
+func (c binChecksum) forPlatform(cmd command) (string, error) {
+	if c.platforms == nil {
+		return c.value, nil
+	}
+	platform := cmd.OS + "-" + cmd.Arch
+	checksum, ok := c.platforms[platform]
+	if !ok || checksum == "" {
+		return "", fmt.Errorf("no checksum found for platform %s", platform)
+	}
+	return checksum, nil
+}
+
+// verifyBin checks a downloaded binary against the checksum, checksums file, and signature configured for the command in cli.json.
+// Commands without any of them are not verified, unless trusted public keys are configured, in which case a signature is required.
+func verifyBin(ctx context.Context, cmd command, binURL, binPath string, sum []byte) error {
+	logger := log.FromContext(ctx)
+
+	if cmd.Checksum.isSet() {
+		logger.Debug(fmt.Sprintf("Verifying checksum of %s", binURL))
+		expected, err := cmd.Checksum.forPlatform(cmd)
+		if err != nil {
+			return err
+		}
+		if err := compareChecksum(expected, sum); err != nil {
+			return err
+		}
+	}
+
+	if cmd.ChecksumsURL != "" {
+		checksumsURL, err := executeURLTemplate(cmd.ChecksumsURL, cmd)
+		if err != nil {
+			return fmt.Errorf("unable to create checksums URL: %w", err)
+		}
+		logger.Debug(fmt.Sprintf("Verifying checksum of %s using %s", binURL, checksumsURL))
+		checksums, err := fetchVerificationFile(checksumsURL)
+		if err != nil {
+			return fmt.Errorf("unable to retrieve checksums: %w", err)
+		}
+		expected, err := findChecksum(checksums, urlFileName(binURL))
+		if err != nil {
+			return err
+		}
+		if err := compareChecksum(expected, sum); err != nil {
+			return err
+		}
+	}
+
+	if cmd.SignatureURL == "" {
+		// with trusted keys configured, leaving out signature-url must not be a way around the signature check
+		keys, err := readTrustedKeys()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			return fmt.Errorf("trusted public keys are configured, but the command does not provide a signature-url")
+		}
+	} else {
+		signatureURL, err := executeURLTemplate(cmd.SignatureURL, cmd)
+		if err != nil {
+			return fmt.Errorf("unable to create signature URL: %w", err)
+		}
+		logger.Debug(fmt.Sprintf("Verifying signature of %s using %s", binURL, signatureURL))
+		signature, err := fetchVerificationFile(signatureURL)
+		if err != nil {
+			return fmt.Errorf("unable to retrieve signature: %w", err)
+		}
+		if err := verifySignature(binPath, signature); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hasBinaryVerification reports whether the binaries of the package are verified, because any of its commands configures
+// a checksum or signature, or because trusted public keys are configured
+func hasBinaryVerification(cmdPackage subcommands) bool {
+	for _, cmd := range cmdPackage.Commands {
+		if cmd.Checksum.isSet() || cmd.ChecksumsURL != "" || cmd.SignatureURL != "" {
+			return true
+		}
+	}
+	keys, err := readTrustedKeys()
+	return err != nil || len(keys) > 0
+}
+
+// compareChecksum compares a hex encoded SHA-256 checksum, optionally prefixed with "sha256:", with sum
+func compareChecksum(expected string, sum []byte) error {
+	expected = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(expected)), "sha256:")
+	expectedSum, err := hex.DecodeString(expected)
+	if err != nil || len(expectedSum) != len(sum) {
+		return fmt.Errorf("invalid SHA-256 checksum: %q", expected)
+	}
+	if !bytes.Equal(expectedSum, sum) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, hex.EncodeToString(sum))
+	}
+	return nil
+}
+
+// findChecksum returns the checksum of fileName from a file in the format of sha256sum output.
+// A file containing just a single checksum applies to any file name.
+func findChecksum(checksums []byte, fileName string) (string, error) {
+	lines := strings.Split(strings.TrimSpace(string(checksums)), "\n")
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 1 && len(lines) == 1 {
+			return fields[0], nil
+		}
+		if len(fields) >= 2 && path.Base(strings.TrimPrefix(fields[1], "*")) == fileName {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("no checksum found for %s", fileName)
+}
+
+// verifySignature checks an ed25519 signature of the file at binPath against the trusted public keys
+func verifySignature(binPath string, signature []byte) error {
+	if len(signature) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+		if err != nil || len(decoded) != ed25519.SignatureSize {
+			return fmt.Errorf("invalid signature format")
+		}
+		signature = decoded
+	}
+
+	keys, err := readTrustedKeys()
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("the binary is signed, but no trusted public keys are configured")
+	}
+
+	content, err := os.ReadFile(binPath)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if ed25519.Verify(key, content, signature) {
+			return nil
+		}
+	}
+	return fmt.Errorf("signature does not match any trusted public key")
+}
+
+// readTrustedKeys reads the ed25519 public keys kept in the keys directory.
+// Each file holds a single key, either PEM encoded or as base64 of the raw key.
+func readTrustedKeys() ([]ed25519.PublicKey, error) {
+	keysPath, err := tools.GetAkamaiCliKeysPath()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(keysPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read trusted keys: %w", err)
+	}
+
+	keys := make([]ed25519.PublicKey, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(keysPath, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read trusted key %s: %w", entry.Name(), err)
+		}
+		key, err := parsePublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted key %s: %w", entry.Name(), err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func parsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("not an ed25519 public key")
+		}
+		return edKey, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("not an ed25519 public key")
+	}
+	return raw, nil
+}
+
+func fetchVerificationFile(url string) ([]byte, error) {
+	res, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, res.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, maxVerificationFileSize))
+}
+
+// urlFileName returns the last path element of a URL
+func urlFileName(rawURL string) string {
+	if u, err := neturl.Parse(rawURL); err == nil {
+		return path.Base(u.Path)
+	}
+	return path.Base(rawURL)
+}
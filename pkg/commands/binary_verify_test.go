@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadBin(t *testing.T) {
+	content := []byte("binary content")
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, content))
+	pkix, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body string
+		switch r.URL.Path {
+		case "/1.0.0/akamai-echo":
+			body = string(content)
+		case "/1.0.0/SHA256SUMS":
+			body = fmt.Sprintf("%s  akamai-other\n%s *akamai-echo\n", checksum[:62]+"00", checksum)
+		case "/1.0.0/OTHERSUMS":
+			body = fmt.Sprintf("%s  akamai-other\n%s  akamai-another\n", checksum, checksum)
+		case "/1.0.0/akamai-echo.sig":
+			body = signature
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, err := w.Write([]byte(body))
+		assert.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	binURL := srv.URL + "/{{.Version}}/akamai-echo"
+	platform := runtime.GOOS + "-" + runtime.GOARCH
+	if runtime.GOOS == "darwin" {
+		platform = "mac-" + runtime.GOARCH
+	}
+	tests := map[string]struct {
+		cmd       command
+		keys      map[string][]byte
+		withError string
+	}{
+		"no verification configured": {
+			cmd: command{Name: "echo", Version: "1.0.0", Bin: binURL},
+		},
+		"matching checksum": {
+			cmd: command{Name: "echo", Version: "1.0.0", Bin: binURL, Checksum: binChecksum{value: "sha256:" + checksum}},
+		},
+		"checksum mismatch": {
+			cmd:       command{Name: "echo", Version: "1.0.0", Bin: binURL, Checksum: binChecksum{value: checksum[:62] + "00"}},
+			withError: "checksum mismatch",
+		},
+		"invalid checksum": {
+			cmd:       command{Name: "echo", Version: "1.0.0", Bin: binURL, Checksum: binChecksum{value: "abc"}},
+			withError: "invalid SHA-256 checksum",
+		},
+		"matching checksum of the platform": {
+			cmd: command{Name: "echo", Version: "1.0.0", Bin: binURL, Checksum: binChecksum{platforms: map[string]string{
+				"other-arch": checksum[:62] + "00",
+				platform:     checksum,
+			}}},
+		},
+		"checksum mismatch of the platform": {
+			cmd:       command{Name: "echo", Version: "1.0.0", Bin: binURL, Checksum: binChecksum{platforms: map[string]string{platform: checksum[:62] + "00"}}},
+			withError: "checksum mismatch",
+		},
+		"no checksum for the platform": {
+			cmd:       command{Name: "echo", Version: "1.0.0", Bin: binURL, Checksum: binChecksum{platforms: map[string]string{"other-arch": checksum}}},
+			withError: "no checksum found for platform " + platform,
+		},
+		"matching checksum from checksums file": {
+			cmd: command{Name: "echo", Version: "1.0.0", Bin: binURL, ChecksumsURL: srv.URL + "/{{.Version}}/SHA256SUMS"},
+		},
+		"checksums file not found": {
+			cmd:       command{Name: "echo", Version: "1.0.0", Bin: binURL, ChecksumsURL: srv.URL + "/{{.Version}}/missing"},
+			withError: "unable to retrieve checksums",
+		},
+		"no entry in checksums file": {
+			cmd:       command{Name: "echo", Version: "1.0.0", Bin: binURL, ChecksumsURL: srv.URL + "/{{.Version}}/OTHERSUMS"},
+			withError: "no checksum found for akamai-echo",
+		},
+		"valid signature": {
+			cmd:  command{Name: "echo", Version: "1.0.0", Bin: binURL, SignatureURL: binURL + ".sig"},
+			keys: map[string][]byte{"other.pub": []byte(base64.StdEncoding.EncodeToString(otherPub)), "akamai.pem": pemKey},
+		},
+		"signature from untrusted key": {
+			cmd:       command{Name: "echo", Version: "1.0.0", Bin: binURL, SignatureURL: binURL + ".sig"},
+			keys:      map[string][]byte{"other.pub": []byte(base64.StdEncoding.EncodeToString(otherPub))},
+			withError: "signature does not match any trusted public key",
+		},
+		"no trusted keys": {
+			cmd:       command{Name: "echo", Version: "1.0.0", Bin: binURL, SignatureURL: binURL + ".sig"},
+			withError: "no trusted public keys are configured",
+		},
+		"trusted keys without signature": {
+			cmd:       command{Name: "echo", Version: "1.0.0", Bin: binURL, Checksum: binChecksum{value: checksum}},
+			keys:      map[string][]byte{"akamai.pem": pemKey},
+			withError: "trusted public keys are configured, but the command does not provide a signature-url",
+		},
+		"invalid trusted key": {
+			cmd:       command{Name: "echo", Version: "1.0.0", Bin: binURL, SignatureURL: binURL + ".sig"},
+			keys:      map[string][]byte{"broken.pub": []byte("abc")},
+			withError: "invalid trusted key broken.pub",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := t.TempDir()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+			defer func() {
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "testdata"))
+			}()
+			if test.keys != nil {
+				keysPath := filepath.Join(cliHome, ".akamai-cli", "keys")
+				require.NoError(t, os.MkdirAll(keysPath, 0700))
+				for fileName, key := range test.keys {
+					require.NoError(t, os.WriteFile(filepath.Join(keysPath, fileName), key, 0600))
+				}
+			}
+			dir := t.TempDir()
+
+			err := downloadBin(context.Background(), dir, test.cmd)
+
+			entries, readErr := os.ReadDir(dir)
+			require.NoError(t, readErr)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				assert.Empty(t, entries)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+			require.NoError(t, err)
+			assert.Equal(t, content, data)
+		})
+	}
+}
+
+func TestBinChecksumJSON(t *testing.T) {
+	tests := map[string]struct {
+		json      string
+		expected  binChecksum
+		withError bool
+	}{
+		"single checksum": {
+			json:     `"sha256:abc"`,
+			expected: binChecksum{value: "sha256:abc"},
+		},
+		"checksum per platform": {
+			json:     `{"linux-amd64": "abc", "mac-arm64": "def"}`,
+			expected: binChecksum{platforms: map[string]string{"linux-amd64": "abc", "mac-arm64": "def"}},
+		},
+		"no checksum": {
+			json: `null`,
+		},
+		"invalid checksum": {
+			json:      `["abc"]`,
+			withError: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var checksum binChecksum
+			err := json.Unmarshal([]byte(test.json), &checksum)
+			if test.withError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, checksum)
+
+			data, err := json.Marshal(checksum)
+			require.NoError(t, err)
+			var again binChecksum
+			require.NoError(t, json.Unmarshal(data, &again))
+			assert.Equal(t, checksum, again)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -14,6 +15,7 @@ import (
 	"github.com/akamai/cli/v2/pkg/apphelp"
 	"github.com/akamai/cli/v2/pkg/autocomplete"
 	"github.com/akamai/cli/v2/pkg/color"
+	"github.com/akamai/cli/v2/pkg/config"
 	"github.com/akamai/cli/v2/pkg/git"
 	"github.com/akamai/cli/v2/pkg/packages"
 	"github.com/akamai/cli/v2/pkg/tools"
@@ -25,15 +27,18 @@ import (
 
 type (
 	command struct {
-		Name         string   `json:"name"`
-		Aliases      []string `json:"aliases"`
-		Version      string   `json:"version"`
-		Description  string   `json:"description"`
-		Usage        string   `json:"usage"`
-		Arguments    string   `json:"arguments"`
-		Bin          string   `json:"bin"`
-		AutoComplete bool     `json:"auto-complete"`
-		LdFlags      string   `json:"ldflags"`
+		Name         string      `json:"name"`
+		Aliases      []string    `json:"aliases"`
+		Version      string      `json:"version"`
+		Description  string      `json:"description"`
+		Usage        string      `json:"usage"`
+		Arguments    string      `json:"arguments"`
+		Bin          string      `json:"bin"`
+		AutoComplete bool        `json:"auto-complete"`
+		LdFlags      string      `json:"ldflags"`
+		Checksum     binChecksum `json:"checksum"`
+		ChecksumsURL string      `json:"checksums-url"`
+		SignatureURL string      `json:"signature-url"`
 
 		Flags       []cli.Flag     `json:"-"`
 		Docs        string         `json:"-"`
@@ -153,31 +158,306 @@ func createBuiltinCommands() []*cli.Command {
 	gitRepo := git.NewRepository()
 	langManager := packages.NewLangManager()
 	return []*cli.Command{
+		{
+			Name:        "accounts",
+			ArgsUsage:   "<action> [alias]",
+			Description: "Manages account aliases, which can be given to --accountkey instead of the account switch key they name.",
+			UsageText: fmt.Sprintf("Examples:\n\n   %v\n   %v",
+				"akamai accounts add --description 'ACME production' acme 1-ABC:1-2XYZ",
+				"akamai --accountkey acme property-manager list-groups"),
+			Subcommands: []*cli.Command{
+				{
+					Name:      "add",
+					ArgsUsage: "<alias> <key>",
+					Action:    withHomeLock(cmdAccountsAdd),
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "description",
+							Usage: "Description of the account, shown by 'akamai accounts list'.",
+						},
+					},
+				},
+				{
+					Name:   "list",
+					Action: cmdAccountsList,
+				},
+				{
+					Name:         "remove",
+					Aliases:      []string{"rm"},
+					ArgsUsage:    "<alias>",
+					Action:       withHomeLock(cmdAccountsRemove),
+					BashComplete: completeAccountAliases,
+				},
+			},
+			HideHelp:     true,
+			BashComplete: autocomplete.Default,
+		},
+		{
+			Name:        "bundle",
+			ArgsUsage:   "<command>...",
+			Description: "Creates an archive of the package containing a given <command>, which can be installed without network access using \"akamai install <file>\".",
+			Action:      cmdBundle(langManager),
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "dir",
+					Value: ".",
+					Usage: "Writes the bundles to the given `directory`.",
+				},
+				&cli.StringSliceFlag{
+					Name:  "platform",
+					Usage: "Includes binaries for the given `OS/ARCH`, for example linux/amd64. Can be repeated. Defaults to the current platform.",
+				},
+			},
+			HideHelp:     true,
+			BashComplete: autocomplete.Default,
+		},
 		{
 			Name:        "config",
 			ArgsUsage:   "<action> <setting> [value]",
 			Description: "Manages configuration.",
 			Subcommands: []*cli.Command{
+				{
+					Name:      "describe",
+					ArgsUsage: "[section | setting]",
+					Action:    cmdConfigDescribe,
+				},
+				{
+					Name:   "export",
+					Action: cmdConfigExport,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "format",
+							Usage: "Writes the config in the given `format`: ini, json or env.",
+							Value: config.FormatINI,
+						},
+					},
+				},
 				{
 					Name:      "get",
 					ArgsUsage: "<setting>",
 					Action:    cmdConfigGet,
 				},
+				{
+					Name:      "import",
+					ArgsUsage: "<file>",
+					Action:    withHomeLock(cmdConfigImport),
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "format",
+							Usage: "Reads the file in the given `format`: ini, json or env. By default, it is guessed from the file extension.",
+						},
+						&cli.BoolFlag{
+							Name:  "merge",
+							Usage: "Keeps settings missing from the file. This is the default.",
+						},
+						&cli.BoolFlag{
+							Name:  "replace",
+							Usage: "Removes settings missing from the file.",
+						},
+						&cli.BoolFlag{
+							Name:  "dry-run",
+							Usage: "Shows the changes without saving them.",
+						},
+					},
+				},
 				{
 					Name:      "set",
 					ArgsUsage: "<setting> <value>",
-					Action:    cmdConfigSet,
+					Action:    withHomeLock(cmdConfigSet),
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "scope",
+							Usage: "Writes the value to the system, user or project config file.",
+							Value: string(config.ScopeUser),
+						},
+						&cli.StringFlag{
+							Name:  "package",
+							Usage: "Sets <setting> in the section of the given package, passed only to that package's commands.",
+						},
+						&cli.StringFlag{
+							Name:  "profile",
+							Usage: "Sets the value in the given profile instead of the config.",
+						},
+					},
 				},
 				{
 					Name:      "list",
 					ArgsUsage: "[section]",
 					Action:    cmdConfigList,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "show-origin",
+							Usage: "Shows the scope and file each value comes from.",
+						},
+						&cli.StringFlag{
+							Name:  "package",
+							Usage: "Lists only the settings of the given package.",
+						},
+					},
+				},
+				{
+					Name:   "migrate",
+					Action: withHomeLock(cmdConfigMigrate),
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:  "dry-run",
+							Usage: "Shows pending migrations without changing the config.",
+						},
+					},
 				},
 				{
 					Name:      "unset",
 					Aliases:   []string{"rm"},
 					ArgsUsage: "<setting>",
-					Action:    cmdConfigUnset,
+					Action:    withHomeLock(cmdConfigUnset),
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "profile",
+							Usage: "Removes the value from the given profile instead of the config.",
+						},
+					},
+				},
+			},
+			HideHelp:     true,
+			BashComplete: autocomplete.Default,
+		},
+		{
+			Name:        "context",
+			ArgsUsage:   "<action> [name]",
+			Description: "Manages credential contexts, which bundle the --edgerc, --section and --accountkey values used when those flags are not given.",
+			Subcommands: []*cli.Command{
+				{
+					Name:      "create",
+					ArgsUsage: "<name>",
+					Action:    withHomeLock(cmdContextCreate),
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "edgerc",
+							Usage: "Location of the credentials file. When not given, the --edgerc flag or $HOME/.edgerc is used.",
+						},
+						&cli.StringFlag{
+							Name:  "section",
+							Usage: "Section of the credentials file.",
+						},
+						&cli.StringFlag{
+							Name:    "account-key",
+							Aliases: []string{"accountkey"},
+							Usage:   "Account switch key.",
+						},
+					},
+				},
+				{
+					Name:   "current",
+					Action: cmdContextCurrent,
+				},
+				{
+					Name:      "delete",
+					Aliases:   []string{"rm"},
+					ArgsUsage: "<name>",
+					Action:    withHomeLock(cmdContextDelete),
+				},
+				{
+					Name:   "list",
+					Action: cmdContextList,
+				},
+				{
+					Name:      "use",
+					ArgsUsage: "<name>",
+					Action:    withHomeLock(cmdContextUse),
+				},
+			},
+			HideHelp:     true,
+			BashComplete: autocomplete.Default,
+		},
+		{
+			Name:        "curl",
+			ArgsUsage:   "<API path>",
+			Description: "Sends a request signed with the EdgeGrid credentials of the --edgerc file and --section to their API host, and prints the response. An account switch key given with --accountkey is added as the accountSwitchKey query parameter.",
+			UsageText: fmt.Sprintf("Examples:\n\n   %v\n   %v\n   %v",
+				"akamai curl /identity-management/v3/user-profile",
+				"akamai --section prod curl -X PUT -d @body.json /papi/v1/properties/prp_1/versions/2/rules",
+				"cat body.json | akamai curl -d @- -H 'PAPI-Use-Prefixes: true' /papi/v1/properties"),
+			Action: cmdCurl(http.DefaultClient),
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "request",
+					Aliases: []string{"X"},
+					Usage:   "HTTP `method` of the request. Defaults to POST when --data is given, GET otherwise.",
+				},
+				&cli.StringSliceFlag{
+					Name:    "header",
+					Aliases: []string{"H"},
+					Usage:   "Adds a `'Name: value'` header to the request. Can be repeated.",
+				},
+				&cli.StringFlag{
+					Name:    "data",
+					Aliases: []string{"d"},
+					Usage:   "Request `body`. Use @<file> to read it from a file, or @- to read it from stdin. Sent as application/json unless a Content-Type header is given.",
+				},
+				&cli.BoolFlag{
+					Name:    "include",
+					Aliases: []string{"i"},
+					Usage:   "Prints the response status and headers before the body.",
+				},
+			},
+			HideHelp:     true,
+			BashComplete: autocomplete.Default,
+		},
+		{
+			Name:        "edgerc",
+			ArgsUsage:   "<action> [section]",
+			Description: "Manages the credentials file given with --edgerc, $HOME/.edgerc by default.",
+			Subcommands: []*cli.Command{
+				{
+					Name:         "add",
+					ArgsUsage:    "[section]",
+					Action:       cmdEdgercAdd,
+					BashComplete: completeEdgercSections,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "host",
+							Usage: "API host of the credentials, such as akab-xxx.luna.akamaiapis.net. Prompted for if not given.",
+						},
+						&cli.StringFlag{
+							Name:  "client-token",
+							Usage: "Client token of the credentials. Prompted for if not given.",
+						},
+						&cli.StringFlag{
+							Name:  "client-secret",
+							Usage: "Client secret of the credentials. Prompted for if not given.",
+						},
+						&cli.StringFlag{
+							Name:  "access-token",
+							Usage: "Access token of the credentials. Prompted for if not given.",
+						},
+						&cli.StringFlag{
+							Name:  "account-key",
+							Usage: "Account switch key used with the credentials.",
+						},
+					},
+				},
+				{
+					Name:   "list",
+					Action: cmdEdgercList,
+				},
+				{
+					Name:         "remove",
+					Aliases:      []string{"rm"},
+					ArgsUsage:    "[section]",
+					Action:       cmdEdgercRemove,
+					BashComplete: completeEdgercSections,
+				},
+				{
+					Name:         "show",
+					ArgsUsage:    "[section]",
+					Action:       cmdEdgercShow,
+					BashComplete: completeEdgercSections,
+				},
+				{
+					Name:         "validate",
+					ArgsUsage:    "[section]",
+					Action:       cmdEdgercValidate,
+					BashComplete: completeEdgercSections,
 				},
 			},
 			HideHelp:     true,
@@ -186,21 +466,42 @@ func createBuiltinCommands() []*cli.Command {
 		{
 			Name:        "install",
 			Aliases:     []string{"get"},
-			ArgsUsage:   "<package name or repository URL>...",
+			ArgsUsage:   "<package name or repository URL>[@<version> | #<ref>]...",
 			Description: "Fetches and installs packages from a Git repository.",
-			Action:      cmdInstall(gitRepo, langManager),
-			UsageText: fmt.Sprintf("Examples:\n\n   %v\n,  %v\n   %v\n   %v",
+			Action:      withHomeLock(withCommandIndexRebuild(cmdInstall(gitRepo, langManager))),
+			UsageText: fmt.Sprintf("Examples:\n\n   %v\n,  %v\n   %v\n   %v\n   %v\n   %v\n   %v",
 				"akamai install property purge",
 				"akamai install akamai/cli-property",
 				"akamai install git@github.com:akamai/cli-property.git",
-				"akamai install https://github.com/akamai/cli-property.git"),
+				"akamai install https://github.com/akamai/cli-property.git",
+				"akamai install property@v2.3.1",
+				"akamai install akamai/cli-purge#feature-x",
+				"akamai install --from akamai.lock"),
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "from",
+					Usage: "Installs the packages pinned in the given lock file.",
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Shows what would be installed without changing anything.",
+				},
+			},
+			HideHelp:     true,
+			BashComplete: autocomplete.Default,
+		},
+		{
+			Name:         "lock",
+			ArgsUsage:    "[file]",
+			Description:  "Records installed packages and their exact revisions in a lock file. Defaults to akamai.lock.",
+			Action:       cmdLock(gitRepo),
 			HideHelp:     true,
 			BashComplete: autocomplete.Default,
 		},
 		{
 			Name:        "list",
 			Description: "By default, displays installed commands. Optionally, can display package commands from Git repositories.",
-			Action:      cmdList,
+			Action:      cmdList(gitRepo),
 			Flags: []cli.Flag{
 				&cli.BoolFlag{
 					Name:  "remote",
@@ -211,6 +512,56 @@ func createBuiltinCommands() []*cli.Command {
 			BashComplete:       autocomplete.Default,
 			CustomHelpTemplate: apphelp.SimplifiedHelpTemplate,
 		},
+		{
+			Name:         "outdated",
+			Description:  "Lists installed packages with newer versions available, without updating them. Exits with code 2 if there are any.",
+			Action:       withHomeLock(cmdOutdated(gitRepo)),
+			HideHelp:     true,
+			BashComplete: autocomplete.Default,
+		},
+		{
+			Name:        "profile",
+			ArgsUsage:   "<action> [name]",
+			Description: "Manages config profiles, which override config values when selected with --profile or AKAMAI_CLI_PROFILE.",
+			Subcommands: []*cli.Command{
+				{
+					Name:      "create",
+					ArgsUsage: "<name>",
+					Action:    withHomeLock(cmdProfileCreate),
+				},
+				{
+					Name:      "delete",
+					Aliases:   []string{"rm"},
+					ArgsUsage: "<name>",
+					Action:    withHomeLock(cmdProfileDelete),
+				},
+				{
+					Name:   "list",
+					Action: cmdProfileList,
+				},
+				{
+					Name:      "use",
+					ArgsUsage: "<name>",
+					Action:    withHomeLock(cmdProfileUse),
+				},
+			},
+			HideHelp:     true,
+			BashComplete: autocomplete.Default,
+		},
+		{
+			Name:        "rollback",
+			ArgsUsage:   "<command>",
+			Description: "Restores the previously installed revision of the package containing a given <command>.",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "to",
+					Usage: "Restore the previous install with the given `version` instead of the latest one",
+				},
+			},
+			Action:       withHomeLock(withCommandIndexRebuild(cmdRollback(gitRepo, langManager))),
+			HideHelp:     true,
+			BashComplete: autocomplete.Default,
+		},
 		{
 			Name:         "search",
 			ArgsUsage:    "<keyword>...",
@@ -221,18 +572,70 @@ func createBuiltinCommands() []*cli.Command {
 			BashComplete: autocomplete.Default,
 		},
 		{
-			Name:         "uninstall",
-			ArgsUsage:    "<command>...",
-			Description:  "Uninstalls a package containing a given <command>.",
-			Action:       cmdUninstall(langManager),
+			Name:        "secrets",
+			ArgsUsage:   "<action> [name]",
+			Description: "Manages secrets stored in a vault encrypted with a passphrase. Config and credentials file values can reference a secret as secret://<name>, which is replaced with the secret when the value is used.",
+			UsageText: fmt.Sprintf("Examples:\n\n   %v\n   %v\n   %v",
+				"akamai secrets set prod/client_secret",
+				"akamai secrets set prod/client_secret - < secret.txt",
+				"akamai edgerc add --client-secret secret://prod/client_secret prod"),
+			Subcommands: []*cli.Command{
+				{
+					Name:      "get",
+					ArgsUsage: "<name>",
+					Action:    cmdSecretsGet,
+				},
+				{
+					Name:   "list",
+					Action: cmdSecretsList,
+				},
+				{
+					Name:      "remove",
+					Aliases:   []string{"rm"},
+					ArgsUsage: "<name>",
+					Action:    withHomeLock(cmdSecretsRemove),
+				},
+				{
+					Name:      "set",
+					ArgsUsage: "<name> [value]",
+					Usage:     "Stores a secret. The value is asked for when not given, use '-' to read it from stdin.",
+					Action:    withHomeLock(cmdSecretsSet),
+				},
+			},
+			HideHelp:     true,
+			BashComplete: autocomplete.Default,
+		},
+		{
+			Name:        "uninstall",
+			ArgsUsage:   "<command>...",
+			Description: "Uninstalls a package containing a given <command>.",
+			Action:      withHomeLock(withCommandIndexRebuild(cmdUninstall(langManager))),
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Shows what would be removed without changing anything.",
+				},
+			},
 			HideHelp:     true,
 			BashComplete: autocomplete.Default,
 		},
 		{
-			Name:         "update",
-			ArgsUsage:    "[<command>...]",
-			Description:  "Updates one or more commands. If no command is specified, all commands are updated.",
-			Action:       cmdUpdate(gitRepo, langManager),
+			Name:        "update",
+			ArgsUsage:   "[<command>...]",
+			Description: "Updates one or more commands. If no command is specified, all commands are updated.",
+			Action:      withHomeLock(withCommandIndexRebuild(cmdUpdate(gitRepo, langManager))),
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:    "jobs",
+					Aliases: []string{"j"},
+					Value:   4,
+					Usage:   "Update at most `N` packages at the same time",
+				},
+				&cli.BoolFlag{
+					Name:  "dry-run",
+					Usage: "Shows what would be updated without changing anything.",
+				},
+			},
 			HideHelp:     true,
 			BashComplete: autocomplete.Default,
 		},
@@ -245,14 +648,10 @@ func createBuiltinCommands() []*cli.Command {
 	}
 }
 
-func createInstalledCommands(_ context.Context, gitRepo git.Repository, langManager packages.LangManager) []*cli.Command {
+func createInstalledCommands(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager) []*cli.Command {
 	commands := make([]*cli.Command, 0)
-	packagePaths := getPackagePaths()
-	for _, dir := range packagePaths {
-		pkg, err := readPackage(dir)
-		if err == nil {
-			commands = append(commands, subcommandToCliCommands(pkg, gitRepo, langManager)...)
-		}
+	for _, pkg := range installedPackages(ctx) {
+		commands = append(commands, subcommandToCliCommands(pkg, gitRepo, langManager)...)
 	}
 	return commands
 }
@@ -268,34 +667,19 @@ func findExec(ctx context.Context, langManager packages.LangManager, cmd string)
 		cmdNameTitle += cases.Title(language.Und, cases.NoLower).String(strings.ToLower(cmdPart))
 	}
 
-	systemPath := os.Getenv("PATH")
-	packagePaths := getPackageBinPaths()
-	if err := os.Setenv("PATH", packagePaths); err != nil {
-		return nil, nil, err
-	}
+	packageDirs := getPackageBinDirs()
 
-	// Quick look for executables on the path
-	var path string
-	path, err := exec.LookPath(cmdName)
+	// Quick look for executables in the package directories
+	path, err := lookPath(cmdName, packageDirs)
 	if err != nil {
-		path, _ = exec.LookPath(cmdNameTitle)
+		path, _ = lookPath(cmdNameTitle, packageDirs)
 	}
 
 	if path != "" {
-		if err := os.Setenv("PATH", systemPath); err != nil {
-			return nil, nil, err
-		}
 		return []string{path}, &packages.LanguageRequirements{}, nil
 	}
 
-	if err := os.Setenv("PATH", systemPath); err != nil {
-		return nil, nil, err
-	}
-	if packagePaths == "" {
-		return nil, nil, packages.ErrNoExeFound
-	}
-
-	for _, path := range filepath.SplitList(packagePaths) {
+	for _, path := range packageDirs {
 		filePaths := []string{
 			// Search for <path>/akamai-command, <path>/akamaiCommand
 			filepath.Join(path, cmdName),
@@ -338,22 +722,6 @@ func findExec(ctx context.Context, langManager packages.LangManager, cmd string)
 	return nil, nil, packages.ErrNoExeFound
 }
 
-func getPackageBinPaths() string {
-	path := ""
-	if akamaiCliPath, err := tools.GetAkamaiCliSrcPath(); err == nil {
-		paths, _ := filepath.Glob(filepath.Join(akamaiCliPath, "*"))
-		if len(paths) > 0 {
-			path += strings.Join(paths, string(os.PathListSeparator))
-		}
-		paths, _ = filepath.Glob(filepath.Join(akamaiCliPath, "*", "bin"))
-		if len(paths) > 0 {
-			path += string(os.PathListSeparator) + strings.Join(paths, string(os.PathListSeparator))
-		}
-	}
-
-	return path
-}
-
 // passthruCommand performs the external Cmd invocation and previous set up, if required
 func passthruCommand(ctx context.Context, subCmd Cmd, langManager packages.LangManager, languageRequirements packages.LanguageRequirements, dirName string) error {
 	/*
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/color"
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/urfave/cli/v2"
+)
+
+func cmdAccountsList(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("ACCOUNTS LIST START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("ACCOUNTS LIST FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("ACCOUNTS LIST ERROR: %v", e))
+		}
+	}()
+	term := terminal.Get(c.Context)
+
+	accounts := config.Accounts(config.Get(c.Context))
+	if isStructuredOutput(c) {
+		return writeOutput(term, outputFormat(c), accounts)
+	}
+
+	if len(accounts) == 0 {
+		term.Printf("No accounts defined, add one with 'akamai accounts add <alias> <key>'.\n")
+		return nil
+	}
+	for _, a := range accounts {
+		term.Printf("%s\t%s\t%s\n", color.GreenString("%s", a.Alias), a.Key, a.Description)
+	}
+	return nil
+}
+
+func cmdAccountsAdd(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("ACCOUNTS ADD START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("ACCOUNTS ADD FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("ACCOUNTS ADD ERROR: %v", e))
+		}
+	}()
+	cfg := config.Get(c.Context)
+
+	if c.NArg() != 2 {
+		return cli.Exit(color.RedString("Unable to add account: alias and account switch key have to be provided"), 1)
+	}
+	account := config.Account{
+		Alias:       c.Args().Get(0),
+		Key:         c.Args().Get(1),
+		Description: c.String("description"),
+	}
+	if err := config.AddAccount(cfg, account); err != nil {
+		logger.Error(fmt.Sprintf("Error adding account: %v", err))
+		return cli.Exit(color.RedString("Unable to add account: %v", err), 1)
+	}
+	if err := cfg.Save(c.Context); err != nil {
+		logger.Error(fmt.Sprintf("Error saving config: %v", err))
+		return cli.Exit(color.RedString("Unable to add account: %v", err), 1)
+	}
+	terminal.Get(c.Context).Printf("Added account %s, use it with '--accountkey %s'.\n", account.Alias, account.Alias)
+	return nil
+}
+
+func cmdAccountsRemove(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("ACCOUNTS REMOVE START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("ACCOUNTS REMOVE FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("ACCOUNTS REMOVE ERROR: %v", e))
+		}
+	}()
+	cfg := config.Get(c.Context)
+
+	alias := c.Args().First()
+	if alias == "" {
+		return cli.Exit(color.RedString("Unable to remove account: account alias has to be provided"), 1)
+	}
+	if err := config.RemoveAccount(cfg, alias); err != nil {
+		logger.Error(fmt.Sprintf("Error removing account: %v", err))
+		return cli.Exit(color.RedString("Unable to remove account: %v", err), 1)
+	}
+	if err := cfg.Save(c.Context); err != nil {
+		logger.Error(fmt.Sprintf("Error saving config: %v", err))
+		return cli.Exit(color.RedString("Unable to remove account: %v", err), 1)
+	}
+	terminal.Get(c.Context).Printf("Removed account %s.\n", alias)
+	return nil
+}
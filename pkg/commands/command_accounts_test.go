@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCmdAccounts(t *testing.T) {
+	accounts := map[string]map[string]string{
+		"account acme": {"key": "1-ABC:1-2XYZ", "description": "ACME production"},
+		"account beta": {"key": "1-BETA"},
+	}
+	tests := map[string]struct {
+		args       []string
+		globalArgs []string
+		init       func(*mocked)
+		withError  string
+	}{
+		"list accounts": {
+			args: []string{"list"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(accounts).Once()
+				m.term.On("Printf", "%s\t%s\t%s\n", []interface{}{"acme", "1-ABC:1-2XYZ", "ACME production"}).Return().Once()
+				m.term.On("Printf", "%s\t%s\t%s\n", []interface{}{"beta", "1-BETA", ""}).Return().Once()
+			},
+		},
+		"list accounts as json": {
+			args:       []string{"list"},
+			globalArgs: []string{"--output", "json"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(map[string]map[string]string{"account beta": accounts["account beta"]}).Once()
+				m.term.On("Printf", "%s\n", []interface{}{`[
+  {
+    "alias": "beta",
+    "key": "1-BETA"
+  }
+]`}).Return().Once()
+			},
+		},
+		"list without accounts": {
+			args: []string{"list"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(map[string]map[string]string{}).Once()
+				m.term.On("Printf", "No accounts defined, add one with 'akamai accounts add <alias> <key>'.\n", []interface{}(nil)).Return().Once()
+			},
+		},
+		"add account": {
+			args: []string{"add", "--description", "Dev account", "dev", "1-DEV:1-2ABC"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(accounts).Once()
+				m.cfg.On("SetValue", "account dev", "key", "1-DEV:1-2ABC").Return().Once()
+				m.cfg.On("SetValue", "account dev", "description", "Dev account").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+				m.term.On("Printf", "Added account %s, use it with '--accountkey %s'.\n", []interface{}{"dev", "dev"}).Return().Once()
+			},
+		},
+		"add existing account": {
+			args: []string{"add", "acme", "1-OTHER"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(accounts).Once()
+			},
+			withError: "Unable to add account: account already exists: acme",
+		},
+		"add account without key": {
+			args:      []string{"add", "dev"},
+			init:      func(*mocked) {},
+			withError: "Unable to add account: alias and account switch key have to be provided",
+		},
+		"add account with invalid alias": {
+			args:      []string{"add", "dev account", "1-DEV"},
+			init:      func(*mocked) {},
+			withError: `Unable to add account: invalid account alias "dev account"`,
+		},
+		"error saving added account": {
+			args: []string{"add", "dev", "1-DEV"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(accounts).Once()
+				m.cfg.On("SetValue", "account dev", "key", "1-DEV").Return().Once()
+				m.cfg.On("Save").Return(fmt.Errorf("save error")).Once()
+			},
+			withError: "Unable to add account: save error",
+		},
+		"remove account": {
+			args: []string{"remove", "acme"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(accounts).Once()
+				for _, key := range []string{"key", "description"} {
+					m.cfg.On("Origin", "account acme", key).Return(config.Origin{Scope: config.ScopeUser}, true).Once()
+					m.cfg.On("UnsetValue", "account acme", key).Return().Once()
+				}
+				m.cfg.On("Save").Return(nil).Once()
+				m.term.On("Printf", "Removed account %s.\n", []interface{}{"acme"}).Return().Once()
+			},
+		},
+		"remove unknown account": {
+			args: []string{"remove", "dev"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(accounts).Once()
+			},
+			withError: "Unable to remove account: unknown account: dev",
+		},
+		"remove account defined in system file": {
+			args: []string{"remove", "acme"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(accounts).Once()
+				m.cfg.On("Origin", "account acme", "key").Return(config.Origin{Scope: config.ScopeSystem, Path: "/etc/akamai/config"}, true).Once()
+			},
+			withError: "Unable to remove account: account acme is defined in /etc/akamai/config, remove it from that file instead",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil, nil}
+			command := &cli.Command{
+				Name: "accounts",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "add",
+						Action: cmdAccountsAdd,
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "description"},
+						},
+					},
+					{Name: "list", Action: cmdAccountsList},
+					{Name: "remove", Action: cmdAccountsRemove},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, test.globalArgs...)
+			args = append(args, "accounts")
+			args = append(args, test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
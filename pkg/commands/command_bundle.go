@@ -0,0 +1,624 @@
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/color"
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/packages"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/akamai/cli/v2/pkg/tools"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	bundleVersion      = 1
+	bundleManifestName = "bundle.json"
+	bundlePackageDir   = "package"
+	bundleVenvDir      = "venv"
+	bundleBinDir       = "bin"
+)
+
+// bundleManifest describes the package contained in a bundle.
+// Binary bundles contain binaries for each of the platforms, source bundles the package as installed on the platform.
+type bundleManifest struct {
+	Version   int       `json:"version"`
+	Package   string    `json:"package"`
+	Binary    bool      `json:"binary"`
+	Platforms []string  `json:"platforms"`
+	Created   time.Time `json:"created"`
+	// Repository and Commit record where a binary package was installed from, see packageOrigin
+	Repository string `json:"repository,omitempty"`
+	Commit     string `json:"commit,omitempty"`
+}
+
+func cmdBundle(langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		logger := log.FromContext(c.Context)
+		start := time.Now()
+		logger.Debug("BUNDLE START")
+		defer func() {
+			if e == nil {
+				logger.Debug(fmt.Sprintf("BUNDLE FINISH: %v", time.Since(start)))
+			} else {
+				logger.Error(fmt.Sprintf("BUNDLE ERROR: %v", e))
+			}
+		}()
+		term := terminal.Get(c.Context)
+
+		if !c.Args().Present() {
+			return cli.Exit(color.RedString("You must specify at least one command to bundle"), 1)
+		}
+
+		platforms := c.StringSlice("platform")
+		if len(platforms) == 0 {
+			platforms = []string{hostPlatform()}
+		}
+		for _, platform := range platforms {
+			if _, _, err := splitPlatform(platform); err != nil {
+				return cli.Exit(color.RedString("%s", tools.CapitalizeFirstWord(err.Error())), 1)
+			}
+		}
+
+		for _, cmd := range c.Args().Slice() {
+			exec, _, err := findExec(c.Context, langManager, cmd)
+			if err != nil {
+				logger.Error(fmt.Sprintf("Command \"%s\" not found: %v", cmd, err))
+				return cli.Exit(color.RedString("Command \"%s\" not found. Try \"%s help\".\n", cmd, tools.Self()), 1)
+			}
+			repoDir := findPackageDir(filepath.Dir(exec[len(exec)-1]))
+			if repoDir == "" {
+				logger.Error("Unable to find package directory")
+				return cli.Exit(color.RedString("unable to bundle, was it installed using %s", color.CyanString("\"akamai install\"")+"?"), 1)
+			}
+
+			term.Spinner().Start("Bundling \"%s\" command...", cmd)
+			file, err := createBundle(c.Context, repoDir, c.String("dir"), platforms)
+			if err != nil {
+				term.Spinner().Fail()
+				logger.Error(fmt.Sprintf("Unable to bundle package: %v", err))
+				return cli.Exit(color.RedString("Unable to bundle package: %v", err), 1)
+			}
+			term.Spinner().OK()
+			term.Printf("Bundle written to %s\n", color.BlueString("%s", file))
+		}
+
+		return nil
+	}
+}
+
+// createBundle writes the archive of the package in repoDir into outDir and returns its path.
+// Binaries of binary packages are downloaded for each of the platforms.
+func createBundle(ctx context.Context, repoDir, outDir string, platforms []string) (string, error) {
+	logger := log.FromContext(ctx)
+	cmdPackage, err := readPackage(repoDir)
+	if err != nil {
+		return "", err
+	}
+	name := filepath.Base(repoDir)
+
+	manifest := bundleManifest{
+		Version:   bundleVersion,
+		Package:   name,
+		Binary:    isBinary(cmdPackage),
+		Platforms: []string{hostPlatform()},
+		Created:   time.Now().UTC(),
+	}
+	if manifest.Binary {
+		manifest.Platforms = platforms
+		if origin, err := readPackageOrigin(repoDir); err == nil {
+			manifest.Repository = origin.Repository
+			manifest.Commit = origin.Commit
+		}
+	}
+
+	file := filepath.Join(outDir, fmt.Sprintf("%s-%s.tar.gz", name, packageVersion(cmdPackage)))
+	out, err := os.Create(file)
+	if err != nil {
+		return "", err
+	}
+	if err := writeBundle(ctx, out, repoDir, cmdPackage, manifest); err != nil {
+		_ = out.Close()
+		if err := os.Remove(file); err != nil {
+			logger.Warn(fmt.Sprintf("Unable to remove incomplete bundle %s: %v", file, err))
+		}
+		return "", err
+	}
+	return file, out.Close()
+}
+
+func writeBundle(ctx context.Context, out io.Writer, repoDir string, cmdPackage subcommands, manifest bundleManifest) error {
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeTarFile(tw, bundleManifestName, data); err != nil {
+		return err
+	}
+
+	if manifest.Binary {
+		if err := addTarFile(tw, filepath.Join(repoDir, "cli.json"), path.Join(bundlePackageDir, "cli.json")); err != nil {
+			return err
+		}
+		if err := addBundleBinaries(ctx, tw, cmdPackage, manifest.Platforms); err != nil {
+			return err
+		}
+	} else {
+		if err := addTarDir(tw, repoDir, bundlePackageDir); err != nil {
+			return err
+		}
+		venvPath, err := tools.GetPkgVenvPath(manifest.Package)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(venvPath); err == nil {
+			if err := addTarDir(tw, venvPath, bundleVenvDir); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// addBundleBinaries downloads and verifies the binaries of all package commands for each platform
+func addBundleBinaries(ctx context.Context, tw *tar.Writer, cmdPackage subcommands, platforms []string) error {
+	tmpDir, err := os.MkdirTemp("", "akamai-bundle")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			log.FromContext(ctx).Warn(fmt.Sprintf("Unable to remove temporary dir %s: %v", tmpDir, err))
+		}
+	}()
+
+	for _, platform := range platforms {
+		goos, goarch, _ := splitPlatform(platform)
+		binDir := filepath.Join(tmpDir, goos+"-"+goarch)
+		if err := os.MkdirAll(binDir, 0700); err != nil {
+			return err
+		}
+		for _, cmd := range cmdPackage.Commands {
+			if err := downloadPlatformBin(ctx, binDir, cmd, goos, goarch); err != nil {
+				return fmt.Errorf("unable to download %s binary for %s: %w", cmd.Name, platform, err)
+			}
+		}
+		if err := addTarDir(tw, binDir, path.Join(bundleBinDir, goos+"-"+goarch)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isBundleFile reports whether an install argument is a package bundle on the local file system
+func isBundleFile(arg string) bool {
+	if !strings.HasSuffix(arg, ".tar.gz") && !strings.HasSuffix(arg, ".tgz") {
+		return false
+	}
+	info, err := os.Stat(arg)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// installBundle installs a package from a bundle without accessing the network.
+// Binary bundles must contain binaries for the current platform, source bundles must have been created on it.
+func installBundle(ctx context.Context, file string) (*subcommands, error) {
+	logger := log.FromContext(ctx)
+	term := terminal.Get(ctx)
+	logger.Debug(fmt.Sprintf("Installing package from bundle: %s", file))
+
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Unable to get akamai cli source path: %v", err))
+		return nil, err
+	}
+	if err := os.MkdirAll(srcPath, 0700); err != nil {
+		return nil, err
+	}
+
+	term.Spinner().Start("Installing package from bundle %s...", file)
+	tmpDir, err := os.MkdirTemp(srcPath, ".bundle_")
+	if err != nil {
+		term.Spinner().Fail()
+		return nil, err
+	}
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			logger.Warn(fmt.Sprintf("Unable to remove temporary dir %s: %v", tmpDir, err))
+		}
+	}()
+
+	if err := extractBundle(file, tmpDir); err != nil {
+		term.Spinner().Fail()
+		logger.Error(fmt.Sprintf("Unable to extract bundle: %v", err))
+		return nil, cli.Exit(color.RedString("Unable to extract bundle: %v", err), 1)
+	}
+
+	manifest, err := readBundleManifest(tmpDir)
+	if err != nil {
+		term.Spinner().Fail()
+		logger.Error(err.Error())
+		return nil, cli.Exit(color.RedString("%s", tools.CapitalizeFirstWord(err.Error())), 1)
+	}
+
+	packageDir := filepath.Join(srcPath, manifest.Package)
+	if _, err := os.Stat(packageDir); err == nil {
+		term.Spinner().WarnOK()
+		warningMsg := fmt.Sprintf("Package directory already exists (%s). To reinstall this package, first run 'akamai uninstall' command.", packageDir)
+		logger.Warn(warningMsg)
+		return nil, cli.Exit(color.YellowString("%s", warningMsg), 0)
+	}
+
+	unmark, err := markInstalling(packageDir)
+	if err != nil {
+		term.Spinner().Fail()
+		logger.Error(fmt.Sprintf("Unable to mark package as being installed: %v", err))
+		return nil, err
+	}
+	if err := installBundleContents(tmpDir, packageDir, manifest); err != nil {
+		term.Spinner().Fail()
+		if err := os.RemoveAll(packageDir); err != nil {
+			logger.Error(fmt.Sprintf("Failed to remove package directory: %v", err))
+		} else {
+			unmark()
+		}
+		logger.Error(fmt.Sprintf("Unable to install bundle: %v", err))
+		return nil, cli.Exit(color.RedString("Unable to install bundle: %v", err), 1)
+	}
+	unmark()
+
+	cmdPackage, err := readPackage(packageDir)
+	if err != nil {
+		term.Spinner().Fail()
+		logger.Error(fmt.Sprintf("Failed to read package: %v", err))
+		return nil, cli.Exit(color.RedString("Unable to install bundle: %v", err), 1)
+	}
+	term.Spinner().OK()
+	logger.Debug(fmt.Sprintf("Package %s installed from bundle", manifest.Package))
+
+	return &cmdPackage, nil
+}
+
+// installBundleContents moves the extracted package, and its binaries or virtualenv, into place
+func installBundleContents(tmpDir, packageDir string, manifest bundleManifest) error {
+	platform := hostPlatform()
+	if !slices.Contains(manifest.Platforms, platform) {
+		if manifest.Binary {
+			return fmt.Errorf("bundle does not contain binaries for %s, only for: %s", platform, strings.Join(manifest.Platforms, ", "))
+		}
+		return fmt.Errorf("bundle was created on %s and cannot be installed on %s", strings.Join(manifest.Platforms, ", "), platform)
+	}
+
+	if err := os.Rename(filepath.Join(tmpDir, bundlePackageDir), packageDir); err != nil {
+		return err
+	}
+
+	if manifest.Binary {
+		goos, goarch, _ := splitPlatform(platform)
+		if err := os.Rename(filepath.Join(tmpDir, bundleBinDir, goos+"-"+goarch), filepath.Join(packageDir, "bin")); err != nil {
+			return err
+		}
+		// bundles created before origins were recorded only name the package
+		origin := packageOrigin{Repository: manifest.Repository, Commit: manifest.Commit}
+		if origin.Repository == "" {
+			origin.Repository = tools.Githubize(manifest.Package)
+		}
+		return writePackageOrigin(packageDir, origin)
+	}
+
+	venvDir := filepath.Join(tmpDir, bundleVenvDir)
+	if _, err := os.Stat(venvDir); err != nil {
+		return nil
+	}
+	venvPath, err := tools.GetPkgVenvPath(manifest.Package)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(venvPath); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(venvPath), 0700); err != nil {
+		return err
+	}
+	return os.Rename(venvDir, venvPath)
+}
+
+func readBundleManifest(dir string) (bundleManifest, error) {
+	var manifest bundleManifest
+	data, err := os.ReadFile(filepath.Join(dir, bundleManifestName))
+	if err != nil {
+		return manifest, fmt.Errorf("file is not a package bundle: %w", err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("unable to unmarshal bundle manifest: %w", err)
+	}
+	if manifest.Version != bundleVersion {
+		return manifest, fmt.Errorf("unsupported bundle version: %d", manifest.Version)
+	}
+	if manifest.Package == "" || manifest.Package != filepath.Base(manifest.Package) || strings.HasPrefix(manifest.Package, ".") {
+		return manifest, fmt.Errorf("invalid package name in bundle manifest: %q", manifest.Package)
+	}
+	return manifest, nil
+}
+
+// extractBundle extracts the bundle archive into dir.
+// Entries which would be written outside of dir are rejected.
+func extractBundle(file, dir string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.FromSlash(path.Clean(hdr.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("invalid path in bundle: %s", hdr.Name)
+		}
+		target := filepath.Join(dir, name)
+		if err := checkInsideDir(dir, filepath.Dir(target)); err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, fs.FileMode(hdr.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				_ = out.Close()
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported entry in bundle: %s", hdr.Name)
+		}
+	}
+}
+
+// checkInsideDir returns an error if target, after resolving symbolic links, is outside of dir
+func checkInsideDir(dir, target string) error {
+	resolvedDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	// the target may not exist yet, resolve its closest existing parent
+	existing := target
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(resolvedDir, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("invalid path in bundle: %s", target)
+	}
+	return nil
+}
+
+// addTarDir adds the contents of dir to the archive under prefix, in lexical order
+func addTarDir(tw *tar.Writer, dir, prefix string) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		name := path.Join(prefix, filepath.ToSlash(rel))
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: name + "/", Mode: int64(info.Mode().Perm()), ModTime: info.ModTime()})
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			return tw.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: name, Linkname: link, ModTime: info.ModTime()})
+		case info.Mode().IsRegular():
+			return addTarFile(tw, p, name)
+		default:
+			return nil
+		}
+	})
+}
+
+func addTarFile(tw *tar.Writer, file, name string) error {
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	hdr := &tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: int64(info.Mode().Perm()), Size: info.Size(), ModTime: info.ModTime()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// hostPlatform returns the platform Akamai CLI runs on as OS/ARCH
+func hostPlatform() string {
+	return runtime.GOOS + "/" + runtime.GOARCH
+}
+
+// splitPlatform splits a platform given as OS/ARCH
+func splitPlatform(platform string) (string, string, error) {
+	goos, goarch, ok := strings.Cut(platform, "/")
+	if !ok || goos == "" || goarch == "" || strings.Contains(goarch, "/") {
+		return "", "", fmt.Errorf("invalid platform %q, expected OS/ARCH, for example linux/amd64", platform)
+	}
+	return goos, goarch, nil
+}
+
+// planBundleInstall describes how a package would be installed from a bundle, without extracting it
+func planBundleInstall(file string) (dryRunPlan, error) {
+	plan := dryRunPlan{Action: dryRunInstall, Package: file, Repository: file, Strategy: installStrategyBundle}
+
+	manifest, cmdPackage, hasVenv, err := inspectBundle(file)
+	if err != nil {
+		return plan, err
+	}
+	plan.Package = manifest.Package
+	plan.Runtime = runtimeDescription(cmdPackage.Requirements)
+	plan.Version = packageVersion(cmdPackage)
+
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return plan, err
+	}
+	packageDir := filepath.Join(srcPath, manifest.Package)
+	if _, err := os.Stat(packageDir); err == nil {
+		plan.Notes = append(plan.Notes, fmt.Sprintf("Package directory already exists (%s), nothing would be installed. To reinstall this package, first run 'akamai uninstall' command.", packageDir))
+		return plan, nil
+	}
+	if !slices.Contains(manifest.Platforms, hostPlatform()) {
+		return plan, fmt.Errorf("bundle cannot be installed on %s, it is for: %s", hostPlatform(), strings.Join(manifest.Platforms, ", "))
+	}
+
+	plan.CreateDirs = []string{packageDir}
+	if hasVenv {
+		venvPath, err := tools.GetPkgVenvPath(manifest.Package)
+		if err != nil {
+			return plan, err
+		}
+		plan.CreateDirs = append(plan.CreateDirs, venvPath)
+	}
+	plan.AddCommands = commandNames(cmdPackage)
+	return plan, nil
+}
+
+// inspectBundle reads the manifest and package configuration of a bundle, and whether it contains a virtualenv
+func inspectBundle(file string) (bundleManifest, subcommands, bool, error) {
+	var manifest bundleManifest
+	var cmdPackage subcommands
+	var hasManifest, hasVenv bool
+
+	f, err := os.Open(file)
+	if err != nil {
+		return manifest, cmdPackage, false, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return manifest, cmdPackage, false, err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return manifest, cmdPackage, false, err
+		}
+
+		switch name := path.Clean(hdr.Name); {
+		case name == bundleManifestName:
+			if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+				return manifest, cmdPackage, false, fmt.Errorf("unable to unmarshal bundle manifest: %w", err)
+			}
+			hasManifest = true
+		case name == path.Join(bundlePackageDir, "cli.json"):
+			if err := json.NewDecoder(tr).Decode(&cmdPackage); err != nil {
+				return manifest, cmdPackage, false, fmt.Errorf("unable to unmarshal package: %w", err)
+			}
+			for key := range cmdPackage.Commands {
+				cmdPackage.Commands[key].Name = strings.ToLower(cmdPackage.Commands[key].Name)
+			}
+		case strings.HasPrefix(name, bundleVenvDir+"/"):
+			hasVenv = true
+		}
+	}
+
+	if !hasManifest {
+		return manifest, cmdPackage, false, errors.New("file is not a package bundle")
+	}
+	return manifest, cmdPackage, hasVenv, nil
+}
@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/git"
+	"github.com/akamai/cli/v2/pkg/packages"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestBundleRoundTrip(t *testing.T) {
+	h := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte("binary for " + r.URL.Path))
+		assert.NoError(t, err)
+	}))
+	defer h.Close()
+
+	tests := map[string]struct {
+		cliJSON   string
+		files     map[string]string
+		platforms []string
+		expected  map[string]string
+		origin    *packageOrigin
+		withError string
+	}{
+		"source package": {
+			cliJSON: `{"requirements":{"go":"1.14.0"},"commands":[{"name":"echo","version":"1.0.0"}]}`,
+			files: map[string]string{
+				filepath.Join("bin", "akamai-echo"):       "built binary",
+				filepath.Join("vendor", "dep", "dep.txt"): "vendored dependency",
+			},
+			expected: map[string]string{
+				filepath.Join("bin", "akamai-echo"):       "built binary",
+				filepath.Join("vendor", "dep", "dep.txt"): "vendored dependency",
+			},
+		},
+		"binary package for several platforms": {
+			cliJSON: `{"commands":[{"name":"echo","version":"1.0.0","bin":"` + h.URL + `/{{.OS}}/{{.Arch}}/akamai-{{.Name}}"}]}`,
+			files: map[string]string{
+				originFileName: `{"repository": "https://github.com/example/cli-echo.git", "commit": "0123456789abcdef0123456789abcdef01234567"}`,
+			},
+			platforms: []string{"windows/amd64", runtime.GOOS + "/" + runtime.GOARCH},
+			expected: map[string]string{
+				filepath.Join("bin", "akamai-echo"+binSuffix()): fmt.Sprintf("binary for /%s/%s/akamai-echo", urlOS(runtime.GOOS), runtime.GOARCH),
+			},
+			origin: &packageOrigin{Repository: "https://github.com/example/cli-echo.git", Commit: "0123456789abcdef0123456789abcdef01234567"},
+		},
+		"binary package without recorded origin": {
+			cliJSON:   `{"commands":[{"name":"echo","version":"1.0.0","bin":"` + h.URL + `/{{.OS}}/{{.Arch}}/akamai-{{.Name}}"}]}`,
+			platforms: []string{runtime.GOOS + "/" + runtime.GOARCH},
+			origin:    &packageOrigin{Repository: "https://github.com/akamai/cli-echo.git"},
+		},
+		"binary package for other platform": {
+			cliJSON:   `{"commands":[{"name":"echo","version":"1.0.0","bin":"` + h.URL + `/{{.OS}}/{{.Arch}}/akamai-{{.Name}}"}]}`,
+			platforms: []string{"plan9/386"},
+			withError: "bundle does not contain binaries for " + runtime.GOOS + "/" + runtime.GOARCH,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := t.TempDir()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+			defer func() {
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", filepath.Join(".", "testdata")))
+			}()
+			packageDir := filepath.Join(cliHome, ".akamai-cli", "src", "cli-echo")
+			require.NoError(t, os.MkdirAll(packageDir, 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(packageDir, "cli.json"), []byte(test.cliJSON), 0644))
+			for file, content := range test.files {
+				require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(packageDir, file)), 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(packageDir, file), []byte(content), 0755))
+			}
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.MockRepo{}, nil, nil}
+			ctx := terminal.Context(context.Background(), m.term)
+			m.term.On("Spinner").Return(m.term)
+			m.term.On("Start", "Installing package from bundle %s...", mock.Anything).Return().Once()
+
+			outDir := t.TempDir()
+			file, err := createBundle(ctx, packageDir, outDir, test.platforms)
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(outDir, "cli-echo-1.0.0.tar.gz"), file)
+			require.NoError(t, os.RemoveAll(packageDir))
+
+			if test.withError != "" {
+				m.term.On("Fail").Return().Once()
+				_, err := installBundle(ctx, file)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				_, err = os.Stat(packageDir)
+				assert.True(t, os.IsNotExist(err))
+				return
+			}
+
+			m.term.On("OK").Return().Once()
+			cmdPackage, err := installBundle(ctx, file)
+			require.NoError(t, err)
+			m.term.AssertExpectations(t)
+			assert.Equal(t, "echo", cmdPackage.Commands[0].Name)
+			for file, content := range test.expected {
+				data, err := os.ReadFile(filepath.Join(packageDir, file))
+				require.NoError(t, err)
+				assert.Equal(t, content, string(data))
+			}
+			if test.origin != nil {
+				origin, err := readPackageOrigin(packageDir)
+				require.NoError(t, err)
+				assert.Equal(t, *test.origin, origin)
+			}
+			entries, err := os.ReadDir(filepath.Join(cliHome, ".akamai-cli", "src"))
+			require.NoError(t, err)
+			assert.Len(t, entries, 1)
+		})
+	}
+}
+
+func TestCmdInstallFromBundle(t *testing.T) {
+	cliHome := t.TempDir()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+	defer func() {
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", filepath.Join(".", "testdata")))
+	}()
+
+	bundle := filepath.Join(t.TempDir(), "cli-echo-1.0.0.tar.gz")
+	ctx := terminal.Context(context.Background(), terminal.New(terminal.DiscardWriter(), nil, terminal.DiscardWriter()))
+	_, err := createBundle(ctx, filepath.Join("testdata", ".akamai-cli", "src", "cli-echo"), filepath.Dir(bundle), nil)
+	require.NoError(t, err)
+
+	m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.MockRepo{}, &packages.Mock{}, nil}
+	m.term.On("Spinner").Return(m.term)
+	m.term.On("Start", "Installing package from bundle %s...", []interface{}{bundle}).Return().Once()
+	m.term.On("OK").Return().Once()
+	m.term.On("Printf", mock.Anything, mock.Anything).Return()
+	m.term.On("Writeln", mock.Anything).Return(0, nil)
+
+	command := &cli.Command{
+		Name:   "install",
+		Action: cmdInstall(m.gitRepo, m.langManager),
+	}
+	app, appCtx := setupTestApp(command, m)
+	require.NoError(t, app.RunContext(appCtx, []string{os.Args[0], "install", bundle}))
+
+	m.term.AssertExpectations(t)
+	m.gitRepo.AssertExpectations(t)
+	_, err = os.Stat(filepath.Join(cliHome, ".akamai-cli", "src", "cli-echo", "bin", "akamai-echo"))
+	assert.NoError(t, err)
+	var found bool
+	for _, cmd := range app.Commands {
+		found = found || cmd.Name == "echo"
+	}
+	assert.True(t, found)
+}
+
+func TestExtractBundle(t *testing.T) {
+	tests := map[string]struct {
+		entries   []tar.Header
+		withError string
+	}{
+		"valid entries": {
+			entries: []tar.Header{
+				{Typeflag: tar.TypeDir, Name: "package/"},
+				{Typeflag: tar.TypeReg, Name: "package/cli.json", Mode: 0644},
+				{Typeflag: tar.TypeSymlink, Name: "venv/python", Linkname: "/usr/bin/python3"},
+			},
+		},
+		"parent directory": {
+			entries:   []tar.Header{{Typeflag: tar.TypeReg, Name: "../cli.json", Mode: 0644}},
+			withError: "invalid path in bundle: ../cli.json",
+		},
+		"file through symlink": {
+			entries: []tar.Header{
+				{Typeflag: tar.TypeSymlink, Name: "package", Linkname: "/tmp"},
+				{Typeflag: tar.TypeReg, Name: "package/cli.json", Mode: 0644},
+			},
+			withError: "invalid path in bundle",
+		},
+		"hard link": {
+			entries:   []tar.Header{{Typeflag: tar.TypeLink, Name: "cli.json", Linkname: "/etc/passwd"}},
+			withError: "unsupported entry in bundle: cli.json",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "bundle.tar.gz")
+			f, err := os.Create(file)
+			require.NoError(t, err)
+			gz := gzip.NewWriter(f)
+			tw := tar.NewWriter(gz)
+			for _, hdr := range test.entries {
+				hdr := hdr
+				require.NoError(t, tw.WriteHeader(&hdr))
+			}
+			require.NoError(t, tw.Close())
+			require.NoError(t, gz.Close())
+			require.NoError(t, f.Close())
+
+			err = extractBundle(file, t.TempDir())
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func binSuffix() string {
+	if runtime.GOOS == "windows" {
+		return ".exe"
+	}
+	return ""
+}
+
+func urlOS(goos string) string {
+	return strings.Replace(goos, "darwin", "mac", 1)
+}
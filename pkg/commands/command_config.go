@@ -2,6 +2,9 @@ package commands
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -26,14 +29,39 @@ func cmdConfigSet(c *cli.Context) (e error) {
 	}()
 	cfg := config.Get(c.Context)
 
-	section, key, err := parseConfigPath(c)
-	if err != nil {
-		logger.Error(fmt.Sprintf("Error parsing config path: %v", err))
-		return cli.Exit(color.RedString("Unable to set config value: %v", err), 1)
+	var section, key string
+	var err error
+	if c.IsSet("package") {
+		section, key = config.PackageSection(c.String("package")), strings.ReplaceAll(c.Args().First(), ".", "-")
+		if key == "" {
+			return cli.Exit(color.RedString("Unable to set config value: key of the package setting has to be provided"), 1)
+		}
+	} else {
+		if section, key, err = parseConfigPath(c); err != nil {
+			logger.Error(fmt.Sprintf("Error parsing config path: %v", err))
+			return cli.Exit(color.RedString("Unable to set config value: %v", err), 1)
+		}
 	}
 
 	value := strings.Join(c.Args().Tail(), " ")
-	cfg.SetValue(section, key, value)
+	if err := config.Validate(section, key, value); err != nil {
+		logger.Error(fmt.Sprintf("Invalid config value: %v", err))
+		return cli.Exit(color.RedString("Unable to set config value: %v", err), 1)
+	}
+	if c.IsSet("profile") {
+		if section, key, err = profileConfigPath(cfg, c.String("profile"), section, key); err != nil {
+			logger.Error(fmt.Sprintf("Error finding profile: %v", err))
+			return cli.Exit(color.RedString("Unable to set config value: %v", err), 1)
+		}
+	}
+	if c.IsSet("scope") {
+		if err := cfg.SetScopedValue(config.Scope(c.String("scope")), section, key, value); err != nil {
+			logger.Error(fmt.Sprintf("Error setting config value: %v", err))
+			return cli.Exit(color.RedString("Unable to set config value: %v", err), 1)
+		}
+	} else {
+		cfg.SetValue(section, key, value)
+	}
 	if err := cfg.Save(c.Context); err != nil {
 		logger.Error(fmt.Sprintf("Error saving config: %v", err))
 		return cli.Exit(color.RedString("Unable to set config value: %v", err), 1)
@@ -62,7 +90,10 @@ func cmdConfigGet(c *cli.Context) (e error) {
 		return cli.Exit(color.RedString("Unable to get config value: %v", err), 1)
 	}
 
-	val, _ := cfg.GetValue(section, key)
+	val, ok := cfg.GetValue(section, key)
+	if !ok {
+		val = config.DefaultValue(section, key)
+	}
 	if _, err := terminal.Get(c.Context).Writeln(val); err != nil {
 		return err
 	}
@@ -89,6 +120,12 @@ func cmdConfigUnset(c *cli.Context) (e error) {
 		logger.Error(fmt.Sprintf("Error parsing config path: %v", err))
 		return cli.Exit(color.RedString("Unable to unset config value: %v", err), 1)
 	}
+	if c.IsSet("profile") {
+		if section, key, err = profileConfigPath(cfg, c.String("profile"), section, key); err != nil {
+			logger.Error(fmt.Sprintf("Error finding profile: %v", err))
+			return cli.Exit(color.RedString("Unable to unset config value: %v", err), 1)
+		}
+	}
 
 	cfg.UnsetValue(section, key)
 	if err := cfg.Save(c.Context); err != nil {
@@ -114,27 +151,130 @@ func cmdConfigList(c *cli.Context) (e error) {
 	term := terminal.Get(c.Context)
 
 	allValues := cfg.Values()
-	if c.NArg() > 0 {
-		sectionName := c.Args().First()
-		section, ok := allValues[sectionName]
-		if !ok {
-			return nil
+	sectionName := c.Args().First()
+	if c.IsSet("package") {
+		sectionName = config.PackageSection(c.String("package"))
+	}
+	if sectionName != "" {
+		values := make(map[string]map[string]string)
+		if section, ok := allValues[sectionName]; ok {
+			values[sectionName] = section
 		}
+		allValues = values
+	}
+
+	if c.Bool("show-origin") {
+		return listConfigWithOrigin(c, cfg, allValues)
+	}
+	if isStructuredOutput(c) {
+		return writeOutput(term, outputFormat(c), allValues)
+	}
+
+	for sectionName, section := range allValues {
 		for key, value := range section {
 			term.Printf("%s.%s = %s\n", sectionName, key, value)
 		}
-
-		return nil
 	}
+	return nil
+}
+
+type configValue struct {
+	Value  string `json:"value" yaml:"value"`
+	Scope  string `json:"scope" yaml:"scope"`
+	Origin string `json:"origin" yaml:"origin"`
+}
 
+func listConfigWithOrigin(c *cli.Context, cfg config.Config, allValues map[string]map[string]string) error {
+	term := terminal.Get(c.Context)
+	values := make(map[string]map[string]configValue)
 	for sectionName, section := range allValues {
+		values[sectionName] = make(map[string]configValue)
 		for key, value := range section {
-			term.Printf("%s.%s = %s\n", sectionName, key, value)
+			origin, _ := cfg.Origin(sectionName, key)
+			values[sectionName][key] = configValue{Value: value, Scope: string(origin.Scope), Origin: origin.Path}
+		}
+	}
+	if isStructuredOutput(c) {
+		return writeOutput(term, outputFormat(c), values)
+	}
+
+	for sectionName, section := range values {
+		for key, value := range section {
+			term.Printf("%s:%s\t%s.%s = %s\n", value.Scope, value.Origin, sectionName, key, value.Value)
 		}
 	}
 	return nil
 }
 
+func cmdConfigDescribe(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("CONFIG DESCRIBE START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("CONFIG DESCRIBE FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("CONFIG DESCRIBE ERROR: %v", e))
+		}
+	}()
+	term := terminal.Get(c.Context)
+
+	keys := config.Keys()
+	if c.NArg() > 0 {
+		keys = filterConfigKeys(keys, c.Args().First())
+		if len(keys) == 0 {
+			return cli.Exit(color.RedString("Unable to describe config: %v %s", config.ErrUnknownKey, c.Args().First()), 1)
+		}
+	}
+	if isStructuredOutput(c) {
+		return writeOutput(term, outputFormat(c), keys)
+	}
+
+	for i, key := range keys {
+		if i > 0 {
+			term.Printf("\n")
+		}
+		term.Printf("%s\n", color.BlueString("%s.%s", key.Section, key.Name))
+		term.Printf("  Type:        %s\n", key.Type)
+		if key.Default != "" {
+			term.Printf("  Default:     %s\n", key.Default)
+		}
+		if len(key.Allowed) > 0 {
+			term.Printf("  Allowed:     %s\n", strings.Join(quoteAll(key.Allowed), ", "))
+		}
+		if key.ReadOnly {
+			term.Printf("  Read-only:   yes\n")
+		}
+		if key.Local {
+			term.Printf("  Local:       yes\n")
+		}
+		term.Printf("  Description: %s\n", key.Description)
+	}
+	return nil
+}
+
+// filterConfigKeys returns the keys matching <section>.<key>, or all keys of the section if only its name is given
+func filterConfigKeys(keys []config.Key, path string) []config.Key {
+	section, name, hasName := strings.Cut(path, ".")
+	name = strings.ReplaceAll(name, ".", "-")
+	var filtered []config.Key
+	for _, key := range keys {
+		if key.Section == section && (!hasName || key.Name == name) {
+			filtered = append(filtered, key)
+		}
+	}
+	return filtered
+}
+
+func quoteAll(values []string) []string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", v))
+	}
+	return quoted
+}
+
 func parseConfigPath(c *cli.Context) (string, string, error) {
 	path := strings.Split(c.Args().First(), ".")
 	if len(path) < 2 {
@@ -144,3 +284,267 @@ func parseConfigPath(c *cli.Context) (string, string, error) {
 	key := strings.Join(path[1:], "-")
 	return section, key, nil
 }
+
+// profileConfigPath returns the section and key under which the profile overrides the value of given key
+func profileConfigPath(cfg config.Config, profile, section, key string) (string, string, error) {
+	if !slices.Contains(cfg.Profiles(), profile) {
+		return "", "", fmt.Errorf("%w: %s, create it with 'akamai profile create %s'", config.ErrUnknownProfile, profile, profile)
+	}
+	return config.ProfileSection(profile), section + "." + key, nil
+}
+
+func cmdConfigMigrate(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("CONFIG MIGRATE START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("CONFIG MIGRATE FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("CONFIG MIGRATE ERROR: %v", e))
+		}
+	}()
+	cfg := config.Get(c.Context)
+	term := terminal.Get(c.Context)
+
+	dryRun := c.Bool("dry-run")
+	result, err := cfg.Migrate(c.Context, dryRun)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error migrating config: %v", err))
+		return cli.Exit(color.RedString("Unable to migrate config: %v", err), 1)
+	}
+	if isStructuredOutput(c) {
+		return writeOutput(term, outputFormat(c), result)
+	}
+
+	if len(result.Migrations) == 0 {
+		term.Printf("Config is up to date (version %s).\n", result.Version)
+		return nil
+	}
+
+	if dryRun {
+		term.Printf("Would migrate config to version %s:\n", result.Version)
+	} else {
+		term.Printf("Migrated config to version %s:\n", result.Version)
+	}
+	for _, m := range result.Migrations {
+		from := m.From
+		if from == "" {
+			from = "none"
+		}
+		term.Printf("  %s -> %s: %s\n", from, m.To, m.Description)
+	}
+	printConfigChanges(term, result.Changes)
+	for _, file := range result.RemovedFiles {
+		term.Printf("  - file %s\n", file)
+	}
+
+	if dryRun {
+		term.Printf("Dry run, no changes were made.\n")
+	} else if result.Backup != "" {
+		term.Printf("Previous config saved to %s\n", result.Backup)
+	}
+	return nil
+}
+
+func printConfigChanges(term terminal.Terminal, changes []config.Change) {
+	for _, change := range changes {
+		switch {
+		case change.Old == "":
+			term.Printf("  + %s.%s = %s\n", change.Section, change.Key, change.New)
+		case change.New == "":
+			term.Printf("  - %s.%s = %s\n", change.Section, change.Key, change.Old)
+		default:
+			term.Printf("  ~ %s.%s = %s (was %s)\n", change.Section, change.Key, change.New, change.Old)
+		}
+	}
+}
+
+func cmdConfigExport(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("CONFIG EXPORT START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("CONFIG EXPORT FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("CONFIG EXPORT ERROR: %v", e))
+		}
+	}()
+	cfg := config.Get(c.Context)
+
+	data, err := config.Encode(exportedValues(cfg.UserValues()), c.String("format"))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error encoding config: %v", err))
+		return cli.Exit(color.RedString("Unable to export config: %v", err), 1)
+	}
+	terminal.Get(c.Context).Printf("%s", string(data))
+	return nil
+}
+
+// exportedValues returns the values of the user config file without those which only apply to this machine.
+// Values of the system and project files, or set in the environment, are not exported, as they are not part of the user config.
+func exportedValues(values map[string]map[string]string) map[string]map[string]string {
+	result := make(map[string]map[string]string)
+	for section, keys := range values {
+		for key, value := range keys {
+			if config.IsLocal(section, key) {
+				continue
+			}
+			if result[section] == nil {
+				result[section] = make(map[string]string)
+			}
+			result[section][key] = value
+		}
+	}
+	return result
+}
+
+type configImportResult struct {
+	File    string          `json:"file" yaml:"file"`
+	Changes []config.Change `json:"changes" yaml:"changes"`
+	DryRun  bool            `json:"dry-run" yaml:"dry-run"`
+}
+
+func cmdConfigImport(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("CONFIG IMPORT START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("CONFIG IMPORT FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("CONFIG IMPORT ERROR: %v", e))
+		}
+	}()
+	cfg := config.Get(c.Context)
+	term := terminal.Get(c.Context)
+
+	path := c.Args().First()
+	if path == "" {
+		return cli.Exit(color.RedString("Unable to import config: file to import has to be provided"), 1)
+	}
+	if c.Bool("merge") && c.Bool("replace") {
+		return cli.Exit(color.RedString("Unable to import config: --merge and --replace cannot be used together"), 1)
+	}
+	format := c.String("format")
+	if format == "" {
+		format = config.FormatFromPath(path)
+	}
+
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error reading config file: %v", err))
+		return cli.Exit(color.RedString("Unable to import config: %v", err), 1)
+	}
+	imported, err := config.Decode(data, format)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error decoding config file: %v", err))
+		return cli.Exit(color.RedString("Unable to import config: %v", err), 1)
+	}
+
+	// the import only writes the user config file, so it is compared with the values of that file
+	before := cfg.UserValues()
+	after := importedValues(before, imported, c.Bool("replace"))
+	changes := config.DiffValues(before, after)
+	var invalid []string
+	for _, change := range changes {
+		if _, ok := after[change.Section][change.Key]; !ok {
+			continue
+		}
+		if err := config.Validate(change.Section, change.Key, change.New); err != nil {
+			invalid = append(invalid, err.Error())
+		}
+	}
+	if len(invalid) > 0 {
+		logger.Error(fmt.Sprintf("Invalid config values: %v", invalid))
+		return cli.Exit(color.RedString("Unable to import config:\n  %s", strings.Join(invalid, "\n  ")), 1)
+	}
+	for _, change := range changes {
+		if _, ok := after[change.Section][change.Key]; !ok {
+			continue
+		}
+		if origin, ok := cfg.Origin(change.Section, change.Key); ok && origin.Scope != config.ScopeUser && origin.Scope != config.ScopeSystem {
+			term.WriteError(color.YellowString("%s.%s is overridden by %s, the imported value is not used while it is set there", change.Section, change.Key, configOriginName(origin)))
+		}
+	}
+
+	dryRun := c.Bool("dry-run")
+	switch {
+	case isStructuredOutput(c):
+		if err := writeOutput(term, outputFormat(c), configImportResult{File: path, Changes: changes, DryRun: dryRun}); err != nil {
+			return err
+		}
+	case len(changes) == 0:
+		term.Printf("Config already matches %s.\n", path)
+	default:
+		term.Printf("Changes from %s:\n", path)
+		printConfigChanges(term, changes)
+		if dryRun {
+			term.Printf("Dry run, no changes were made.\n")
+		}
+	}
+	if dryRun || len(changes) == 0 {
+		return nil
+	}
+
+	for _, change := range changes {
+		if _, ok := after[change.Section][change.Key]; ok {
+			cfg.SetValue(change.Section, change.Key, change.New)
+		} else {
+			cfg.UnsetValue(change.Section, change.Key)
+		}
+	}
+	if err := cfg.Save(c.Context); err != nil {
+		logger.Error(fmt.Sprintf("Error saving config: %v", err))
+		return cli.Exit(color.RedString("Unable to import config: %v", err), 1)
+	}
+	return nil
+}
+
+// configOriginName describes where a value comes from, such as the project file or an environment variable
+func configOriginName(origin config.Origin) string {
+	if origin.Scope == config.ScopeEnv {
+		return "environment variable " + origin.Path
+	}
+	return fmt.Sprintf("the %s config %s", origin.Scope, origin.Path)
+}
+
+// importedValues returns the config values after importing. Imported values are merged into the current ones,
+// or replace them, in which case only read-only values, such as the config version, are kept.
+// Local values, such as the cache path, are kept as well and not imported, as they only apply to the machine they are set on.
+func importedValues(current, imported map[string]map[string]string, replace bool) map[string]map[string]string {
+	result := make(map[string]map[string]string)
+	set := func(section, key, value string) {
+		if result[section] == nil {
+			result[section] = make(map[string]string)
+		}
+		result[section][key] = value
+	}
+	for section, keys := range current {
+		for key, value := range keys {
+			if known, ok := config.LookupKey(section, key); replace && (!ok || !known.ReadOnly && !known.Local) {
+				continue
+			}
+			set(section, key, value)
+		}
+	}
+	for section, keys := range imported {
+		for key, value := range keys {
+			if known, ok := config.LookupKey(section, key); ok && (known.ReadOnly || known.Local) {
+				continue
+			}
+			set(section, key, value)
+		}
+	}
+	return result
+}
@@ -3,11 +3,15 @@ package commands
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/akamai/cli/v2/pkg/color"
 	"github.com/akamai/cli/v2/pkg/config"
 	"github.com/akamai/cli/v2/pkg/terminal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
 )
@@ -19,25 +23,95 @@ func TestCmdConfigSet(t *testing.T) {
 		withError string
 	}{
 		"set config no error": {
-			args: []string{"cli.testKey", "testValue"},
+			args: []string{"test.testKey", "testValue"},
 			init: func(m *config.Mock) {
-				m.On("SetValue", "cli", "testKey", "testValue").Return().Once()
+				m.On("SetValue", "test", "testKey", "testValue").Return().Once()
 				m.On("Save").Return(nil).Once()
 			},
 		},
 		"key format error": {
-			args:      []string{"cli", "testKey", "testValue"},
+			args:      []string{"test", "testKey", "testValue"},
 			init:      func(_ *config.Mock) {},
 			withError: "Unable to set config value: section key has to be provided in <section>.<key> format",
 		},
 		"error on save": {
-			args: []string{"cli.testKey", "testValue"},
+			args: []string{"test.testKey", "testValue"},
 			init: func(m *config.Mock) {
-				m.On("SetValue", "cli", "testKey", "testValue").Return().Once()
+				m.On("SetValue", "test", "testKey", "testValue").Return().Once()
 				m.On("Save").Return(fmt.Errorf("save error")).Once()
 			},
 			withError: "save error",
 		},
+		"set config in project scope": {
+			args: []string{"--scope", "project", "test.testKey", "testValue"},
+			init: func(m *config.Mock) {
+				m.On("SetScopedValue", config.ScopeProject, "test", "testKey", "testValue").Return(nil).Once()
+				m.On("Save").Return(nil).Once()
+			},
+		},
+		"set config in env scope": {
+			args: []string{"--scope", "env", "test.testKey", "testValue"},
+			init: func(m *config.Mock) {
+				m.On("SetScopedValue", config.ScopeEnv, "test", "testKey", "testValue").Return(fmt.Errorf("environment overrides cannot be modified")).Once()
+			},
+			withError: "Unable to set config value: environment overrides cannot be modified",
+		},
+		"set known key": {
+			args: []string{"cli.lock-timeout", "30s"},
+			init: func(m *config.Mock) {
+				m.On("SetValue", "cli", "lock-timeout", "30s").Return().Once()
+				m.On("Save").Return(nil).Once()
+			},
+		},
+		"typo in known section": {
+			args:      []string{"cli.last-upgrade-chek", "never"},
+			init:      func(_ *config.Mock) {},
+			withError: "Unable to set config value: unknown config key cli.last-upgrade-chek, did you mean cli.last-upgrade-check?",
+		},
+		"invalid timestamp": {
+			args:      []string{"cli.last-upgrade-check", "yesterday"},
+			init:      func(_ *config.Mock) {},
+			withError: `Unable to set config value: invalid config value for cli.last-upgrade-check: "yesterday", expected an RFC 3339 timestamp`,
+		},
+		"read-only key": {
+			args:      []string{"cli.config-version", "2"},
+			init:      func(_ *config.Mock) {},
+			withError: "Unable to set config value: cli.config-version is managed by Akamai CLI and cannot be set",
+		},
+		"set package config": {
+			args: []string{"--package", "property-manager", "default.contract", "ctr_1"},
+			init: func(m *config.Mock) {
+				m.On("SetValue", "package.property-manager", "default-contract", "ctr_1").Return().Once()
+				m.On("Save").Return(nil).Once()
+			},
+		},
+		"set package config in project scope": {
+			args: []string{"--package", "purge", "--scope", "project", "network", "staging"},
+			init: func(m *config.Mock) {
+				m.On("SetScopedValue", config.ScopeProject, "package.purge", "network", "staging").Return(nil).Once()
+				m.On("Save").Return(nil).Once()
+			},
+		},
+		"set config in profile": {
+			args: []string{"--profile", "prod", "edgerc.section", "production"},
+			init: func(m *config.Mock) {
+				m.On("Profiles").Return([]string{"prod"}).Once()
+				m.On("SetValue", "profile prod", "edgerc.section", "production").Return().Once()
+				m.On("Save").Return(nil).Once()
+			},
+		},
+		"set config in unknown profile": {
+			args: []string{"--profile", "dev", "edgerc.section", "dev"},
+			init: func(m *config.Mock) {
+				m.On("Profiles").Return([]string{"prod"}).Once()
+			},
+			withError: "Unable to set config value: unknown profile: dev, create it with 'akamai profile create dev'",
+		},
+		"set package config without key": {
+			args:      []string{"--package", "purge"},
+			init:      func(_ *config.Mock) {},
+			withError: "Unable to set config value: key of the package setting has to be provided",
+		},
 	}
 
 	for name, test := range tests {
@@ -49,6 +123,7 @@ func TestCmdConfigSet(t *testing.T) {
 					{
 						Name:   "set",
 						Action: cmdConfigSet,
+						Flags:  []cli.Flag{&cli.StringFlag{Name: "scope"}, &cli.StringFlag{Name: "package"}, &cli.StringFlag{Name: "profile"}},
 					},
 				},
 			}
@@ -85,6 +160,20 @@ func TestCmdConfigGet(t *testing.T) {
 				m.term.On("Writeln", []interface{}{"test val"}).Return(0, nil).Once()
 			},
 		},
+		"default of unset key": {
+			args: []string{"cli.lock-timeout"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "cli", "lock-timeout").Return("", false).Once()
+				m.term.On("Writeln", []interface{}{"5m"}).Return(0, nil).Once()
+			},
+		},
+		"key without default": {
+			args: []string{"test.testKey"},
+			init: func(m *mocked) {
+				m.cfg.On("GetValue", "test", "testKey").Return("", false).Once()
+				m.term.On("Writeln", []interface{}{""}).Return(0, nil).Once()
+			},
+		},
 		"key format error": {
 			args:      []string{"cli"},
 			init:      func(_ *mocked) {},
@@ -185,9 +274,10 @@ func TestCmdConfigUnset(t *testing.T) {
 
 func TestCmdConfigList(t *testing.T) {
 	tests := map[string]struct {
-		args      []string
-		init      func(*mocked)
-		withError string
+		args       []string
+		globalArgs []string
+		init       func(*mocked)
+		withError  string
 	}{
 		"list full config": {
 			args: []string{},
@@ -220,6 +310,79 @@ func TestCmdConfigList(t *testing.T) {
 				}).Once()
 			},
 		},
+		"list full config as json": {
+			globalArgs: []string{"--output", "json"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(map[string]map[string]string{
+					"cli":  {"key1": "val1", "key2": "val2"},
+					"test": {"key3": "val3"},
+				}).Once()
+				m.term.On("Printf", "%s\n", []interface{}{`{
+  "cli": {
+    "key1": "val1",
+    "key2": "val2"
+  },
+  "test": {
+    "key3": "val3"
+  }
+}`}).Return().Once()
+			},
+		},
+		"list specific section as yaml": {
+			args:       []string{"test"},
+			globalArgs: []string{"--output", "yaml"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(map[string]map[string]string{
+					"cli":  {"key1": "val1", "key2": "val2"},
+					"test": {"key3": "val3"},
+				}).Once()
+				m.term.On("Printf", "%s\n", []interface{}{"test:\n  key3: val3"}).Return().Once()
+			},
+		},
+		"list with origin": {
+			args: []string{"--show-origin"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(map[string]map[string]string{
+					"cli":  {"key1": "val1"},
+					"test": {"key3": "val3"},
+				}).Once()
+				m.cfg.On("Origin", "cli", "key1").Return(config.Origin{Scope: config.ScopeUser, Path: "/home/.akamai-cli/config"}, true).Once()
+				m.cfg.On("Origin", "test", "key3").Return(config.Origin{Scope: config.ScopeEnv, Path: "AKAMAI_TEST_KEY3"}, true).Once()
+				m.term.On("Printf", "%s:%s\t%s.%s = %s\n", []interface{}{"user", "/home/.akamai-cli/config", "cli", "key1", "val1"}).Return().Once()
+				m.term.On("Printf", "%s:%s\t%s.%s = %s\n", []interface{}{"env", "AKAMAI_TEST_KEY3", "test", "key3", "val3"}).Return().Once()
+			},
+		},
+		"list section with origin as json": {
+			args:       []string{"--show-origin", "test"},
+			globalArgs: []string{"--output", "json"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(map[string]map[string]string{
+					"cli":  {"key1": "val1"},
+					"test": {"key3": "val3"},
+				}).Once()
+				m.cfg.On("Origin", "test", "key3").Return(config.Origin{Scope: config.ScopeProject, Path: "/repo/.akamai-cli.ini"}, true).Once()
+				m.term.On("Printf", "%s\n", []interface{}{`{
+  "test": {
+    "key3": {
+      "value": "val3",
+      "scope": "project",
+      "origin": "/repo/.akamai-cli.ini"
+    }
+  }
+}`}).Return().Once()
+			},
+		},
+		"list package config": {
+			args: []string{"--package", "purge"},
+			init: func(m *mocked) {
+				m.cfg.On("Values").Return(map[string]map[string]string{
+					"cli":                      {"key1": "val1"},
+					"package.purge":            {"network": "staging"},
+					"package.property-manager": {"default-contract": "ctr_1"},
+				}).Once()
+				m.term.On("Printf", "%s.%s = %s\n", []interface{}{"package.purge", "network", "staging"}).Return().Once()
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -231,11 +394,13 @@ func TestCmdConfigList(t *testing.T) {
 					{
 						Name:   "list",
 						Action: cmdConfigList,
+						Flags:  []cli.Flag{&cli.BoolFlag{Name: "show-origin"}, &cli.StringFlag{Name: "package"}},
 					},
 				},
 			}
 			app, ctx := setupTestApp(command, m)
 			args := os.Args[0:1]
+			args = append(args, test.globalArgs...)
 			args = append(args, "config", "list")
 			args = append(args, test.args...)
 
@@ -243,6 +408,441 @@ func TestCmdConfigList(t *testing.T) {
 			err := app.RunContext(ctx, args)
 
 			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCmdConfigMigrate(t *testing.T) {
+	pending := &config.MigrationResult{
+		Version:      "1.1",
+		Migrations:   []config.Migration{{From: "", To: "1", Description: "First step"}, {From: "1", To: "1.1", Description: "Second step"}},
+		Changes:      []config.Change{{Section: "cli", Key: "config-version", New: "1.1"}, {Section: "cli", Key: "old-key", Old: "abc"}, {Section: "cli", Key: "last-upgrade-check", Old: "never", New: "ignore"}},
+		RemovedFiles: []string{"/home/.akamai-cli/.upgrade-check"},
+	}
+
+	tests := map[string]struct {
+		args       []string
+		globalArgs []string
+		init       func(*mocked)
+		withError  string
+	}{
+		"dry run": {
+			args: []string{"--dry-run"},
+			init: func(m *mocked) {
+				m.cfg.On("Migrate", true).Return(pending, nil).Once()
+				m.term.On("Printf", "Would migrate config to version %s:\n", []interface{}{"1.1"}).Return().Once()
+				m.term.On("Printf", "  %s -> %s: %s\n", []interface{}{"none", "1", "First step"}).Return().Once()
+				m.term.On("Printf", "  %s -> %s: %s\n", []interface{}{"1", "1.1", "Second step"}).Return().Once()
+				m.term.On("Printf", "  + %s.%s = %s\n", []interface{}{"cli", "config-version", "1.1"}).Return().Once()
+				m.term.On("Printf", "  - %s.%s = %s\n", []interface{}{"cli", "old-key", "abc"}).Return().Once()
+				m.term.On("Printf", "  ~ %s.%s = %s (was %s)\n", []interface{}{"cli", "last-upgrade-check", "ignore", "never"}).Return().Once()
+				m.term.On("Printf", "  - file %s\n", []interface{}{"/home/.akamai-cli/.upgrade-check"}).Return().Once()
+				m.term.On("Printf", "Dry run, no changes were made.\n", []interface{}(nil)).Return().Once()
+			},
+		},
+		"migrate": {
+			init: func(m *mocked) {
+				m.cfg.On("Migrate", false).Return(&config.MigrationResult{
+					Version:    "1.1",
+					Migrations: []config.Migration{{From: "1", To: "1.1", Description: "Second step"}},
+					Changes:    []config.Change{{Section: "cli", Key: "config-version", Old: "1", New: "1.1"}},
+					Backup:     "/home/.akamai-cli/config.20260101120000.bak",
+				}, nil).Once()
+				m.term.On("Printf", "Migrated config to version %s:\n", []interface{}{"1.1"}).Return().Once()
+				m.term.On("Printf", "  %s -> %s: %s\n", []interface{}{"1", "1.1", "Second step"}).Return().Once()
+				m.term.On("Printf", "  ~ %s.%s = %s (was %s)\n", []interface{}{"cli", "config-version", "1.1", "1"}).Return().Once()
+				m.term.On("Printf", "Previous config saved to %s\n", []interface{}{"/home/.akamai-cli/config.20260101120000.bak"}).Return().Once()
+			},
+		},
+		"up to date": {
+			init: func(m *mocked) {
+				m.cfg.On("Migrate", false).Return(&config.MigrationResult{Version: "1.1"}, nil).Once()
+				m.term.On("Printf", "Config is up to date (version %s).\n", []interface{}{"1.1"}).Return().Once()
+			},
+		},
+		"dry run as json": {
+			args:       []string{"--dry-run"},
+			globalArgs: []string{"--output", "json"},
+			init: func(m *mocked) {
+				m.cfg.On("Migrate", true).Return(&config.MigrationResult{
+					Version:    "1.1",
+					Migrations: []config.Migration{{From: "1", To: "1.1", Description: "Second step"}},
+					Changes:    []config.Change{{Section: "cli", Key: "config-version", Old: "1", New: "1.1"}},
+				}, nil).Once()
+				m.term.On("Printf", "%s\n", []interface{}{`{
+  "version": "1.1",
+  "migrations": [
+    {
+      "from": "1",
+      "to": "1.1",
+      "description": "Second step"
+    }
+  ],
+  "changes": [
+    {
+      "section": "cli",
+      "key": "config-version",
+      "old": "1",
+      "new": "1.1"
+    }
+  ]
+}`}).Return().Once()
+			},
+		},
+		"config from newer cli": {
+			init: func(m *mocked) {
+				m.cfg.On("Migrate", false).Return(nil, fmt.Errorf("%w: config version 2 is newer", config.ErrUnsupportedConfigVersion)).Once()
+			},
+			withError: "Unable to migrate config: unsupported config version: config version 2 is newer",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil, nil}
+			command := &cli.Command{
+				Name: "config",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "migrate",
+						Action: cmdConfigMigrate,
+						Flags:  []cli.Flag{&cli.BoolFlag{Name: "dry-run"}},
+					},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, test.globalArgs...)
+			args = append(args, "config", "migrate")
+			args = append(args, test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCmdConfigDescribe(t *testing.T) {
+	tests := map[string]struct {
+		args       []string
+		globalArgs []string
+		init       func(*mocked)
+		withError  string
+	}{
+		"describe key": {
+			args: []string{"cli.lock-timeout"},
+			init: func(m *mocked) {
+				m.term.On("Printf", "%s\n", []interface{}{color.BlueString("%s.%s", "cli", "lock-timeout")}).Return().Once()
+				m.term.On("Printf", "  Type:        %s\n", []interface{}{config.TypeDuration}).Return().Once()
+				m.term.On("Printf", "  Default:     %s\n", []interface{}{"5m"}).Return().Once()
+				m.term.On("Printf", "  Description: %s\n", []interface{}{"How long to wait for another akamai process to release the lock on the CLI root directory."}).Return().Once()
+			},
+		},
+		"describe key with allowed values": {
+			args: []string{"cli.install-in-path"},
+			init: func(m *mocked) {
+				m.term.On("Printf", "%s\n", []interface{}{color.BlueString("%s.%s", "cli", "install-in-path")}).Return().Once()
+				m.term.On("Printf", "  Type:        %s\n", []interface{}{config.TypeString}).Return().Once()
+				m.term.On("Printf", "  Allowed:     %s\n", []interface{}{`"", "no"`}).Return().Once()
+				m.term.On("Printf", "  Description: %s\n", []interface{}{"Set to 'no' to stop asking whether to install the CLI in a directory on PATH."}).Return().Once()
+			},
+		},
+		"describe section": {
+			args: []string{"cli"},
+			init: func(m *mocked) {
+				m.term.On("Printf", "%s\n", mock.Anything).Return().Times(len(config.Keys()))
+				m.term.On("Printf", "\n", []interface{}(nil)).Return().Times(len(config.Keys()) - 1)
+				m.term.On("Printf", mock.Anything, mock.Anything).Return()
+			},
+		},
+		"describe key as json": {
+			args:       []string{"cli.registries"},
+			globalArgs: []string{"--output", "json"},
+			init: func(m *mocked) {
+				m.term.On("Printf", "%s\n", []interface{}{`[
+  {
+    "section": "cli",
+    "name": "registries",
+    "type": "list",
+    "default": "",
+    "description": "Additional package registries searched before the built-in one, as URLs or local file paths."
+  }
+]`}).Return().Once()
+			},
+		},
+		"unknown key": {
+			args:      []string{"cli.unknown"},
+			init:      func(_ *mocked) {},
+			withError: "Unable to describe config: unknown config key cli.unknown",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil, nil}
+			command := &cli.Command{
+				Name: "config",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "describe",
+						Action: cmdConfigDescribe,
+					},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, test.globalArgs...)
+			args = append(args, "config", "describe")
+			args = append(args, test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCmdConfigExport(t *testing.T) {
+	values := map[string]map[string]string{
+		"cli":          {"lock-timeout": "30s", "cache-path": "/home/user/.akamai-cli/cache"},
+		"edgerc":       {"section": "prod"},
+		"profile prod": {"cli.proxy": "http://proxy:3128"},
+	}
+	tests := map[string]struct {
+		args      []string
+		init      func(*mocked)
+		withError string
+	}{
+		"export ini by default, without local values": {
+			init: func(m *mocked) {
+				m.cfg.On("UserValues").Return(values).Once()
+				m.term.On("Printf", "%s", []interface{}{"[cli]\nlock-timeout = 30s\n\n[edgerc]\nsection = prod\n\n[profile prod]\ncli.proxy = http://proxy:3128\n"}).Return().Once()
+			},
+		},
+		"export env, without profiles": {
+			args: []string{"--format", "env"},
+			init: func(m *mocked) {
+				m.cfg.On("UserValues").Return(values).Once()
+				m.term.On("Printf", "%s", []interface{}{"AKAMAI_CLI_LOCK_TIMEOUT=30s\nAKAMAI_EDGERC_SECTION=prod\n"}).Return().Once()
+			},
+		},
+		"unsupported format": {
+			args: []string{"--format", "xml"},
+			init: func(m *mocked) {
+				m.cfg.On("UserValues").Return(values).Once()
+			},
+			withError: `Unable to export config: unsupported config format "xml", expected one of: ini, json, env`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil, nil}
+			command := &cli.Command{
+				Name: "config",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "export",
+						Action: cmdConfigExport,
+						Flags:  []cli.Flag{&cli.StringFlag{Name: "format", Value: config.FormatINI}},
+					},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "config", "export")
+			args = append(args, test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCmdConfigImport(t *testing.T) {
+	current := map[string]map[string]string{
+		"cli":  {"config-version": "1.1", "lock-timeout": "5m"},
+		"test": {"a": "1"},
+	}
+	tests := map[string]struct {
+		args       []string
+		globalArgs []string
+		fileName   string
+		file       string
+		init       func(*mocked)
+		withError  string
+	}{
+		"merge ini file": {
+			fileName: "laptop.ini",
+			file:     "[cli]\nlock-timeout = 30s\n[test]\nb = 2\n",
+			init: func(m *mocked) {
+				m.cfg.On("UserValues").Return(current).Once()
+				m.term.On("Printf", "Changes from %s:\n", mock.Anything).Return().Once()
+				m.term.On("Printf", "  ~ %s.%s = %s (was %s)\n", []interface{}{"cli", "lock-timeout", "30s", "5m"}).Return().Once()
+				m.term.On("Printf", "  + %s.%s = %s\n", []interface{}{"test", "b", "2"}).Return().Once()
+				m.cfg.On("Origin", "cli", "lock-timeout").Return(config.Origin{Scope: config.ScopeUser}, true).Once()
+				m.cfg.On("Origin", "test", "b").Return(config.Origin{}, false).Once()
+				m.cfg.On("SetValue", "cli", "lock-timeout", "30s").Return().Once()
+				m.cfg.On("SetValue", "test", "b", "2").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+			},
+		},
+		"replace with json file": {
+			args:     []string{"--replace"},
+			fileName: "laptop.json",
+			file:     `{"cli": {"lock-timeout": "5m", "config-version": "0"}}`,
+			init: func(m *mocked) {
+				m.cfg.On("UserValues").Return(current).Once()
+				m.term.On("Printf", "Changes from %s:\n", mock.Anything).Return().Once()
+				m.term.On("Printf", "  - %s.%s = %s\n", []interface{}{"test", "a", "1"}).Return().Once()
+				m.cfg.On("UnsetValue", "test", "a").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+			},
+		},
+		"replace keeps local values and does not import them": {
+			args:     []string{"--replace"},
+			fileName: "laptop.ini",
+			file:     "[cli]\ncache-path = /other/cache\nlock-timeout = 5m\n[test]\na = 1\n",
+			init: func(m *mocked) {
+				m.cfg.On("UserValues").Return(map[string]map[string]string{
+					"cli":  {"cache-path": "/home/user/cache", "lock-timeout": "5m"},
+					"test": {"a": "1"},
+				}).Once()
+				m.term.On("Printf", "Config already matches %s.\n", mock.Anything).Return().Once()
+			},
+		},
+		"value overridden by the environment": {
+			fileName: "laptop.ini",
+			file:     "[test]\na = 2\n",
+			init: func(m *mocked) {
+				m.cfg.On("UserValues").Return(current).Once()
+				m.cfg.On("Origin", "test", "a").Return(config.Origin{Scope: config.ScopeEnv, Path: "AKAMAI_TEST_A"}, true).Once()
+				m.term.On("WriteError", color.YellowString("test.a is overridden by environment variable AKAMAI_TEST_A, the imported value is not used while it is set there")).Return().Once()
+				m.term.On("Printf", "Changes from %s:\n", mock.Anything).Return().Once()
+				m.term.On("Printf", "  ~ %s.%s = %s (was %s)\n", []interface{}{"test", "a", "2", "1"}).Return().Once()
+				m.cfg.On("SetValue", "test", "a", "2").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+			},
+		},
+		"dry run of env file": {
+			args:     []string{"--dry-run"},
+			fileName: "laptop.env",
+			file:     "AKAMAI_TEST_A=10\n",
+			init: func(m *mocked) {
+				m.cfg.On("UserValues").Return(current).Once()
+				m.cfg.On("Origin", "test", "a").Return(config.Origin{Scope: config.ScopeUser}, true).Once()
+				m.term.On("Printf", "Changes from %s:\n", mock.Anything).Return().Once()
+				m.term.On("Printf", "  ~ %s.%s = %s (was %s)\n", []interface{}{"test", "a", "10", "1"}).Return().Once()
+				m.term.On("Printf", "Dry run, no changes were made.\n", []interface{}(nil)).Return().Once()
+			},
+		},
+		"dry run as json": {
+			args:       []string{"--dry-run", "--format", "env"},
+			globalArgs: []string{"--output", "json"},
+			fileName:   "laptop",
+			file:       "AKAMAI_TEST_A=10\n",
+			init: func(m *mocked) {
+				m.cfg.On("UserValues").Return(current).Once()
+				m.cfg.On("Origin", "test", "a").Return(config.Origin{Scope: config.ScopeUser}, true).Once()
+				m.term.On("Printf", "%s\n", mock.MatchedBy(func(args []interface{}) bool {
+					return len(args) == 1 && strings.Contains(args[0].(string), `"dry-run": true`) && strings.Contains(args[0].(string), `"new": "10"`)
+				})).Return().Once()
+			},
+		},
+		"no changes": {
+			fileName: "laptop.ini",
+			file:     "[test]\na = 1\n",
+			init: func(m *mocked) {
+				m.cfg.On("UserValues").Return(current).Once()
+				m.term.On("Printf", "Config already matches %s.\n", mock.Anything).Return().Once()
+			},
+		},
+		"invalid values": {
+			fileName: "laptop.ini",
+			file:     "[cli]\nlock-timeout = soon\nlast-upgrade-chek = never\n",
+			init: func(m *mocked) {
+				m.cfg.On("UserValues").Return(current).Once()
+			},
+			withError: "Unable to import config:\n  unknown config key cli.last-upgrade-chek, did you mean cli.last-upgrade-check?\n  invalid config value for cli.lock-timeout",
+		},
+		"merge and replace": {
+			args:      []string{"--merge", "--replace"},
+			fileName:  "laptop.ini",
+			init:      func(_ *mocked) {},
+			withError: "Unable to import config: --merge and --replace cannot be used together",
+		},
+		"missing file": {
+			init:      func(_ *mocked) {},
+			withError: "Unable to import config: file to import has to be provided",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil, nil}
+			command := &cli.Command{
+				Name: "config",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "import",
+						Action: cmdConfigImport,
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "format"},
+							&cli.BoolFlag{Name: "merge"},
+							&cli.BoolFlag{Name: "replace"},
+							&cli.BoolFlag{Name: "dry-run"},
+						},
+					},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, test.globalArgs...)
+			args = append(args, "config", "import")
+			args = append(args, test.args...)
+			if test.fileName != "" {
+				path := filepath.Join(t.TempDir(), test.fileName)
+				require.NoError(t, os.WriteFile(path, []byte(test.file), 0600))
+				args = append(args, path)
+			}
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
 			if test.withError != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), test.withError)
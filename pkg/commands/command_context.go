@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/color"
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/edgerc"
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli/v2"
+)
+
+type contextInfo struct {
+	config.CredentialContext `yaml:",inline"`
+	Active                   bool `json:"active" yaml:"active"`
+}
+
+func cmdContextList(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("CONTEXT LIST START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("CONTEXT LIST FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("CONTEXT LIST ERROR: %v", e))
+		}
+	}()
+	cfg := config.Get(c.Context)
+	term := terminal.Get(c.Context)
+
+	active, _ := cfg.GetValue("cli", "context")
+	contexts := make([]contextInfo, 0)
+	for _, ctx := range config.CredentialContexts(cfg) {
+		contexts = append(contexts, contextInfo{CredentialContext: ctx, Active: ctx.Name == active})
+	}
+	if isStructuredOutput(c) {
+		return writeOutput(term, outputFormat(c), contexts)
+	}
+
+	if len(contexts) == 0 {
+		term.Printf("No contexts defined, create one with 'akamai context create <name>'.\n")
+		return nil
+	}
+	for _, ctx := range contexts {
+		if ctx.Active {
+			term.Printf("* %s\t%s\n", color.GreenString("%s", ctx.Name), describeContext(ctx.CredentialContext))
+			continue
+		}
+		term.Printf("  %s\t%s\n", ctx.Name, describeContext(ctx.CredentialContext))
+	}
+	return nil
+}
+
+func cmdContextCurrent(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("CONTEXT CURRENT START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("CONTEXT CURRENT FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("CONTEXT CURRENT ERROR: %v", e))
+		}
+	}()
+	term := terminal.Get(c.Context)
+
+	current, ok, err := config.CurrentCredentialContext(config.Get(c.Context))
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to get current context: %v", err), 1)
+	}
+	if !ok {
+		return cli.Exit(color.RedString("No context is used, select one with 'akamai context use <name>'"), 1)
+	}
+	if isStructuredOutput(c) {
+		return writeOutput(term, outputFormat(c), current)
+	}
+	term.Printf("%s\t%s\n", current.Name, describeContext(current))
+	return nil
+}
+
+func cmdContextUse(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("CONTEXT USE START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("CONTEXT USE FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("CONTEXT USE ERROR: %v", e))
+		}
+	}()
+	cfg := config.Get(c.Context)
+
+	name := c.Args().First()
+	if name == "" {
+		return cli.Exit(color.RedString("Unable to use context: context name has to be provided"), 1)
+	}
+	if _, err := config.GetCredentialContext(cfg, name); err != nil {
+		return cli.Exit(color.RedString("Unable to use context: %v", err), 1)
+	}
+	cfg.SetValue("cli", "context", name)
+	if err := cfg.Save(c.Context); err != nil {
+		logger.Error(fmt.Sprintf("Error saving config: %v", err))
+		return cli.Exit(color.RedString("Unable to use context: %v", err), 1)
+	}
+	terminal.Get(c.Context).Printf("Using context %s by default.\n", name)
+	return nil
+}
+
+func cmdContextCreate(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("CONTEXT CREATE START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("CONTEXT CREATE FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("CONTEXT CREATE ERROR: %v", e))
+		}
+	}()
+	cfg := config.Get(c.Context)
+
+	name := c.Args().First()
+	if name == "" {
+		return cli.Exit(color.RedString("Unable to create context: context name has to be provided"), 1)
+	}
+	ctx := config.CredentialContext{
+		Name:       name,
+		Section:    c.String("section"),
+		AccountKey: c.String("account-key"),
+	}
+	if path := c.String("edgerc"); path != "" {
+		var err error
+		if ctx.Edgerc, err = absolutePath(path); err != nil {
+			return cli.Exit(color.RedString("Unable to create context: %v", err), 1)
+		}
+	}
+	if err := checkContextSection(ctx); err != nil {
+		return cli.Exit(color.RedString("Unable to create context: %v", err), 1)
+	}
+	if err := config.CreateCredentialContext(cfg, ctx); err != nil {
+		logger.Error(fmt.Sprintf("Error creating context: %v", err))
+		return cli.Exit(color.RedString("Unable to create context: %v", err), 1)
+	}
+	if err := cfg.Save(c.Context); err != nil {
+		logger.Error(fmt.Sprintf("Error saving config: %v", err))
+		return cli.Exit(color.RedString("Unable to create context: %v", err), 1)
+	}
+	terminal.Get(c.Context).Printf("Created context %s, select it with 'akamai context use %s'.\n", name, name)
+	return nil
+}
+
+func cmdContextDelete(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("CONTEXT DELETE START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("CONTEXT DELETE FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("CONTEXT DELETE ERROR: %v", e))
+		}
+	}()
+	cfg := config.Get(c.Context)
+
+	name := c.Args().First()
+	if name == "" {
+		return cli.Exit(color.RedString("Unable to delete context: context name has to be provided"), 1)
+	}
+	if err := config.DeleteCredentialContext(cfg, name); err != nil {
+		logger.Error(fmt.Sprintf("Error deleting context: %v", err))
+		return cli.Exit(color.RedString("Unable to delete context: %v", err), 1)
+	}
+	if err := cfg.Save(c.Context); err != nil {
+		logger.Error(fmt.Sprintf("Error saving config: %v", err))
+		return cli.Exit(color.RedString("Unable to delete context: %v", err), 1)
+	}
+	terminal.Get(c.Context).Printf("Deleted context %s.\n", name)
+	return nil
+}
+
+// checkContextSection verifies that the section of the context exists in its credentials file,
+// so that a mistyped section does not silently fall back to other credentials
+func checkContextSection(ctx config.CredentialContext) error {
+	if ctx.Section == "" {
+		return nil
+	}
+	path := ctx.Edgerc
+	if path == "" {
+		var err error
+		if path, err = edgerc.DefaultPath(); err != nil {
+			return err
+		}
+	}
+	file, err := edgerc.Load(path)
+	if err != nil {
+		return err
+	}
+	_, err = file.Credentials(ctx.Section)
+	return err
+}
+
+func describeContext(ctx config.CredentialContext) string {
+	var parts []string
+	if ctx.Section != "" {
+		parts = append(parts, fmt.Sprintf("section %s", ctx.Section))
+	}
+	if ctx.Edgerc != "" {
+		parts = append(parts, fmt.Sprintf("edgerc %s", ctx.Edgerc))
+	}
+	if ctx.AccountKey != "" {
+		parts = append(parts, fmt.Sprintf("account key %s", ctx.AccountKey))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func absolutePath(path string) (string, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(path)
+}
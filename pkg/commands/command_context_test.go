@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCmdContext(t *testing.T) {
+	contexts := map[string]map[string]string{
+		"cli":          {"context": "prod"},
+		"context prod": {"section": "default", "account-key": "1-ABCD"},
+		"context test": {"edgerc": "/creds/.edgerc", "section": "test"},
+	}
+	tests := map[string]struct {
+		args       []string
+		globalArgs []string
+		init       func(*mocked, string)
+		withError  string
+	}{
+		"list contexts": {
+			args: []string{"list"},
+			init: func(m *mocked, _ string) {
+				m.cfg.On("GetValue", "cli", "context").Return("prod", true).Once()
+				m.cfg.On("Values").Return(contexts).Once()
+				m.term.On("Printf", "* %s\t%s\n", []interface{}{"prod", "section default, account key 1-ABCD"}).Return().Once()
+				m.term.On("Printf", "  %s\t%s\n", []interface{}{"test", "section test, edgerc /creds/.edgerc"}).Return().Once()
+			},
+		},
+		"list contexts as json": {
+			args:       []string{"list"},
+			globalArgs: []string{"--output", "json"},
+			init: func(m *mocked, _ string) {
+				m.cfg.On("GetValue", "cli", "context").Return("", false).Once()
+				m.cfg.On("Values").Return(map[string]map[string]string{"context prod": contexts["context prod"]}).Once()
+				m.term.On("Printf", "%s\n", []interface{}{`[
+  {
+    "name": "prod",
+    "section": "default",
+    "account-key": "1-ABCD",
+    "active": false
+  }
+]`}).Return().Once()
+			},
+		},
+		"list without contexts": {
+			args: []string{"list"},
+			init: func(m *mocked, _ string) {
+				m.cfg.On("GetValue", "cli", "context").Return("", false).Once()
+				m.cfg.On("Values").Return(map[string]map[string]string{}).Once()
+				m.term.On("Printf", "No contexts defined, create one with 'akamai context create <name>'.\n", []interface{}(nil)).Return().Once()
+			},
+		},
+		"current context": {
+			args: []string{"current"},
+			init: func(m *mocked, _ string) {
+				m.cfg.On("GetValue", "cli", "context").Return("prod", true).Once()
+				m.cfg.On("Values").Return(contexts).Once()
+				m.term.On("Printf", "%s\t%s\n", []interface{}{"prod", "section default, account key 1-ABCD"}).Return().Once()
+			},
+		},
+		"no current context": {
+			args: []string{"current"},
+			init: func(m *mocked, _ string) {
+				m.cfg.On("GetValue", "cli", "context").Return("", false).Once()
+			},
+			withError: "No context is used, select one with 'akamai context use <name>'",
+		},
+		"use context": {
+			args: []string{"use", "test"},
+			init: func(m *mocked, _ string) {
+				m.cfg.On("Values").Return(contexts).Once()
+				m.cfg.On("SetValue", "cli", "context", "test").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+				m.term.On("Printf", "Using context %s by default.\n", []interface{}{"test"}).Return().Once()
+			},
+		},
+		"use unknown context": {
+			args: []string{"use", "dev"},
+			init: func(m *mocked, _ string) {
+				m.cfg.On("Values").Return(contexts).Once()
+			},
+			withError: "Unable to use context: unknown context: dev",
+		},
+		"create context": {
+			args: []string{"create", "--section", "default", "--account-key", "1-DEV", "dev"},
+			init: func(m *mocked, path string) {
+				m.cfg.On("Values").Return(contexts).Once()
+				m.cfg.On("SetValue", "context dev", "edgerc", path).Return().Once()
+				m.cfg.On("SetValue", "context dev", "section", "default").Return().Once()
+				m.cfg.On("SetValue", "context dev", "account-key", "1-DEV").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+				m.term.On("Printf", "Created context %s, select it with 'akamai context use %s'.\n", []interface{}{"dev", "dev"}).Return().Once()
+			},
+		},
+		"create context with unknown section": {
+			args:      []string{"create", "--section", "missing", "dev"},
+			init:      func(_ *mocked, _ string) {},
+			withError: "Unable to create context: section not found: missing",
+		},
+		"create existing context": {
+			args: []string{"create", "--section", "default", "prod"},
+			init: func(m *mocked, _ string) {
+				m.cfg.On("Values").Return(contexts).Once()
+			},
+			withError: "Unable to create context: context already exists: prod",
+		},
+		"create without name": {
+			args:      []string{"create"},
+			init:      func(_ *mocked, _ string) {},
+			withError: "Unable to create context: context name has to be provided",
+		},
+		"delete context": {
+			args: []string{"delete", "prod"},
+			init: func(m *mocked, _ string) {
+				m.cfg.On("Values").Return(contexts).Once()
+				for _, key := range []string{"edgerc", "section", "account-key"} {
+					m.cfg.On("Origin", "context prod", key).Return(config.Origin{Scope: config.ScopeUser}, true).Once()
+					m.cfg.On("UnsetValue", "context prod", key).Return().Once()
+				}
+				m.cfg.On("GetValue", "cli", "context").Return("prod", true).Once()
+				m.cfg.On("UnsetValue", "cli", "context").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+				m.term.On("Printf", "Deleted context %s.\n", []interface{}{"prod"}).Return().Once()
+			},
+		},
+		"delete context defined in project file": {
+			args: []string{"delete", "prod"},
+			init: func(m *mocked, _ string) {
+				m.cfg.On("Values").Return(contexts).Once()
+				m.cfg.On("Origin", "context prod", "edgerc").Return(config.Origin{}, false).Once()
+				m.cfg.On("Origin", "context prod", "section").Return(config.Origin{Scope: config.ScopeProject, Path: "/project/.akamai-cli.ini"}, true).Once()
+			},
+			withError: "Unable to delete context: context prod is defined in /project/.akamai-cli.ini, remove it from that file instead",
+		},
+		"error saving used context": {
+			args: []string{"use", "prod"},
+			init: func(m *mocked, _ string) {
+				m.cfg.On("Values").Return(contexts).Once()
+				m.cfg.On("SetValue", "cli", "context", "prod").Return().Once()
+				m.cfg.On("Save").Return(fmt.Errorf("save error")).Once()
+			},
+			withError: "Unable to use context: save error",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".edgerc")
+			require.NoError(t, os.WriteFile(path, []byte(testEdgerc), 0600))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil, nil}
+			command := &cli.Command{
+				Name: "context",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "create",
+						Action: cmdContextCreate,
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "edgerc"},
+							&cli.StringFlag{Name: "section"},
+							&cli.StringFlag{Name: "account-key"},
+						},
+					},
+					{Name: "current", Action: cmdContextCurrent},
+					{Name: "delete", Action: cmdContextDelete},
+					{Name: "list", Action: cmdContextList},
+					{Name: "use", Action: cmdContextUse},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, test.globalArgs...)
+			args = append(args, "context")
+			if len(test.args) > 0 && test.args[0] == "create" {
+				args = append(args, "create", "--edgerc", path)
+				args = append(args, test.args[1:]...)
+			} else {
+				args = append(args, test.args...)
+			}
+
+			test.init(m, path)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/color"
+	"github.com/akamai/cli/v2/pkg/edgegrid"
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/akamai/cli/v2/pkg/vault"
+	"github.com/akamai/cli/v2/pkg/version"
+	"github.com/urfave/cli/v2"
+)
+
+// cmdCurl sends a request signed with the credentials of the --edgerc file and --section to the API host of the credentials
+func cmdCurl(client *http.Client) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		logger := log.FromContext(c.Context)
+		start := time.Now()
+		logger.Debug("CURL START")
+		defer func() {
+			if e == nil {
+				logger.Debug(fmt.Sprintf("CURL FINISH: %v", time.Since(start)))
+			} else {
+				logger.Error(fmt.Sprintf("CURL ERROR: %v", e))
+			}
+		}()
+		term := terminal.Get(c.Context)
+
+		path := c.Args().First()
+		if path == "" {
+			return cli.Exit(color.RedString("Unable to send request: API path has to be provided, such as /identity-management/v3/user-profile"), 1)
+		}
+		if strings.Contains(path, "://") {
+			return cli.Exit(color.RedString("Unable to send request: provide the API path only, the host is read from the credentials"), 1)
+		}
+		file, err := loadEdgerc(c)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to send request: %v", err), 1)
+		}
+		section := selectedSection(c)
+		creds, err := secretCredentials{file: file, secrets: vault.Get(c.Context)}.Credentials(section)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to send request: %v", err), 1)
+		}
+		signer, err := edgegrid.NewSigner(creds)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to send request: invalid credentials in section %s: %v", section, err), 1)
+		}
+
+		req, err := newCurlRequest(c, creds.Host, path)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to send request: %v", err), 1)
+		}
+		if err := signer.Sign(req); err != nil {
+			return cli.Exit(color.RedString("Unable to send request: %v", err), 1)
+		}
+		logger.Debug(fmt.Sprintf("Sending %s %s signed with section %s of %s", req.Method, req.URL, section, file.Path()))
+		res, err := client.Do(req)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error sending request: %v", err))
+			return cli.Exit(color.RedString("Unable to send request: %v", err), 1)
+		}
+		defer func() {
+			if err := res.Body.Close(); err != nil {
+				logger.Warn(fmt.Sprintf("Error closing response body: %v", err))
+			}
+		}()
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to read response: %v", err), 1)
+		}
+
+		if c.Bool("include") {
+			term.Printf("%s %s\n", res.Proto, res.Status)
+			names := make([]string, 0, len(res.Header))
+			for name := range res.Header {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				for _, value := range res.Header.Values(name) {
+					term.Printf("%s: %s\n", name, value)
+				}
+			}
+			term.Printf("\n")
+		}
+		if len(body) > 0 {
+			term.Printf("%s", formatResponseBody(res.Header.Get("Content-Type"), body))
+		}
+		if res.StatusCode >= http.StatusBadRequest {
+			return cli.Exit(color.RedString("Request failed with status %s", res.Status), 1)
+		}
+		return nil
+	}
+}
+
+// newCurlRequest builds the request to the API host from the --request, --header and --data flags.
+// The account switch key given with --accountkey is added as the accountSwitchKey query parameter.
+func newCurlRequest(c *cli.Context, host, path string) (*http.Request, error) {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	u, err := url.Parse("https://" + strings.TrimSuffix(host, "/") + path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API path %s: %w", path, err)
+	}
+	if accountKey := c.String("accountkey"); accountKey != "" {
+		query := u.Query()
+		if !query.Has("accountSwitchKey") {
+			query.Set("accountSwitchKey", accountKey)
+			u.RawQuery = query.Encode()
+		}
+	}
+
+	var body []byte
+	if c.IsSet("data") {
+		if body, err = readRequestData(c.String("data")); err != nil {
+			return nil, err
+		}
+	}
+	method := strings.ToUpper(c.String("request"))
+	if method == "" {
+		method = http.MethodGet
+		if body != nil {
+			method = http.MethodPost
+		}
+	}
+
+	req, err := http.NewRequestWithContext(c.Context, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Akamai-CLI/"+version.Version)
+	for _, header := range c.StringSlice("header") {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid header %q, expected 'Name: value'", header)
+		}
+		req.Header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// readRequestData returns the body given with --data: @<file> reads a file, @- reads stdin, any other value is sent as is
+func readRequestData(data string) ([]byte, error) {
+	switch {
+	case data == "@-":
+		return io.ReadAll(os.Stdin)
+	case strings.HasPrefix(data, "@"):
+		body, err := os.ReadFile(strings.TrimPrefix(data, "@"))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read request body: %w", err)
+		}
+		return body, nil
+	}
+	return []byte(data), nil
+}
+
+// formatResponseBody indents JSON responses, other responses are returned as is
+func formatResponseBody(contentType string, body []byte) string {
+	if strings.Contains(contentType, "json") {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, bytes.TrimSpace(body), "", "  "); err == nil {
+			return buf.String() + "\n"
+		}
+	}
+	return string(body)
+}
@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCmdCurl(t *testing.T) {
+	tests := map[string]struct {
+		args       []string
+		globalArgs []string
+		handler    func(*testing.T, http.ResponseWriter, *http.Request)
+		init       func(*mocked)
+		withError  string
+	}{
+		"GET with json response": {
+			args: []string{"/identity-management/v3/user-profile"},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodGet, r.Method)
+				assert.Equal(t, "/identity-management/v3/user-profile", r.URL.Path)
+				assert.Regexp(t, `^EG1-HMAC-SHA256 client_token=akab-c113ntt0k3n4qtari252bfxxbsl-yvsdj;access_token=akab-acc35t0k3nodujqunph3w7hzp7-gtm6ij;timestamp=\d{8}T\d\d:\d\d:\d\d\+0000;nonce=[0-9a-f-]{36};signature=\S{44}$`, r.Header.Get("Authorization"))
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"uiUserName":"jdoe","accounts":[1]}`))
+			},
+			init: func(m *mocked) {
+				m.term.On("Printf", "%s", []interface{}{"{\n  \"uiUserName\": \"jdoe\",\n  \"accounts\": [\n    1\n  ]\n}\n"}).Return().Once()
+			},
+		},
+		"POST from file with account switch key": {
+			args:       []string{"-H", "PAPI-Use-Prefixes: true", "-d", "@" + filepath.Join("testdata", "curl_body.json"), "papi/v1/properties?groupId=grp_1"},
+			globalArgs: []string{"--accountkey", "1-ABCD:1-EFGH"},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/papi/v1/properties", r.URL.Path)
+				assert.Equal(t, "1-ABCD:1-EFGH", r.URL.Query().Get("accountSwitchKey"))
+				assert.Equal(t, "grp_1", r.URL.Query().Get("groupId"))
+				assert.Equal(t, "true", r.Header.Get("PAPI-Use-Prefixes"))
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, "{\"propertyName\": \"example.com\"}\n", string(body))
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte("created"))
+			},
+			init: func(m *mocked) {
+				m.term.On("Printf", "%s", []interface{}{"created"}).Return().Once()
+			},
+		},
+		"include response headers": {
+			args: []string{"-X", "delete", "-i", "/ccu/v3/queues/1"},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodDelete, r.Method)
+				w.Header().Set("X-Trace", "abc")
+				w.Header()["Date"] = nil
+				w.WriteHeader(http.StatusNoContent)
+			},
+			init: func(m *mocked) {
+				m.term.On("Printf", "%s %s\n", []interface{}{"HTTP/1.1", "204 No Content"}).Return().Once()
+				m.term.On("Printf", "%s: %s\n", []interface{}{"X-Trace", "abc"}).Return().Once()
+				m.term.On("Printf", "\n", []interface{}(nil)).Return().Once()
+			},
+		},
+		"error response": {
+			args: []string{"/papi/v1/groups"},
+			handler: func(_ *testing.T, w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"title":"Forbidden"}`))
+			},
+			init: func(m *mocked) {
+				m.term.On("Printf", "%s", []interface{}{"{\n  \"title\": \"Forbidden\"\n}\n"}).Return().Once()
+			},
+			withError: "Request failed with status 403 Forbidden",
+		},
+		"missing path": {
+			init:      func(_ *mocked) {},
+			withError: "Unable to send request: API path has to be provided",
+		},
+		"full URL": {
+			args:      []string{"https://example.com/papi/v1/groups"},
+			init:      func(_ *mocked) {},
+			withError: "Unable to send request: provide the API path only",
+		},
+		"unknown section": {
+			args:       []string{"/papi/v1/groups"},
+			globalArgs: []string{"--section", "missing"},
+			init:       func(_ *mocked) {},
+			withError:  "Unable to send request: section not found: missing",
+		},
+		"invalid header": {
+			args:      []string{"-H", "no-colon", "/papi/v1/groups"},
+			init:      func(_ *mocked) {},
+			withError: `Unable to send request: invalid header "no-colon", expected 'Name: value'`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if test.handler == nil {
+					t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+					return
+				}
+				test.handler(t, w, r)
+			}))
+			defer srv.Close()
+			path := filepath.Join(t.TempDir(), ".edgerc")
+			creds := strings.Replace(testEdgerc, "akab-h05tnam3wl42son7-nktm4ycinn3urmz4.luna.akamaiapis.net", srv.Listener.Addr().String(), 1)
+			require.NoError(t, os.WriteFile(path, []byte(creds), 0600))
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil, nil}
+			command := &cli.Command{
+				Name:   "curl",
+				Action: cmdCurl(srv.Client()),
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "request", Aliases: []string{"X"}},
+					&cli.StringSliceFlag{Name: "header", Aliases: []string{"H"}},
+					&cli.StringFlag{Name: "data", Aliases: []string{"d"}},
+					&cli.BoolFlag{Name: "include", Aliases: []string{"i"}},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			app.Flags = append(app.Flags, &cli.StringFlag{Name: "accountkey"})
+			args := os.Args[0:1]
+			args = append(args, "--edgerc", path)
+			args = append(args, test.globalArgs...)
+			args = append(args, "curl")
+			args = append(args, test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err, fmt.Sprintf("%v", err))
+		})
+	}
+}
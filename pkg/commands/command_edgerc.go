@@ -0,0 +1,335 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/color"
+	"github.com/akamai/cli/v2/pkg/edgerc"
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/akamai/cli/v2/pkg/vault"
+	"github.com/urfave/cli/v2"
+)
+
+type (
+	edgercSection struct {
+		Section string `json:"section" yaml:"section"`
+		Host    string `json:"host" yaml:"host"`
+		Valid   bool   `json:"valid" yaml:"valid"`
+	}
+
+	edgercCredentials struct {
+		Section            string `json:"section" yaml:"section"`
+		edgerc.Credentials `yaml:",inline"`
+	}
+
+	edgercValidation struct {
+		Section string   `json:"section" yaml:"section"`
+		Valid   bool     `json:"valid" yaml:"valid"`
+		Errors  []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+	}
+)
+
+func cmdEdgercList(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("EDGERC LIST START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("EDGERC LIST FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("EDGERC LIST ERROR: %v", e))
+		}
+	}()
+	term := terminal.Get(c.Context)
+
+	file, err := loadEdgerc(c)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to list credentials: %v", err), 1)
+	}
+	sections := make([]edgercSection, 0)
+	for _, name := range file.Sections() {
+		creds, err := file.Credentials(name)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to list credentials: %v", err), 1)
+		}
+		sections = append(sections, edgercSection{Section: name, Host: creds.Host, Valid: len(creds.Validate()) == 0})
+	}
+	if isStructuredOutput(c) {
+		return writeOutput(term, outputFormat(c), sections)
+	}
+
+	if len(sections) == 0 {
+		term.Printf("No credentials found in %s.\n", file.Path())
+		return nil
+	}
+	for _, s := range sections {
+		if !s.Valid {
+			term.Printf("%s\t%s\t%s\n", s.Section, s.Host, color.YellowString("invalid, run 'akamai edgerc validate %s'", s.Section))
+			continue
+		}
+		term.Printf("%s\t%s\n", s.Section, s.Host)
+	}
+	return nil
+}
+
+func cmdEdgercShow(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("EDGERC SHOW START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("EDGERC SHOW FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("EDGERC SHOW ERROR: %v", e))
+		}
+	}()
+	term := terminal.Get(c.Context)
+
+	file, err := loadEdgerc(c)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to show credentials: %v", err), 1)
+	}
+	section := edgercSectionName(c)
+	creds, err := file.Credentials(section)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to show credentials: %v", err), 1)
+	}
+	creds = creds.Redacted()
+	if isStructuredOutput(c) {
+		return writeOutput(term, outputFormat(c), edgercCredentials{Section: section, Credentials: creds})
+	}
+
+	term.Printf("%s\n", color.BlueString("[%s]", section))
+	for _, kv := range [][2]string{
+		{"host", creds.Host},
+		{"client_token", creds.ClientToken},
+		{"client_secret", creds.ClientSecret},
+		{"access_token", creds.AccessToken},
+		{"account_key", creds.AccountKey},
+		{"max_body", creds.MaxBody},
+	} {
+		if kv[1] != "" {
+			term.Printf("  %-13s = %s\n", kv[0], kv[1])
+		}
+	}
+	return nil
+}
+
+func cmdEdgercAdd(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("EDGERC ADD START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("EDGERC ADD FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("EDGERC ADD ERROR: %v", e))
+		}
+	}()
+	term := terminal.Get(c.Context)
+
+	file, err := loadEdgerc(c)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to add credentials: %v", err), 1)
+	}
+	section := edgercSectionName(c)
+	if _, err := file.Credentials(section); err == nil {
+		overwrite, err := term.Confirm(fmt.Sprintf("Section %s already exists in %s, replace it", section, file.Path()), false)
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			return cli.Exit(color.RedString("Unable to add credentials: section %s already exists", section), 1)
+		}
+	}
+
+	creds := edgerc.Credentials{
+		Host:         c.String("host"),
+		ClientToken:  c.String("client-token"),
+		ClientSecret: c.String("client-secret"),
+		AccessToken:  c.String("access-token"),
+		AccountKey:   c.String("account-key"),
+	}
+	for _, field := range []struct {
+		value  *string
+		prompt string
+	}{
+		{&creds.Host, "Host (akab-xxx.luna.akamaiapis.net)"},
+		{&creds.ClientToken, "Client token"},
+		{&creds.ClientSecret, "Client secret"},
+		{&creds.AccessToken, "Access token"},
+	} {
+		if *field.value != "" {
+			continue
+		}
+		if *field.value, err = term.Prompt(field.prompt); err != nil {
+			return err
+		}
+	}
+
+	if problems := creds.Validate(); len(problems) > 0 {
+		logger.Error(fmt.Sprintf("Invalid credentials: %v", errors.Join(problems...)))
+		return cli.Exit(color.RedString("Unable to add credentials: %s", formatEdgercProblems(problems)), 1)
+	}
+	file.SetCredentials(section, creds)
+	if err := file.Save(); err != nil {
+		logger.Error(fmt.Sprintf("Error saving credentials file: %v", err))
+		return cli.Exit(color.RedString("Unable to add credentials: %v", err), 1)
+	}
+	term.Printf("Added section %s to %s.\n", section, file.Path())
+	return nil
+}
+
+func cmdEdgercRemove(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("EDGERC REMOVE START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("EDGERC REMOVE FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("EDGERC REMOVE ERROR: %v", e))
+		}
+	}()
+
+	file, err := loadEdgerc(c)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to remove credentials: %v", err), 1)
+	}
+	section := edgercSectionName(c)
+	if err := file.RemoveSection(section); err != nil {
+		return cli.Exit(color.RedString("Unable to remove credentials: %v", err), 1)
+	}
+	if err := file.Save(); err != nil {
+		logger.Error(fmt.Sprintf("Error saving credentials file: %v", err))
+		return cli.Exit(color.RedString("Unable to remove credentials: %v", err), 1)
+	}
+	terminal.Get(c.Context).Printf("Removed section %s from %s.\n", section, file.Path())
+	return nil
+}
+
+func cmdEdgercValidate(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("EDGERC VALIDATE START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("EDGERC VALIDATE FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("EDGERC VALIDATE ERROR: %v", e))
+		}
+	}()
+	term := terminal.Get(c.Context)
+
+	file, err := loadEdgerc(c)
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to validate credentials: %v", err), 1)
+	}
+	sections := file.Sections()
+	if c.NArg() > 0 {
+		sections = []string{c.Args().First()}
+	}
+	if len(sections) == 0 {
+		return cli.Exit(color.RedString("Unable to validate credentials: no credentials found in %s", file.Path()), 1)
+	}
+
+	results := make([]edgercValidation, 0, len(sections))
+	invalid := 0
+	for _, section := range sections {
+		creds, err := file.Credentials(section)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to validate credentials: %v", err), 1)
+		}
+		result := edgercValidation{Section: section, Valid: true}
+		for _, problem := range creds.Validate() {
+			result.Valid = false
+			result.Errors = append(result.Errors, problem.Error())
+		}
+		if !result.Valid {
+			invalid++
+		}
+		results = append(results, result)
+	}
+
+	if isStructuredOutput(c) {
+		if err := writeOutput(term, outputFormat(c), results); err != nil {
+			return err
+		}
+	} else {
+		for _, result := range results {
+			if result.Valid {
+				term.Printf("%s: %s\n", result.Section, color.GreenString("OK"))
+				continue
+			}
+			term.Printf("%s: %s\n", result.Section, color.RedString("invalid"))
+			for _, problem := range result.Errors {
+				term.Printf("  - %s\n", problem)
+			}
+		}
+	}
+	if invalid > 0 {
+		return cli.Exit(color.RedString("Invalid credentials in %d of %d section(s) of %s", invalid, len(results), file.Path()), 1)
+	}
+	return nil
+}
+
+// loadEdgerc reads the credentials file given with the --edgerc flag, or the one in the home directory
+func loadEdgerc(c *cli.Context) (*edgerc.File, error) {
+	path := c.String("edgerc")
+	if path == "" {
+		var err error
+		if path, err = edgerc.DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+	return edgerc.Load(path)
+}
+
+// secretCredentials reads the credentials of the file, replacing values which reference a secret with the secret of the vault
+type secretCredentials struct {
+	file    *edgerc.File
+	secrets *vault.Session
+}
+
+func (s secretCredentials) Credentials(section string) (edgerc.Credentials, error) {
+	creds, err := s.file.Credentials(section)
+	if err != nil {
+		return edgerc.Credentials{}, err
+	}
+	return creds.ResolveSecrets(s.secrets.Resolve)
+}
+
+// edgercSectionName returns the section given as argument, with the --section flag, or the default one
+func edgercSectionName(c *cli.Context) string {
+	if c.NArg() > 0 {
+		return c.Args().First()
+	}
+	return selectedSection(c)
+}
+
+// selectedSection returns the section given with the --section flag, or the default one
+func selectedSection(c *cli.Context) string {
+	if section := c.String("section"); section != "" {
+		return section
+	}
+	return edgerc.DefaultSection
+}
+
+func formatEdgercProblems(problems []error) string {
+	if len(problems) == 1 {
+		return problems[0].Error()
+	}
+	msg := ""
+	for _, problem := range problems {
+		msg += "\n  - " + problem.Error()
+	}
+	return msg
+}
@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/edgerc"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+const testEdgerc = `[default]
+client_secret = C113nt53KR3TN6N90yVuAgICxIRwsObLi0E67/N8eRN=
+host = akab-h05tnam3wl42son7-nktm4ycinn3urmz4.luna.akamaiapis.net
+access_token = akab-acc35t0k3nodujqunph3w7hzp7-gtm6ij
+client_token = akab-c113ntt0k3n4qtari252bfxxbsl-yvsdj
+
+[broken]
+host = https://akab-broken.luna.akamaiapis.net
+client_token = akab-c113ntt0k3n
+`
+
+func TestCmdEdgerc(t *testing.T) {
+	tests := map[string]struct {
+		args       []string
+		globalArgs []string
+		init       func(*mocked, string)
+		withError  string
+		check      func(*testing.T, *edgerc.File)
+	}{
+		"list sections": {
+			args: []string{"list"},
+			init: func(m *mocked, _ string) {
+				m.term.On("Printf", "%s\t%s\t%s\n", []interface{}{"broken", "https://akab-broken.luna.akamaiapis.net", "invalid, run 'akamai edgerc validate broken'"}).Return().Once()
+				m.term.On("Printf", "%s\t%s\n", []interface{}{"default", "akab-h05tnam3wl42son7-nktm4ycinn3urmz4.luna.akamaiapis.net"}).Return().Once()
+			},
+		},
+		"show default section": {
+			args: []string{"show"},
+			init: func(m *mocked, _ string) {
+				m.term.On("Printf", "%s\n", []interface{}{"[default]"}).Return().Once()
+				m.term.On("Printf", "  %-13s = %s\n", []interface{}{"host", "akab-h05tnam3wl42son7-nktm4ycinn3urmz4.luna.akamaiapis.net"}).Return().Once()
+				m.term.On("Printf", "  %-13s = %s\n", []interface{}{"client_token", "********vsdj"}).Return().Once()
+				m.term.On("Printf", "  %-13s = %s\n", []interface{}{"client_secret", "********"}).Return().Once()
+				m.term.On("Printf", "  %-13s = %s\n", []interface{}{"access_token", "********m6ij"}).Return().Once()
+			},
+		},
+		"show section given with --section as json": {
+			args:       []string{"show"},
+			globalArgs: []string{"--section", "broken", "--output", "json"},
+			init: func(m *mocked, _ string) {
+				m.term.On("Printf", "%s\n", []interface{}{`{
+  "section": "broken",
+  "host": "https://akab-broken.luna.akamaiapis.net",
+  "client-token": "********0k3n",
+  "client-secret": "",
+  "access-token": ""
+}`}).Return().Once()
+			},
+		},
+		"show missing section": {
+			args:      []string{"show", "missing"},
+			init:      func(_ *mocked, _ string) {},
+			withError: "Unable to show credentials: section not found: missing",
+		},
+		"add section with prompts": {
+			args: []string{"add", "--host", "akab-new.luna.akamaiapis.net", "--account-key", "1-ABC", "new"},
+			init: func(m *mocked, path string) {
+				m.term.On("Prompt", "Client token", []string(nil)).Return("akab-client", nil).Once()
+				m.term.On("Prompt", "Client secret", []string(nil)).Return("secret", nil).Once()
+				m.term.On("Prompt", "Access token", []string(nil)).Return("akab-access", nil).Once()
+				m.term.On("Printf", "Added section %s to %s.\n", []interface{}{"new", path}).Return().Once()
+			},
+			check: func(t *testing.T, file *edgerc.File) {
+				creds, err := file.Credentials("new")
+				require.NoError(t, err)
+				assert.Equal(t, edgerc.Credentials{Host: "akab-new.luna.akamaiapis.net", ClientToken: "akab-client", ClientSecret: "secret", AccessToken: "akab-access", AccountKey: "1-ABC"}, creds)
+			},
+		},
+		"add invalid credentials": {
+			args:      []string{"add", "--host", "https://example.com", "--client-token", "akab-a", "--client-secret", "s", "--access-token", "b", "new"},
+			init:      func(_ *mocked, _ string) {},
+			withError: "Unable to add credentials: \n  - host \"https://example.com\" should not include the scheme",
+			check: func(t *testing.T, file *edgerc.File) {
+				assert.Equal(t, []string{"broken", "default"}, file.Sections())
+			},
+		},
+		"replacing existing section is declined": {
+			args: []string{"add"},
+			init: func(m *mocked, path string) {
+				m.term.On("Confirm", "Section default already exists in "+path+", replace it", false).Return(false, nil).Once()
+			},
+			withError: "Unable to add credentials: section default already exists",
+		},
+		"remove section": {
+			args: []string{"rm", "broken"},
+			init: func(m *mocked, path string) {
+				m.term.On("Printf", "Removed section %s from %s.\n", []interface{}{"broken", path}).Return().Once()
+			},
+			check: func(t *testing.T, file *edgerc.File) {
+				assert.Equal(t, []string{"default"}, file.Sections())
+			},
+		},
+		"validate valid section": {
+			args: []string{"validate", "default"},
+			init: func(m *mocked, _ string) {
+				m.term.On("Printf", "%s: %s\n", []interface{}{"default", "OK"}).Return().Once()
+			},
+		},
+		"validate all sections": {
+			args: []string{"validate"},
+			init: func(m *mocked, _ string) {
+				m.term.On("Printf", "%s: %s\n", []interface{}{"broken", "invalid"}).Return().Once()
+				m.term.On("Printf", "  - %s\n", []interface{}{"client_secret is required"}).Return().Once()
+				m.term.On("Printf", "  - %s\n", []interface{}{"access_token is required"}).Return().Once()
+				m.term.On("Printf", "  - %s\n", []interface{}{`host "https://akab-broken.luna.akamaiapis.net" should not include the scheme, use the host name only, such as akab-xxx.luna.akamaiapis.net`}).Return().Once()
+				m.term.On("Printf", "%s: %s\n", []interface{}{"default", "OK"}).Return().Once()
+			},
+			withError: "Invalid credentials in 1 of 2 section(s)",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".edgerc")
+			require.NoError(t, os.WriteFile(path, []byte(testEdgerc), 0600))
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil, nil}
+			command := &cli.Command{
+				Name: "edgerc",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "add",
+						Action: cmdEdgercAdd,
+						Flags: []cli.Flag{
+							&cli.StringFlag{Name: "host"},
+							&cli.StringFlag{Name: "client-token"},
+							&cli.StringFlag{Name: "client-secret"},
+							&cli.StringFlag{Name: "access-token"},
+							&cli.StringFlag{Name: "account-key"},
+						},
+					},
+					{Name: "list", Action: cmdEdgercList},
+					{Name: "remove", Aliases: []string{"rm"}, Action: cmdEdgercRemove},
+					{Name: "show", Action: cmdEdgercShow},
+					{Name: "validate", Action: cmdEdgercValidate},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "--edgerc", path)
+			args = append(args, test.globalArgs...)
+			args = append(args, "edgerc")
+			args = append(args, test.args...)
+
+			test.init(m, path)
+			err := app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			if test.check != nil {
+				file, loadErr := edgerc.Load(path)
+				require.NoError(t, loadErr)
+				test.check(t, file)
+			}
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/tools"
+	"github.com/akamai/cli/v2/pkg/version"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	commandIndexFile = "command-index.json"
+	// commandIndexFormat is increased whenever the index or the cli.json fields it holds change, so that older indexes are rebuilt
+	commandIndexFormat = 2
+)
+
+type (
+	// commandIndex holds the cli.json of installed packages, so that they are not read on every run.
+	// It is valid as long as the modification times recorded for the src directory, the package directories
+	// and their cli.json files do not change.
+	commandIndex struct {
+		Format     int                 `json:"format"`
+		CLIVersion string              `json:"cli-version"`
+		SrcPath    string              `json:"src-path"`
+		SrcModTime int64               `json:"src-mtime"`
+		Packages   []commandIndexEntry `json:"packages"`
+	}
+
+	commandIndexEntry struct {
+		Path           string `json:"path"`
+		ModTime        int64  `json:"mtime"`
+		CliJSONModTime int64  `json:"cli-json-mtime"`
+		CliJSONSize    int64  `json:"cli-json-size"`
+		// Package is not set for entries which are not valid packages, so that they are skipped without being read again
+		Package *subcommands `json:"package,omitempty"`
+	}
+)
+
+// installedPackages returns the packages installed in the src directory, read from the command index when it is up to date.
+// An outdated or missing index is rebuilt.
+func installedPackages(ctx context.Context) []subcommands {
+	logger := log.FromContext(ctx)
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil || srcPath == "" {
+		return nil
+	}
+	indexPath := commandIndexPath(ctx)
+	if indexPath == "" {
+		return buildCommandIndex(srcPath).packages()
+	}
+	if index, err := readCommandIndex(indexPath, srcPath); err == nil {
+		logger.Debug(fmt.Sprintf("Using command index %s", indexPath))
+		return index.packages()
+	} else if !os.IsNotExist(err) {
+		logger.Debug(fmt.Sprintf("Rebuilding command index: %v", err))
+	}
+
+	index := buildCommandIndex(srcPath)
+	// the cache directory is created with the config, so that commands which must not change the CLI home,
+	// such as dry runs, do not create it
+	if _, err := os.Stat(filepath.Dir(indexPath)); err != nil {
+		return index.packages()
+	}
+	if err := writeCommandIndex(indexPath, index); err != nil {
+		logger.Warn(fmt.Sprintf("Unable to write command index %s: %v", indexPath, err))
+	}
+	return index.packages()
+}
+
+// rebuildCommandIndex writes the index of the packages currently installed
+func rebuildCommandIndex(ctx context.Context) error {
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return err
+	}
+	indexPath := commandIndexPath(ctx)
+	if indexPath == "" {
+		return nil
+	}
+	return writeCommandIndex(indexPath, buildCommandIndex(srcPath))
+}
+
+// withCommandIndexRebuild rebuilds the command index once the action, which installs, updates or removes packages, is done.
+// The index is rebuilt even if the action fails, as packages may have changed before the failure. Dry runs change nothing.
+func withCommandIndexRebuild(action cli.ActionFunc) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if c.Bool("dry-run") {
+			return action(c)
+		}
+		err := action(c)
+		if indexErr := rebuildCommandIndex(c.Context); indexErr != nil {
+			log.FromContext(c.Context).Warn(fmt.Sprintf("Unable to rebuild command index: %v", indexErr))
+		}
+		return err
+	}
+}
+
+// commandIndexPath returns the path of the index in the cache directory, or an empty string if there is no cache directory
+func commandIndexPath(ctx context.Context) string {
+	cachePath := getCachePath(config.Get(ctx))
+	if cachePath == "" {
+		return ""
+	}
+	return filepath.Join(cachePath, commandIndexFile)
+}
+
+func buildCommandIndex(srcPath string) *commandIndex {
+	index := &commandIndex{Format: commandIndexFormat, CLIVersion: version.Version, SrcPath: srcPath, Packages: make([]commandIndexEntry, 0)}
+	if info, err := os.Stat(srcPath); err == nil {
+		index.SrcModTime = info.ModTime().UnixNano()
+	}
+	for _, dir := range getPackagePaths() {
+		entry := newCommandIndexEntry(dir)
+		if pkg, err := readPackage(dir); err == nil {
+			entry.Package = &pkg
+		}
+		index.Packages = append(index.Packages, entry)
+	}
+	return index
+}
+
+func newCommandIndexEntry(dir string) commandIndexEntry {
+	entry := commandIndexEntry{Path: dir}
+	if info, err := os.Stat(dir); err == nil {
+		entry.ModTime = info.ModTime().UnixNano()
+	}
+	if info, err := os.Stat(filepath.Join(dir, "cli.json")); err == nil {
+		entry.CliJSONModTime = info.ModTime().UnixNano()
+		entry.CliJSONSize = info.Size()
+	}
+	return entry
+}
+
+// readCommandIndex returns the index if it describes the current content of the src directory
+func readCommandIndex(path, srcPath string) (*commandIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var index commandIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid command index: %w", err)
+	}
+	if index.Format != commandIndexFormat || index.CLIVersion != version.Version || index.SrcPath != srcPath {
+		return nil, errors.New("command index was written for another version or location")
+	}
+	info, err := os.Stat(srcPath)
+	if err != nil || info.ModTime().UnixNano() != index.SrcModTime {
+		return nil, errors.New("packages were added or removed")
+	}
+	for _, entry := range index.Packages {
+		entry.Package = nil
+		if newCommandIndexEntry(entry.Path) != entry {
+			return nil, fmt.Errorf("package %s changed", filepath.Base(entry.Path))
+		}
+	}
+	return &index, nil
+}
+
+func writeCommandIndex(path string, index *commandIndex) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return tools.WriteFileAtomic(path, data, 0600)
+}
+
+// packages returns the packages of the index, leaving out entries which are not valid packages
+func (i *commandIndex) packages() []subcommands {
+	pkgs := make([]subcommands, 0, len(i.Packages))
+	for _, entry := range i.Packages {
+		if entry.Package != nil {
+			pkgs = append(pkgs, *entry.Package)
+		}
+	}
+	return pkgs
+}
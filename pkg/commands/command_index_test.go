@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstalledPackages(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("AKAMAI_CLI_HOME", home)
+	srcPath := filepath.Join(home, ".akamai-cli", "src")
+	cachePath := t.TempDir()
+	cfg := &config.Mock{}
+	cfg.On("GetValue", "cli", "cache-path").Return(cachePath, true)
+	ctx := config.Context(context.Background(), cfg)
+	indexPath := filepath.Join(cachePath, commandIndexFile)
+
+	writePackage := func(dir, cmd string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(srcPath, dir), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(srcPath, dir, "cli.json"), []byte(`{"commands": [{"name": "`+cmd+`"}]}`), 0644))
+	}
+	touch := func(path string, at time.Time) {
+		require.NoError(t, os.Chtimes(path, at, at))
+	}
+	commandNames := func(pkgs []subcommands) []string {
+		names := make([]string, 0)
+		for _, pkg := range pkgs {
+			for _, cmd := range pkg.Commands {
+				names = append(names, cmd.Name)
+			}
+		}
+		return names
+	}
+
+	writePackage("cli-alpha", "Alpha")
+	require.NoError(t, os.MkdirAll(filepath.Join(srcPath, "cli-broken"), 0755))
+	assert.Equal(t, []string{"alpha"}, commandNames(installedPackages(ctx)))
+	require.FileExists(t, indexPath)
+
+	// the index is used as long as nothing changes, which is shown by a command only present in the index
+	data, err := os.ReadFile(indexPath)
+	require.NoError(t, err)
+	var index commandIndex
+	require.NoError(t, json.Unmarshal(data, &index))
+	require.Len(t, index.Packages, 2)
+	index.Packages[0].Package.Commands[0].Name = "from-index"
+	require.NoError(t, writeCommandIndex(indexPath, &index))
+	assert.Equal(t, []string{"from-index"}, commandNames(installedPackages(ctx)))
+
+	// an updated cli.json is read again
+	writePackage("cli-alpha", "alpha2")
+	touch(filepath.Join(srcPath, "cli-alpha", "cli.json"), time.Now().Add(time.Minute))
+	assert.Equal(t, []string{"alpha2"}, commandNames(installedPackages(ctx)))
+
+	// a new package is found
+	writePackage("cli-beta", "beta")
+	touch(srcPath, time.Now().Add(2*time.Minute))
+	assert.Equal(t, []string{"alpha2", "beta"}, commandNames(installedPackages(ctx)))
+
+	// a removed package is no longer listed once the index is rebuilt, as install, update and uninstall do
+	require.NoError(t, os.RemoveAll(filepath.Join(srcPath, "cli-beta")))
+	require.NoError(t, rebuildCommandIndex(ctx))
+	touch(srcPath, time.Now().Add(3*time.Minute))
+	require.NoError(t, rebuildCommandIndex(ctx))
+	assert.Equal(t, []string{"alpha2"}, commandNames(installedPackages(ctx)))
+
+	// an index that cannot be read is rebuilt
+	require.NoError(t, os.WriteFile(indexPath, []byte("{"), 0600))
+	assert.Equal(t, []string{"alpha2"}, commandNames(installedPackages(ctx)))
+	_, err = readCommandIndex(indexPath, srcPath)
+	assert.NoError(t, err)
+
+	// the cache directory is not created to write the index
+	missingCache := filepath.Join(t.TempDir(), "cache")
+	missingCfg := &config.Mock{}
+	missingCfg.On("GetValue", "cli", "cache-path").Return(missingCache, true)
+	assert.Equal(t, []string{"alpha2"}, commandNames(installedPackages(config.Context(context.Background(), missingCfg))))
+	assert.NoDirExists(t, missingCache)
+}
@@ -17,6 +17,8 @@ import (
 	"github.com/akamai/cli/v2/pkg/packages"
 	"github.com/akamai/cli/v2/pkg/terminal"
 	"github.com/akamai/cli/v2/pkg/tools"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -48,10 +50,21 @@ func cmdInstall(git git.Repository, langManager packages.LangManager) cli.Action
 				}
 			}
 		}()
+		if c.IsSet("from") {
+			if c.Bool("dry-run") {
+				return planFromLockFile(c, git, c.String("from"))
+			}
+			return installFromLockFile(c, git, langManager, c.String("from"))
+		}
+
 		if !c.Args().Present() {
 			return cli.Exit(color.RedString("You must specify a repository URL"), 1)
 		}
 
+		if c.Bool("dry-run") {
+			return planInstallArgs(c)
+		}
+
 		oldCmds := getCommands(c)
 
 		for _, repo := range c.Args().Slice() {
@@ -61,11 +74,17 @@ func cmdInstall(git git.Repository, langManager packages.LangManager) cli.Action
 				return cli.Exit(color.RedString("Repository URL cannot be empty"), 1)
 			}
 
-			if !strings.Contains(repo, "://") && !strings.HasPrefix(repo, "git@") {
-				repo = tools.Githubize(repo)
+			var subCmd *subcommands
+			var err error
+			if isBundleFile(repo) {
+				subCmd, err = installBundle(c.Context, repo)
+			} else {
+				repo, ref := splitPackageRef(repo)
+				if !strings.Contains(repo, "://") && !strings.HasPrefix(repo, "git@") {
+					repo = tools.Githubize(repo)
+				}
+				subCmd, err = installPackage(c.Context, git, langManager, repo, ref)
 			}
-
-			subCmd, err := installPackage(c.Context, git, langManager, repo)
 			if err != nil {
 				logger.Error(fmt.Sprintf("Error installing package: %v", err))
 				return err
@@ -80,6 +99,37 @@ func cmdInstall(git git.Repository, langManager packages.LangManager) cli.Action
 	}
 }
 
+// planInstallArgs reports how each package given as argument would be installed, without installing anything
+func planInstallArgs(c *cli.Context) error {
+	plans := make([]dryRunPlan, 0, c.Args().Len())
+	for _, arg := range c.Args().Slice() {
+		arg = strings.TrimSpace(arg)
+		if isBundleFile(arg) {
+			plan, err := planBundleInstall(arg)
+			if err != nil {
+				plan.Error = err.Error()
+			}
+			plans = append(plans, plan)
+			continue
+		}
+
+		repo, ref := splitPackageRef(arg)
+		if repo == "" {
+			return cli.Exit(color.RedString("Repository URL cannot be empty"), 1)
+		}
+		if !strings.Contains(repo, "://") && !strings.HasPrefix(repo, "git@") {
+			repo = tools.Githubize(repo)
+		}
+
+		plan, err := planInstall(c.Context, repo, ref)
+		if err != nil {
+			plan.Error = err.Error()
+		}
+		plans = append(plans, plan)
+	}
+	return writeDryRun(c, plans)
+}
+
 func packageListDiff(c *cli.Context, oldcmds []subcommands) {
 	cmds := getCommands(c)
 
@@ -127,9 +177,27 @@ func packageListDiff(c *cli.Context, oldcmds []subcommands) {
 	listInstalledCommands(c, added, removed)
 }
 
-func installPackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, repo string) (*subcommands, error) {
+// splitPackageRef splits a package argument into the repository and the requested ref.
+// A ref is given either as <repo>#<branch, tag or commit> or as <repo>@<version>.
+func splitPackageRef(arg string) (string, string) {
+	if i := strings.LastIndex(arg, "#"); i != -1 {
+		return arg[:i], arg[i+1:]
+	}
+	start := strings.LastIndexAny(arg, "/:") + 1
+	if i := strings.LastIndex(arg[start:], "@"); i != -1 {
+		return arg[:start+i], arg[start+i+1:]
+	}
+	return arg, ""
+}
+
+// installPackage installs a package from the repository. If ref is not empty, the package is installed
+// at the given branch, tag or commit and pinned to it for subsequent updates.
+func installPackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, repo, ref string) (_ *subcommands, e error) {
 	logger := log.FromContext(ctx)
 	logger.Debug(fmt.Sprintf("Installing package from repository: %s", repo))
+	if ref != "" {
+		logger.Debug(fmt.Sprintf("Requested ref: %s", ref))
+	}
 
 	repo = strings.TrimSpace(repo)
 	if repo == "" {
@@ -162,6 +230,22 @@ func installPackage(ctx context.Context, gitRepo git.Repository, langManager pac
 		return nil, cli.Exit(color.YellowString("%s", warningMsg), 0)
 	}
 
+	if err := os.MkdirAll(srcPath, 0700); err != nil {
+		logger.Error(fmt.Sprintf("Unable to create directory %s: %v", srcPath, err))
+		return nil, err
+	}
+	unmark, err := markInstalling(packageDir)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Unable to mark package as being installed: %v", err))
+		return nil, err
+	}
+	defer func() {
+		// a failed install which could not clean up is removed on the next run
+		if _, err := os.Stat(packageDir); e == nil || os.IsNotExist(err) {
+			unmark()
+		}
+	}()
+
 	term := terminal.Get(ctx)
 	spin := term.Spinner()
 
@@ -173,13 +257,7 @@ func installPackage(ctx context.Context, gitRepo git.Repository, langManager pac
 
 	if strings.HasPrefix(repo, "https://github.com/") {
 		var fetchErr error
-		for _, branch := range []string{"main", "master"} {
-			url := buildRawGitHubURL(owner, repoName, branch)
-			cmdPackage, fetchErr = readPackageFromGithub(url, dirName)
-			if fetchErr == nil {
-				break
-			}
-		}
+		cmdPackage, fetchErr = fetchPackageConfig(owner, repoName, dirName, refCandidates(ref))
 		if fetchErr != nil {
 			spin.Stop(terminal.SpinnerStatusFail)
 			logger.Error(fmt.Sprintf("Failed to read package from github: %v", fetchErr))
@@ -211,15 +289,34 @@ For a list of supported packages, visit:
 	}
 
 	if strings.HasPrefix(repo, "https://github.com/") && isBinary(cmdPackage) {
+		origin := packageOrigin{Repository: repo}
+		// the cli.json of the resolved commit is used, so that the commit recorded for lock files matches the installed binaries
+		if commit, err := resolveGitHubCommit(owner, repoName, refCandidates(ref)); err != nil {
+			logger.Warn(fmt.Sprintf("Unable to resolve the commit of package %s, it cannot be locked to a commit: %v", repoName, err))
+		} else if pkgAtCommit, err := fetchPackageConfig(owner, repoName, dirName, []string{commit}); err != nil {
+			logger.Warn(fmt.Sprintf("Unable to read package %s at commit %s, it cannot be locked to a commit: %v", repoName, commit, err))
+		} else if isBinary(pkgAtCommit) {
+			cmdPackage, origin.Commit = pkgAtCommit, commit
+		}
+
 		logger.Debug(fmt.Sprintf("Installing binaries for package in directory: %s", packageDir))
 		ok, subCmd := installPackageBinaries(ctx, packageDir, cmdPackage, logger)
 		if ok {
-			return subCmd, nil
+			if err := writePackageOrigin(packageDir, origin); err != nil {
+				logger.Error(fmt.Sprintf("Unable to record package origin: %v", err))
+				return nil, err
+			}
+			return subCmd, pinPackage(ctx, packageDir, ref)
 		}
 		if err := os.RemoveAll(packageDir); err != nil {
 			logger.Error(fmt.Sprintf("Failed to remove package directory: %v", err))
 			return nil, err
 		}
+		// building from source would bypass the verification the package asks for
+		if hasBinaryVerification(cmdPackage) {
+			logger.Error("Unable to install verified binaries, not falling back to the repository")
+			return nil, cli.Exit(color.RedString("Unable to install selected package"), 1)
+		}
 		logger.Debug(fmt.Sprintf("Unable to install binaries, cloning repository: %s", repo))
 	}
 
@@ -240,6 +337,18 @@ For a list of supported packages, visit:
 		logger.Error(cases.Title(language.Und, cases.NoLower).String(err.Error()))
 		return nil, cli.Exit(color.RedString("%s", tools.CapitalizeFirstWord(err.Error())), 1)
 	}
+
+	if ref != "" {
+		if err := checkoutRef(gitRepo, ref); err != nil {
+			spin.Stop(terminal.SpinnerStatusFail)
+			if err := os.RemoveAll(packageDir); err != nil {
+				logger.Error(fmt.Sprintf("Failed to remove package directory: %v", err))
+				return nil, err
+			}
+			logger.Error(err.Error())
+			return nil, cli.Exit(color.RedString("%s", tools.CapitalizeFirstWord(err.Error())), 1)
+		}
+	}
 	spin.OK()
 
 	logger.Debug(fmt.Sprintf("Installing dependencies for package in directory: %s", packageDir))
@@ -255,7 +364,44 @@ For a list of supported packages, visit:
 	}
 	logger.Debug(fmt.Sprintf("Dependencies installed successfully for package in directory: %s", packageDir))
 
-	return subCmd, nil
+	return subCmd, pinPackage(ctx, packageDir, ref)
+}
+
+// checkoutRef checks out the commit the ref resolves to in an already opened repository
+func checkoutRef(gitRepo git.Repository, ref string) error {
+	hash, err := resolveRef(gitRepo, ref)
+	if err != nil {
+		return err
+	}
+	return gitRepo.Checkout(&gogit.CheckoutOptions{Hash: hash, Force: true})
+}
+
+// resolveRef returns the commit a ref resolves to in an already opened repository.
+// An empty ref resolves to the default branch.
+func resolveRef(gitRepo git.Repository, ref string) (plumbing.Hash, error) {
+	var hash plumbing.Hash
+	var err error
+	for _, candidate := range refCandidates(ref) {
+		if hash, err = gitRepo.ResolveRevision(candidate); err == nil {
+			return hash, nil
+		}
+	}
+	if ref == "" {
+		return plumbing.ZeroHash, fmt.Errorf("unable to find the default branch in the package repository: %w", err)
+	}
+	return plumbing.ZeroHash, fmt.Errorf("unable to find %q in the package repository: %w", ref, err)
+}
+
+// pinPackage records the requested ref in the package directory, so that updates respect it
+func pinPackage(ctx context.Context, packageDir, ref string) error {
+	if ref == "" {
+		return nil
+	}
+	if err := writePackagePin(packageDir, ref); err != nil {
+		log.FromContext(ctx).Error(fmt.Sprintf("Unable to pin package to %s: %v", ref, err))
+		return err
+	}
+	return nil
 }
 
 func installPackageDependencies(ctx context.Context, langManager packages.LangManager, dir string, logger *slog.Logger) (bool, *subcommands) {
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -16,6 +17,7 @@ import (
 	"github.com/akamai/cli/v2/pkg/terminal"
 	"github.com/akamai/cli/v2/pkg/tools"
 	git2 "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -30,6 +32,8 @@ func TestCmdInstall(t *testing.T) {
 
 	cliTestCmdJSON := filepath.Join(".", "testdata", ".akamai-cli", "src", "cli-test-cmd", "cli.json")
 	cliTestInvalidJSONRepo := filepath.Join(".", "testdata", ".akamai-cli", "src", "cli-test-invalid-json")
+	commitHash := "0123456789abcdef0123456789abcdef01234567"
+	var rawPaths []string
 	tests := map[string]struct {
 		args                 []string
 		init                 func(*testing.T, *mocked)
@@ -178,7 +182,18 @@ func TestCmdInstall(t *testing.T) {
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("Start", "Attempting to fetch package configuration from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
 
-				h := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				commits := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, "/repos/akamai/cli-test-cmd/commits/main", r.URL.Path)
+					assert.Equal(t, "application/vnd.github.sha", r.Header.Get("Accept"))
+					_, err := w.Write([]byte(commitHash))
+					assert.NoError(t, err)
+				}))
+				buildGitHubCommitURL = func(owner, repo, ref string) string {
+					return fmt.Sprintf("%s/repos/%s/%s/commits/%s", commits.URL, owner, repo, ref)
+				}
+				rawPaths = nil
+				h := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					rawPaths = append(rawPaths, r.URL.Path)
 					configJSON, err := os.ReadFile(cliJSON)
 					output := strings.ReplaceAll(string(configJSON), "${REPOSITORY_URL}", os.Getenv("REPOSITORY_URL"))
 					require.NoError(t, err)
@@ -228,9 +243,51 @@ func TestCmdInstall(t *testing.T) {
 				buildRawGitHubURL = func(owner, repo, branch string) string {
 					return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/cli.json", owner, repo, branch)
 				}
+				buildGitHubCommitURL = unresolvableGitHubCommitURL
+				// the binaries are installed from the cli.json of the resolved commit, which is recorded for lock files
+				assert.Equal(t, []string{"/akamai/cli-test-cmd/main/cli.json", "/akamai/cli-test-cmd/" + commitHash + "/cli.json"}, rawPaths)
+				origin, err := readPackageOrigin(cliTestCmdRepo)
+				assert.NoError(t, err)
+				assert.Equal(t, packageOrigin{Repository: "https://github.com/akamai/cli-test-cmd.git", Commit: commitHash}, origin)
 				require.NoError(t, os.RemoveAll(cliTestCmdRepo))
 			},
 		},
+		"install from official akamai repository, binary checksum mismatch, do not build from source": {
+			args: []string{"test-cmd"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Attempting to fetch package configuration from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+
+				h := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					configJSON, err := os.ReadFile(cliJSON)
+					require.NoError(t, err)
+					output := strings.ReplaceAll(string(configJSON), "${REPOSITORY_URL}", os.Getenv("REPOSITORY_URL"))
+					output = strings.ReplaceAll(output, `"bin":`, fmt.Sprintf(`"checksum": "%s", "bin":`, strings.Repeat("0", 64)))
+					_, err = w.Write([]byte(output))
+					require.NoError(t, err)
+				}))
+				buildRawGitHubURL = func(owner, repo, branch string) string {
+					return fmt.Sprintf("%s/%s/%s/%s/cli.json", h.URL, owner, repo, branch)
+				}
+				m.term.On("OK").Return().Once()
+
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Installing Binaries...", []interface{}(nil)).Return().Once()
+				m.term.On("Stop", terminal.SpinnerStatusWarn).Return().Once()
+				m.term.On("Writeln", mock.MatchedBy(func(args []interface{}) bool {
+					return len(args) == 1 && strings.Contains(fmt.Sprint(args[0]), "checksum mismatch")
+				})).Return(0, nil).Once()
+			},
+			binaryResponseStatus: http.StatusOK,
+			teardown: func(t *testing.T) {
+				buildRawGitHubURL = func(owner, repo, branch string) string {
+					return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/cli.json", owner, repo, branch)
+				}
+				_, err := os.Stat(cliTestCmdRepo)
+				assert.True(t, os.IsNotExist(err))
+			},
+			withError: "Unable to install selected package",
+		},
 		"install from third-party repository shows disclaimer": {
 			args: []string{"https://github.com/other-user/cli-other-pkg.git"},
 			init: func(t *testing.T, m *mocked) {
@@ -675,6 +732,82 @@ func TestCmdInstall(t *testing.T) {
 			},
 			withError: "Unable to install selected package",
 		},
+		"install from official akamai repository at tag, build from source": {
+			args: []string{"test-cmd@v1.2.0"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Attempting to fetch package configuration from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+
+				h := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, "/akamai/cli-test-cmd/v1.2.0/cli.json", r.URL.String())
+					configJSON, err := os.ReadFile(cliNoBinaryJSON)
+					require.NoError(t, err)
+					_, err = w.Write(configJSON)
+					require.NoError(t, err)
+				}))
+				buildRawGitHubURL = func(owner, repo, branch string) string {
+					return fmt.Sprintf("%s/%s/%s/%s/cli.json", h.URL, owner, repo, branch)
+				}
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.term.On("OK").Return()
+
+				m.gitRepo.On("Clone", filepath.Join("testdata", ".akamai-cli", "src", "cli-test-cmd"),
+					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					Run(func(_ mock.Arguments) {
+						mustCopyFile(t, cliNoBinaryJSON, cliTestCmdRepo)
+					})
+				m.gitRepo.On("ResolveRevision", "v1.2.0").Return(plumbing.Hash{1}, nil).Once()
+				m.gitRepo.On("Checkout", &git2.CheckoutOptions{Hash: plumbing.Hash{1}, Force: true}).Return(nil).Once()
+				m.term.On("Start", "Installing Dependencies...", []interface{}(nil)).Return().Once()
+
+				m.langManager.On("Install", filepath.Join("testdata", ".akamai-cli", "src", "cli-test-cmd"),
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}, []string{""}).Return(nil).Once()
+
+				m.term.On("Writeln", mock.Anything).Return(0, nil)
+				m.term.On("Printf", mock.Anything, mock.Anything).Return()
+			},
+			teardown: func(t *testing.T) {
+				buildRawGitHubURL = func(owner, repo, branch string) string {
+					return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/cli.json", owner, repo, branch)
+				}
+				assert.Equal(t, "v1.2.0", readPackagePin(cliTestCmdRepo))
+				require.NoError(t, os.RemoveAll(cliTestCmdRepo))
+			},
+		},
+		"unknown ref in repository": {
+			args: []string{"akamai/cli-test-cmd#no-such-branch"},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Attempting to fetch package configuration from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+
+				h := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					configJSON, err := os.ReadFile(cliNoBinaryJSON)
+					require.NoError(t, err)
+					_, err = w.Write(configJSON)
+					require.NoError(t, err)
+				}))
+				buildRawGitHubURL = func(owner, repo, branch string) string {
+					return fmt.Sprintf("%s/%s/%s/%s/cli.json", h.URL, owner, repo, branch)
+				}
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.term.On("OK").Return().Once()
+
+				m.gitRepo.On("Clone", filepath.Join("testdata", ".akamai-cli", "src", "cli-test-cmd"),
+					"https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					Run(func(_ mock.Arguments) {
+						mustCopyFile(t, cliNoBinaryJSON, cliTestCmdRepo)
+					})
+				m.gitRepo.On("ResolveRevision", "no-such-branch").Return(plumbing.ZeroHash, fmt.Errorf("unable to resolve revision")).Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+			},
+			teardown: func(t *testing.T) {
+				buildRawGitHubURL = func(owner, repo, branch string) string {
+					return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/cli.json", owner, repo, branch)
+				}
+				assert.NoDirExists(t, cliTestCmdRepo)
+			},
+			withError: `Unable to find "no-such-branch" in the package repository`,
+		},
 		"package not found on github, 404 response": {
 			args: []string{"test-cmd"},
 			init: func(_ *testing.T, m *mocked) {
@@ -771,3 +904,147 @@ func TestExtractOwnerAndRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitPackageRef(t *testing.T) {
+	tests := map[string]struct {
+		arg          string
+		expectedRepo string
+		expectedRef  string
+	}{
+		"no ref":                    {"property", "property", ""},
+		"version":                   {"property@v2.3.1", "property", "v2.3.1"},
+		"branch":                    {"akamai/cli-purge#feature-x", "akamai/cli-purge", "feature-x"},
+		"ssh url without ref":       {"git@github.com:akamai/cli-property.git", "git@github.com:akamai/cli-property.git", ""},
+		"ssh url with version":      {"git@github.com:akamai/cli-property.git@1.0.0", "git@github.com:akamai/cli-property.git", "1.0.0"},
+		"https url with commit ref": {"https://github.com/akamai/cli-property.git#0a1b2c3", "https://github.com/akamai/cli-property.git", "0a1b2c3"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo, ref := splitPackageRef(test.arg)
+			assert.Equal(t, test.expectedRepo, repo)
+			assert.Equal(t, test.expectedRef, ref)
+		})
+	}
+}
+
+func TestCmdInstallDryRun(t *testing.T) {
+	cliHome := t.TempDir()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+	defer func() {
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", filepath.Join(".", "testdata")))
+	}()
+	srcDir := filepath.Join(cliHome, ".akamai-cli", "src")
+	require.NoError(t, os.MkdirAll(filepath.Join(srcDir, "cli-installed"), 0755))
+
+	configs := map[string]string{
+		"cli-binary": `{"requirements":{"go":"1.14.0"},"commands":[{"name":"binary","version":"1.0.0","bin":"https://example.com/akamai-{{.Name}}"}]}`,
+		"cli-python": `{"requirements":{"python":"3.*"},"commands":[{"name":"python","version":"2.0.0"}]}`,
+	}
+	h := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(r.URL.Path, "/")
+		config, ok := configs[parts[2]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, err := w.Write([]byte(config))
+		assert.NoError(t, err)
+	}))
+	defer h.Close()
+	buildRawGitHubURL = func(owner, repo, branch string) string {
+		return fmt.Sprintf("%s/%s/%s/%s/cli.json", h.URL, owner, repo, branch)
+	}
+	defer func() {
+		buildRawGitHubURL = func(owner, repo, branch string) string {
+			return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/cli.json", owner, repo, branch)
+		}
+	}()
+
+	tests := map[string]struct {
+		args      []string
+		expected  []dryRunPlan
+		withError string
+	}{
+		"binary package": {
+			args: []string{"binary"},
+			expected: []dryRunPlan{{
+				Action: "install", Package: "cli-binary", Repository: "https://github.com/akamai/cli-binary.git",
+				Strategy: "binary", Runtime: "go 1.14.0", Version: "1.0.0",
+				CreateDirs:  []string{filepath.Join(srcDir, "cli-binary"), filepath.Join(srcDir, "cli-binary", "bin")},
+				AddCommands: []string{"binary"},
+				Notes:       []string{"If the binaries cannot be downloaded, the repository is cloned and built from source."},
+			}},
+		},
+		"python package at version": {
+			args: []string{"python@2.0.0"},
+			expected: []dryRunPlan{{
+				Action: "install", Package: "cli-python", Repository: "https://github.com/akamai/cli-python.git", Ref: "2.0.0",
+				Strategy: "source", Runtime: "python 3.0.0", Version: "2.0.0",
+				CreateDirs:  []string{filepath.Join(srcDir, "cli-python"), filepath.Join(cliHome, ".akamai-cli", "venv", "cli-python")},
+				AddCommands: []string{"python"},
+			}},
+		},
+		"installed and third-party packages": {
+			args: []string{"installed", "https://git.example.com/tools/cli-tool.git"},
+			expected: []dryRunPlan{
+				{
+					Action: "install", Package: "cli-installed", Repository: "https://github.com/akamai/cli-installed.git",
+					Notes: []string{fmt.Sprintf("Package directory already exists (%s), nothing would be installed. To reinstall this package, first run 'akamai uninstall' command.", filepath.Join(srcDir, "cli-installed"))},
+				},
+				{
+					Action: "install", Package: "cli-tool", Repository: "https://git.example.com/tools/cli-tool.git", Strategy: "source",
+					CreateDirs: []string{filepath.Join(srcDir, "cli-tool")},
+					Notes:      []string{"The package configuration is only available after cloning the repository, the runtime and commands are determined then."},
+				},
+			},
+		},
+		"package without cli.json": {
+			args: []string{"missing"},
+			expected: []dryRunPlan{{
+				Action: "install", Package: "cli-missing", Repository: "https://github.com/akamai/cli-missing.git",
+				Error: "unable to fetch package configuration: invalid response status while fetching cli.json: 404",
+			}},
+			withError: "Unable to install 1 of 1 package(s)",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.MockRepo{}, &packages.Mock{}, nil}
+			var output string
+			m.term.On("Printf", "%s\n", mock.Anything).Run(func(args mock.Arguments) {
+				output = args.Get(1).([]interface{})[0].(string)
+			}).Return().Once()
+
+			command := &cli.Command{
+				Name:   "install",
+				Action: cmdInstall(m.gitRepo, m.langManager),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name: "dry-run",
+					},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "--output", "json", "install", "--dry-run")
+			args = append(args, test.args...)
+
+			err := app.RunContext(ctx, args)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			var out dryRunOutput
+			require.NoError(t, json.Unmarshal([]byte(output), &out))
+			assert.Equal(t, test.expected, out.Plans)
+			entries, err := os.ReadDir(srcDir)
+			require.NoError(t, err)
+			assert.Len(t, entries, 1)
+		})
+	}
+}
@@ -2,21 +2,55 @@ package commands
 
 import (
 	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/akamai/cli/v2/pkg/color"
+	"github.com/akamai/cli/v2/pkg/git"
 	"github.com/akamai/cli/v2/pkg/log"
 	"github.com/akamai/cli/v2/pkg/terminal"
 	"github.com/akamai/cli/v2/pkg/tools"
 	"github.com/urfave/cli/v2"
 )
 
-func cmdList(c *cli.Context) (e error) {
-	pr := newPackageReader(embeddedPackages)
-	return cmdListWithPackageReader(c, pr)
+type (
+	// listOutput is the document written by the list command in JSON and YAML output formats
+	listOutput struct {
+		Installed []installedCommandOutput `json:"installed" yaml:"installed"`
+		Available []availableCommandOutput `json:"available,omitempty" yaml:"available,omitempty"`
+	}
+
+	installedCommandOutput struct {
+		Name        string   `json:"name" yaml:"name"`
+		Aliases     []string `json:"aliases" yaml:"aliases"`
+		Version     string   `json:"version,omitempty" yaml:"version,omitempty"`
+		Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+		Builtin     bool     `json:"builtin" yaml:"builtin"`
+		Package     string   `json:"package,omitempty" yaml:"package,omitempty"`
+		PackageDir  string   `json:"package-dir,omitempty" yaml:"package-dir,omitempty"`
+		Language    string   `json:"language,omitempty" yaml:"language,omitempty"`
+		Source      string   `json:"source,omitempty" yaml:"source,omitempty"`
+	}
+
+	availableCommandOutput struct {
+		Name        string `json:"name" yaml:"name"`
+		Version     string `json:"version,omitempty" yaml:"version,omitempty"`
+		Description string `json:"description,omitempty" yaml:"description,omitempty"`
+		Package     string `json:"package" yaml:"package"`
+		Registry    string `json:"registry,omitempty" yaml:"registry,omitempty"`
+	}
+)
+
+func cmdList(gitRepo git.Repository) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		pr := newRegistryReader(c.Context)
+		return cmdListWithPackageReader(c, pr, gitRepo)
+	}
 }
 
-func cmdListWithPackageReader(c *cli.Context, pr packageReader) (e error) {
+func cmdListWithPackageReader(c *cli.Context, pr packageReader, gitRepo git.Repository) (e error) {
 	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
 	start := time.Now()
 	logger := log.FromContext(c.Context)
@@ -30,6 +64,10 @@ func cmdListWithPackageReader(c *cli.Context, pr packageReader) (e error) {
 	}()
 	term := terminal.Get(c.Context)
 
+	if isStructuredOutput(c) {
+		return writeListOutput(c, pr, gitRepo)
+	}
+
 	commands := listInstalledCommands(c, nil, nil)
 
 	if c.IsSet("remote") {
@@ -66,6 +104,9 @@ func cmdListWithPackageReader(c *cli.Context, pr packageReader) (e error) {
 				commandName := color.BoldString("  %s", command.Name)
 				term.Printf(commandName)
 				packageName := fmt.Sprintf(" [package: %s]", color.BlueString("%s", remotePackage.Name))
+				if remotePackage.Source != "" {
+					packageName = fmt.Sprintf(" [package: %s, registry: %s]", color.BlueString("%s", remotePackage.Name), remotePackage.Source)
+				}
 				if _, err := term.Writeln(packageName); err != nil {
 					return err
 				}
@@ -83,6 +124,110 @@ func cmdListWithPackageReader(c *cli.Context, pr packageReader) (e error) {
 	return nil
 }
 
+func writeListOutput(c *cli.Context, pr packageReader, gitRepo git.Repository) error {
+	logger := log.FromContext(c.Context)
+	out := listOutput{Installed: installedCommandsOutput(c, gitRepo)}
+
+	if c.IsSet("remote") {
+		packages, err := pr.readPackage()
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to read package: %v", err))
+			return cli.Exit(fmt.Sprintf("list: %v", err), 1)
+		}
+
+		installed := make(map[string]bool, len(out.Installed))
+		for _, cmd := range out.Installed {
+			installed[cmd.Name] = true
+		}
+		out.Available = make([]availableCommandOutput, 0)
+		for _, remotePackage := range packages.Packages {
+			for _, command := range remotePackage.Commands {
+				if installed[command.Name] {
+					continue
+				}
+				out.Available = append(out.Available, availableCommandOutput{
+					Name:        command.Name,
+					Version:     command.Version,
+					Description: command.Description,
+					Package:     remotePackage.Name,
+					Registry:    remotePackage.Source,
+				})
+			}
+		}
+	}
+
+	return writeOutput(terminal.Get(c.Context), outputFormat(c), out)
+}
+
+// installedCommandsOutput describes the built-in commands and the commands of installed packages
+func installedCommandsOutput(c *cli.Context, gitRepo git.Repository) []installedCommandOutput {
+	logger := log.FromContext(c.Context)
+	installed := make([]installedCommandOutput, 0)
+	fromPackages := make(map[string]bool)
+
+	for _, dir := range getPackagePaths() {
+		if strings.HasPrefix(filepath.Base(dir), ".") {
+			continue
+		}
+		cmdPackage, err := readPackage(dir)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Skipping package %s: %v", filepath.Base(dir), err))
+			continue
+		}
+		source := packageSourceURL(gitRepo, dir)
+		lang, _ := cmdPackage.Requirements.Runtime()
+		for _, cmd := range cmdPackage.Commands {
+			fromPackages[cmd.Name] = true
+			aliases := cmd.Aliases
+			if aliases == nil {
+				aliases = []string{}
+			}
+			installed = append(installed, installedCommandOutput{
+				Name:        cmd.Name,
+				Aliases:     aliases,
+				Version:     cmd.Version,
+				Description: cmd.Description,
+				Package:     filepath.Base(dir),
+				PackageDir:  dir,
+				Language:    lang,
+				Source:      source,
+			})
+		}
+	}
+
+	for _, cmd := range c.App.Commands {
+		if fromPackages[cmd.Name] {
+			continue
+		}
+		aliases := cmd.Aliases
+		if aliases == nil {
+			aliases = []string{}
+		}
+		installed = append(installed, installedCommandOutput{
+			Name:        cmd.Name,
+			Aliases:     aliases,
+			Description: cmd.Description,
+			Builtin:     true,
+		})
+	}
+
+	sort.Slice(installed, func(i, j int) bool {
+		return installed[i].Name < installed[j].Name
+	})
+	return installed
+}
+
+// packageSourceURL returns the repository a package was installed from.
+// Packages installed as binaries are not git repositories, so their GitHub repository is assumed.
+func packageSourceURL(gitRepo git.Repository, dir string) string {
+	if gitRepo.Open(dir) == nil {
+		if url, err := gitRepo.RemoteURL(git.DefaultRemoteName); err == nil {
+			return url
+		}
+	}
+	return tools.Githubize(filepath.Base(dir))
+}
+
 func listInstalledCommands(c *cli.Context, added map[string]bool, removed map[string]bool) map[string]bool {
 	term := terminal.Get(c.Context)
 
@@ -1,15 +1,19 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/akamai/cli/v2/pkg/color"
 	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/git"
 	"github.com/akamai/cli/v2/pkg/terminal"
 	"github.com/akamai/cli/v2/pkg/tools"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
 )
@@ -100,7 +104,7 @@ func TestCmdListWithRemote(t *testing.T) {
 				Description: "Displays available commands",
 				Aliases:     []string{"ls", "show"},
 				Action: func(context *cli.Context) error {
-					return cmdListWithPackageReader(context, pr)
+					return cmdListWithPackageReader(context, pr, m.gitRepo)
 				},
 			}
 
@@ -122,3 +126,100 @@ func TestCmdListWithRemote(t *testing.T) {
 		})
 	}
 }
+
+func TestCmdListStructuredOutput(t *testing.T) {
+	cliHome := t.TempDir()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+	defer func() {
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "testdata"))
+	}()
+	echoDir := filepath.Join(cliHome, ".akamai-cli", "src", "cli-echo")
+	require.NoError(t, os.MkdirAll(echoDir, 0755))
+	cliJSON, err := os.ReadFile(filepath.Join("testdata", ".akamai-cli", "src", "cli-echo", "cli.json"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(echoDir, "cli.json"), cliJSON, 0644))
+
+	tests := map[string]struct {
+		args     []string
+		init     func(*mocked)
+		expected listOutput
+	}{
+		"installed commands from git repository": {
+			init: func(m *mocked) {
+				m.gitRepo.On("Open", echoDir).Return(nil).Once()
+				m.gitRepo.On("RemoteURL", "origin").Return("https://git.example.com/tools/cli-echo.git", nil).Once()
+			},
+			expected: listOutput{
+				Installed: []installedCommandOutput{
+					{Name: "echo", Aliases: []string{"e"}, Version: "1.0.0", Description: "echo command", Package: "cli-echo",
+						PackageDir: echoDir, Language: "go", Source: "https://git.example.com/tools/cli-echo.git"},
+					{Name: "help", Aliases: []string{"h"}, Builtin: true},
+					{Name: "list", Aliases: []string{}, Description: "Displays available commands", Builtin: true},
+				},
+			},
+		},
+		"installed binary and available commands": {
+			args: []string{"--remote"},
+			init: func(m *mocked) {
+				m.gitRepo.On("Open", echoDir).Return(git.ErrPackageNotAvailable).Once()
+			},
+			expected: listOutput{
+				Installed: []installedCommandOutput{
+					{Name: "echo", Aliases: []string{"e"}, Version: "1.0.0", Description: "echo command", Package: "cli-echo",
+						PackageDir: echoDir, Language: "go", Source: "https://github.com/akamai/cli-echo.git"},
+					{Name: "help", Aliases: []string{"h"}, Builtin: true},
+					{Name: "list", Aliases: []string{}, Description: "Displays available commands", Builtin: true},
+				},
+				Available: []availableCommandOutput{
+					{Name: "sample", Version: "1.0.0", Description: "test for single match", Package: "SAMPLE", Registry: "https://git.example.com/packages.json"},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.MockRepo{}, nil, nil}
+			pr := &mockPackageReader{}
+			pr.On("readPackage").Return(&packageList{Version: 1.0, Packages: []packageListItem{
+				{Name: "SAMPLE", Source: "https://git.example.com/packages.json", Commands: []command{
+					{Name: "sample", Version: "1.0.0", Description: "test for single match"},
+				}},
+				{Name: "echo", Commands: []command{{Name: "echo"}}},
+			}}, nil).Maybe()
+
+			var output string
+			m.term.On("Printf", "%s\n", mock.Anything).Run(func(args mock.Arguments) {
+				output = args.Get(1).([]interface{})[0].(string)
+			}).Return().Once()
+
+			commandToExecute := &cli.Command{
+				Name: "list",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name: "remote",
+					},
+				},
+				Description: "Displays available commands",
+				Action: func(context *cli.Context) error {
+					return cmdListWithPackageReader(context, pr, m.gitRepo)
+				},
+			}
+
+			app, ctx := setupTestApp(commandToExecute, m)
+			args := os.Args[0:1]
+			args = append(args, "--output", "json", "list")
+			args = append(args, test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+			require.NoError(t, err)
+
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			var out listOutput
+			require.NoError(t, json.Unmarshal([]byte(output), &out))
+			assert.Equal(t, test.expected, out)
+		})
+	}
+}
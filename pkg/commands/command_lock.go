@@ -0,0 +1,428 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/color"
+	"github.com/akamai/cli/v2/pkg/git"
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/packages"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/akamai/cli/v2/pkg/tools"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	defaultLockFileName = "akamai.lock"
+	lockFileVersion     = 1
+)
+
+type (
+	// lockFile represents the set of installed packages pinned to exact revisions
+	lockFile struct {
+		Version  int             `json:"version"`
+		Packages []lockedPackage `json:"packages"`
+	}
+
+	// lockedPackage represents a single package entry in the lock file
+	lockedPackage struct {
+		Name       string          `json:"name"`
+		Repository string          `json:"repository"`
+		Commit     string          `json:"commit,omitempty"`
+		Ref        string          `json:"ref,omitempty"`
+		Binary     bool            `json:"binary"`
+		Commands   []lockedCommand `json:"commands"`
+	}
+
+	// lockedCommand represents a command version recorded in the lock file
+	lockedCommand struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+)
+
+// errInvalidLockFile is returned when the lock file cannot be used for installation
+var errInvalidLockFile = errors.New("invalid lock file")
+
+func cmdLock(gitRepo git.Repository) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		logger := log.FromContext(c.Context)
+		start := time.Now()
+		logger.Debug("LOCK START")
+		defer func() {
+			if e == nil {
+				logger.Debug(fmt.Sprintf("LOCK FINISH: %v", time.Since(start)))
+			} else {
+				logger.Error(fmt.Sprintf("LOCK ERROR: %v", e))
+			}
+		}()
+
+		path := defaultLockFileName
+		if c.Args().Present() {
+			path = c.Args().First()
+		}
+
+		lock, err := createLockFile(c.Context, gitRepo)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to create lock file: %v", err), 1)
+		}
+
+		if err := writeLockFile(path, lock); err != nil {
+			return cli.Exit(color.RedString("Unable to write lock file: %v", err), 1)
+		}
+
+		terminal.Get(c.Context).Printf("Locked %d package(s) in %s\n", len(lock.Packages), color.BlueString("%s", path))
+		return nil
+	}
+}
+
+// createLockFile records the repository, revision, pinned ref and command versions of every installed package.
+// Binary packages are locked to the repository and commit recorded when they were installed. Packages installed
+// before the origin was recorded are assumed to come from the GitHub repository matching their name, at its latest commit.
+func createLockFile(ctx context.Context, gitRepo git.Repository) (*lockFile, error) {
+	logger := log.FromContext(ctx)
+	lock := &lockFile{Version: lockFileVersion, Packages: make([]lockedPackage, 0)}
+
+	for _, dir := range getPackagePaths() {
+		name := filepath.Base(dir)
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		cmdPackage, err := readPackage(dir)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Skipping package %s: %v", name, err))
+			continue
+		}
+
+		pkg := lockedPackage{Name: name, Ref: readPackagePin(dir), Commands: make([]lockedCommand, 0, len(cmdPackage.Commands))}
+		for _, cmd := range cmdPackage.Commands {
+			pkg.Commands = append(pkg.Commands, lockedCommand{Name: cmd.Name, Version: cmd.Version})
+		}
+
+		if err := gitRepo.Open(dir); err != nil {
+			logger.Debug(fmt.Sprintf("Package %s is not a git repository, locking as binary install", name))
+			origin, err := readPackageOrigin(dir)
+			if err != nil {
+				origin = packageOrigin{Repository: tools.Githubize(name)}
+				logger.Warn(fmt.Sprintf("The origin of package %s is unknown, assuming %s: %v", name, origin.Repository, err))
+			}
+			if origin.Commit == "" {
+				logger.Warn(fmt.Sprintf("The commit of package %s is unknown, it is installed from the latest cli.json of %s", name, origin.Repository))
+			}
+			pkg.Binary = true
+			pkg.Repository = origin.Repository
+			pkg.Commit = origin.Commit
+		} else {
+			ref, err := gitRepo.Head()
+			if err != nil {
+				return nil, fmt.Errorf("unable to resolve revision of package %s: %w", name, err)
+			}
+			pkg.Commit = ref.Hash().String()
+
+			pkg.Repository, err = gitRepo.RemoteURL(git.DefaultRemoteName)
+			if err != nil {
+				return nil, fmt.Errorf("unable to resolve repository of package %s: %w", name, err)
+			}
+		}
+
+		lock.Packages = append(lock.Packages, pkg)
+	}
+
+	return lock, nil
+}
+
+func readLockFile(path string) (*lockFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock lockFile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidLockFile, err)
+	}
+
+	if lock.Version > lockFileVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", errInvalidLockFile, lock.Version)
+	}
+
+	for _, pkg := range lock.Packages {
+		if pkg.Name == "" || pkg.Repository == "" {
+			return nil, fmt.Errorf("%w: package entries require a name and a repository", errInvalidLockFile)
+		}
+		if !pkg.Binary && pkg.Commit == "" {
+			return nil, fmt.Errorf("%w: package %s has no commit", errInvalidLockFile, pkg.Name)
+		}
+	}
+
+	return &lock, nil
+}
+
+func writeLockFile(path string, lock *lockFile) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// installFromLockFile installs every package listed in the lock file at its pinned revision
+func installFromLockFile(c *cli.Context, gitRepo git.Repository, langManager packages.LangManager, path string) error {
+	logger := log.FromContext(c.Context)
+	term := terminal.Get(c.Context)
+
+	lock, err := readLockFile(path)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Unable to read lock file: %v", err))
+		return cli.Exit(color.RedString("Unable to read lock file: %v", err), 1)
+	}
+
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Unable to get akamai cli source path: %v", err))
+		return err
+	}
+
+	oldCmds := getCommands(c)
+
+	for _, pkg := range lock.Packages {
+		packageDir := filepath.Join(srcPath, pkg.Name)
+		if _, err := os.Stat(packageDir); err == nil {
+			if isLockedRevisionInstalled(gitRepo, packageDir, pkg) {
+				logger.Debug(fmt.Sprintf("Package %s is already installed at the locked revision", pkg.Name))
+				continue
+			}
+			warnMsg := fmt.Sprintf("Package %s is installed at a different revision than locked. To reinstall this package, first run 'akamai uninstall' command.", pkg.Name)
+			logger.Warn(warnMsg)
+			if _, err := term.Writeln(color.YellowString("%s", warnMsg)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		subCmd, err := installLockedPackage(c.Context, gitRepo, langManager, packageDir, pkg)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error installing package: %v", err))
+			return err
+		}
+		c.App.Commands = append(c.App.Commands, subcommandToCliCommands(*subCmd, gitRepo, langManager)...)
+		sortCommands(c.App.Commands)
+	}
+
+	packageListDiff(c, oldCmds)
+
+	return nil
+}
+
+// planFromLockFile reports how the packages of a lock file would be installed, without installing anything
+func planFromLockFile(c *cli.Context, gitRepo git.Repository, path string) error {
+	lock, err := readLockFile(path)
+	if err != nil {
+		log.FromContext(c.Context).Error(fmt.Sprintf("Unable to read lock file: %v", err))
+		return cli.Exit(color.RedString("Unable to read lock file: %v", err), 1)
+	}
+
+	plans := make([]dryRunPlan, 0, len(lock.Packages))
+	for _, pkg := range lock.Packages {
+		plan, err := planLockedInstall(c.Context, gitRepo, pkg)
+		if err != nil {
+			plan.Error = err.Error()
+		}
+		plans = append(plans, plan)
+	}
+	return writeDryRun(c, plans)
+}
+
+func isLockedRevisionInstalled(gitRepo git.Repository, packageDir string, pkg lockedPackage) bool {
+	if pkg.Binary {
+		cmdPackage, err := readPackage(packageDir)
+		if err != nil {
+			return false
+		}
+		if pkg.Commit != "" {
+			if origin, err := readPackageOrigin(packageDir); err != nil || origin.Commit != pkg.Commit {
+				return false
+			}
+		}
+		return lockedVersionsMatch(cmdPackage, pkg.Commands)
+	}
+
+	if err := gitRepo.Open(packageDir); err != nil {
+		return false
+	}
+	ref, err := gitRepo.Head()
+	if err != nil {
+		return false
+	}
+	return ref.Hash().String() == pkg.Commit
+}
+
+// installLockedPackage installs a locked package at its locked revision and pin. Like installPackage, it marks the package
+// as being installed, so that an interrupted install is cleaned up on the next run
+func installLockedPackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, packageDir string, pkg lockedPackage) (_ *subcommands, e error) {
+	logger := log.FromContext(ctx)
+	if err := os.MkdirAll(filepath.Dir(packageDir), 0700); err != nil {
+		logger.Error(fmt.Sprintf("Unable to create directory %s: %v", filepath.Dir(packageDir), err))
+		return nil, err
+	}
+	unmark, err := markInstalling(packageDir)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Unable to mark package as being installed: %v", err))
+		return nil, err
+	}
+	defer func() {
+		if _, err := os.Stat(packageDir); e == nil || os.IsNotExist(err) {
+			unmark()
+		}
+	}()
+
+	subCmd, err := fetchLockedPackage(ctx, gitRepo, langManager, packageDir, pkg)
+	if err != nil {
+		return nil, err
+	}
+	return subCmd, pinPackage(ctx, packageDir, pkg.Ref)
+}
+
+func fetchLockedPackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, packageDir string, pkg lockedPackage) (*subcommands, error) {
+	logger := log.FromContext(ctx)
+	term := terminal.Get(ctx)
+	spin := term.Spinner()
+
+	if pkg.Binary {
+		spin.Start("Attempting to fetch package configuration from %s...", pkg.Repository)
+		owner, repoName := extractOwnerAndRepo(pkg.Repository)
+		if pkg.Commit == "" {
+			logger.Warn(fmt.Sprintf("Package %s is locked without a commit, installing the latest cli.json", pkg.Name))
+		}
+		// an empty commit falls back to the default branches
+		cmdPackage, fetchErr := fetchPackageConfig(owner, repoName, pkg.Name, refCandidates(pkg.Commit))
+		if fetchErr != nil {
+			spin.Stop(terminal.SpinnerStatusFail)
+			logger.Error(fmt.Sprintf("Failed to read package from github: %v", fetchErr))
+			return nil, cli.Exit(color.RedString("Unable to install package %s: %v", pkg.Name, fetchErr), 1)
+		}
+		if err := pinCommandVersions(&cmdPackage, pkg.Commands); err != nil {
+			spin.Stop(terminal.SpinnerStatusFail)
+			logger.Error(fmt.Sprintf("Failed to pin command versions: %v", err))
+			return nil, cli.Exit(color.RedString("Unable to install package %s: %v", pkg.Name, err), 1)
+		}
+		spin.OK()
+
+		ok, subCmd := installPackageBinaries(ctx, packageDir, cmdPackage, logger)
+		if !ok {
+			if err := os.RemoveAll(packageDir); err != nil {
+				logger.Error(fmt.Sprintf("Failed to remove package directory: %v", err))
+				return nil, err
+			}
+			return nil, cli.Exit(color.RedString("Unable to install package %s", pkg.Name), 1)
+		}
+		if err := writePackageOrigin(packageDir, packageOrigin{Repository: pkg.Repository, Commit: pkg.Commit}); err != nil {
+			logger.Error(fmt.Sprintf("Unable to record package origin: %v", err))
+			return nil, err
+		}
+		return subCmd, nil
+	}
+
+	spin.Start("Attempting to fetch command from %s...", pkg.Repository)
+	if err := gitRepo.Clone(ctx, packageDir, pkg.Repository, false, spin); err != nil {
+		spin.Stop(terminal.SpinnerStatusFail)
+		if err := os.RemoveAll(packageDir); err != nil {
+			logger.Error(fmt.Sprintf("Failed to remove package directory: %v", err))
+			return nil, err
+		}
+		logger.Error(fmt.Sprintf("Failed to clone repository: %v", err))
+		return nil, cli.Exit(color.RedString("%s", tools.CapitalizeFirstWord(err.Error())), 1)
+	}
+
+	logger.Debug(fmt.Sprintf("Checking out locked commit %s", pkg.Commit))
+	if err := gitRepo.Checkout(&gogit.CheckoutOptions{Hash: plumbing.NewHash(pkg.Commit)}); err != nil {
+		spin.Stop(terminal.SpinnerStatusFail)
+		if err := os.RemoveAll(packageDir); err != nil {
+			logger.Error(fmt.Sprintf("Failed to remove package directory: %v", err))
+			return nil, err
+		}
+		logger.Error(fmt.Sprintf("Failed to check out locked commit: %v", err))
+		return nil, cli.Exit(color.RedString("Unable to check out commit %s of package %s", pkg.Commit, pkg.Name), 1)
+	}
+	spin.OK()
+
+	ok, subCmd := installPackageDependencies(ctx, langManager, packageDir, logger)
+	if !ok {
+		logger.Error(fmt.Sprintf("Dependency installation failed, removing package directory: %s", packageDir))
+		if err := os.RemoveAll(packageDir); err != nil {
+			logger.Error(fmt.Sprintf("Failed to remove package directory: %v", err))
+			return nil, err
+		}
+		return nil, cli.Exit("Unable to install selected package", 1)
+	}
+
+	return subCmd, nil
+}
+
+// pinCommandVersions overrides command versions in the package, including its raw cli.json, with the locked ones
+func pinCommandVersions(cmdPackage *subcommands, locked []lockedCommand) error {
+	versions := make(map[string]string, len(locked))
+	for _, cmd := range locked {
+		versions[strings.ToLower(cmd.Name)] = cmd.Version
+	}
+
+	for key, cmd := range cmdPackage.Commands {
+		if v, ok := versions[cmd.Name]; ok {
+			cmdPackage.Commands[key].Version = v
+		}
+	}
+
+	if len(cmdPackage.raw) == 0 {
+		return nil
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(cmdPackage.raw, &raw); err != nil {
+		return err
+	}
+	rawCommands, _ := raw["commands"].([]interface{})
+	for _, rawCommand := range rawCommands {
+		cmd, ok := rawCommand.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := cmd["name"].(string)
+		if v, ok := versions[strings.ToLower(name)]; ok {
+			cmd["version"] = v
+		}
+	}
+
+	data, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return err
+	}
+	cmdPackage.raw = data
+
+	return nil
+}
+
+func lockedVersionsMatch(cmdPackage subcommands, locked []lockedCommand) bool {
+	installed := make(map[string]string, len(cmdPackage.Commands))
+	for _, cmd := range cmdPackage.Commands {
+		installed[cmd.Name] = cmd.Version
+	}
+
+	for _, cmd := range locked {
+		if installed[strings.ToLower(cmd.Name)] != cmd.Version {
+			return false
+		}
+	}
+	return true
+}
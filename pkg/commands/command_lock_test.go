@@ -0,0 +1,318 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/git"
+	"github.com/akamai/cli/v2/pkg/packages"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCmdLock(t *testing.T) {
+	srcPath := filepath.Join("testdata", ".akamai-cli", "src")
+	commitHash := plumbing.NewHash("0123456789abcdef0123456789abcdef01234567")
+
+	tests := map[string]struct {
+		init      func(*mocked)
+		expected  []lockedPackage
+		withError string
+	}{
+		"lock source and binary packages": {
+			init: func(m *mocked) {
+				m.gitRepo.On("Open", filepath.Join(srcPath, "cli-echo")).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", commitHash), nil).Once()
+				m.gitRepo.On("RemoteURL", git.DefaultRemoteName).Return("https://github.com/akamai/cli-echo.git", nil).Once()
+				m.gitRepo.On("Open", filepath.Join(srcPath, "cli-echo-python")).Return(errors.New("not a repository")).Once()
+				m.gitRepo.On("Open", filepath.Join(srcPath, "cli-installed")).Return(errors.New("not a repository")).Once()
+				m.term.On("Printf", "Locked %d package(s) in %s\n", mock.Anything).Return().Once()
+			},
+			expected: []lockedPackage{
+				{
+					Name:       "cli-echo",
+					Repository: "https://github.com/akamai/cli-echo.git",
+					Commit:     commitHash.String(),
+					Commands:   []lockedCommand{{Name: "echo", Version: "1.0.0"}},
+				},
+				{
+					Name:       "cli-echo-python",
+					Repository: "https://github.com/example/cli-echo-python.git",
+					Binary:     true,
+					Commands:   []lockedCommand{{Name: "echo-python", Version: "1.0.0"}},
+				},
+				{
+					Name:       "cli-installed",
+					Repository: "https://github.com/akamai/cli-installed.git",
+					Commit:     "89abcdef0123456789abcdef0123456789abcdef",
+					Binary:     true,
+					Commands:   []lockedCommand{{Name: "installed", Version: "1.0.0"}},
+				},
+			},
+		},
+		"error resolving remote": {
+			init: func(m *mocked) {
+				m.gitRepo.On("Open", filepath.Join(srcPath, "cli-echo")).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", commitHash), nil).Once()
+				m.gitRepo.On("RemoteURL", git.DefaultRemoteName).Return("", errors.New("remote not found")).Once()
+			},
+			withError: "Unable to create lock file: unable to resolve repository of package cli-echo: remote not found",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "testdata"))
+			lockPath := filepath.Join(t.TempDir(), "akamai.lock")
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.MockRepo{}, &packages.Mock{}, nil}
+			command := &cli.Command{
+				Name:   "lock",
+				Action: cmdLock(m.gitRepo),
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "lock", lockPath)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.gitRepo.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+
+			lock, err := readLockFile(lockPath)
+			require.NoError(t, err)
+			assert.Equal(t, lockFileVersion, lock.Version)
+			assert.Equal(t, test.expected, lock.Packages)
+		})
+	}
+}
+
+func TestCreateLockFile(t *testing.T) {
+	writePackage := func(t *testing.T, dir string, files map[string]string) {
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		files["cli.json"] = `{"commands": [{"name": "echo", "version": "1.0.0", "bin": "https://example.com/akamai-echo"}]}`
+		for name, content := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+		}
+	}
+
+	t.Run("binary package with origin and pin", func(t *testing.T) {
+		cliHome := t.TempDir()
+		t.Setenv("AKAMAI_CLI_HOME", cliHome)
+		dir := filepath.Join(cliHome, ".akamai-cli", "src", "cli-echo")
+		writePackage(t, dir, map[string]string{
+			originFileName: `{"repository": "https://github.com/example/cli-echo.git", "commit": "0123456789abcdef0123456789abcdef01234567"}`,
+			pinFileName:    "v1.0.0\n",
+		})
+		gitRepo := &git.MockRepo{}
+		gitRepo.On("Open", dir).Return(errors.New("not a repository")).Once()
+		ctx := terminal.Context(context.Background(), &terminal.Mock{})
+
+		lock, err := createLockFile(ctx, gitRepo)
+
+		require.NoError(t, err)
+		assert.Equal(t, []lockedPackage{{
+			Name:       "cli-echo",
+			Repository: "https://github.com/example/cli-echo.git",
+			Commit:     "0123456789abcdef0123456789abcdef01234567",
+			Ref:        "v1.0.0",
+			Binary:     true,
+			Commands:   []lockedCommand{{Name: "echo", Version: "1.0.0"}},
+		}}, lock.Packages)
+		gitRepo.AssertExpectations(t)
+	})
+
+	t.Run("binary package with unknown origin", func(t *testing.T) {
+		cliHome := t.TempDir()
+		t.Setenv("AKAMAI_CLI_HOME", cliHome)
+		dir := filepath.Join(cliHome, ".akamai-cli", "src", "cli-echo")
+		writePackage(t, dir, map[string]string{})
+		gitRepo := &git.MockRepo{}
+		gitRepo.On("Open", dir).Return(errors.New("not a repository")).Once()
+		ctx := terminal.Context(context.Background(), &terminal.Mock{})
+
+		lock, err := createLockFile(ctx, gitRepo)
+
+		require.NoError(t, err)
+		assert.Equal(t, []lockedPackage{{
+			Name:       "cli-echo",
+			Repository: "https://github.com/akamai/cli-echo.git",
+			Binary:     true,
+			Commands:   []lockedCommand{{Name: "echo", Version: "1.0.0"}},
+		}}, lock.Packages)
+		gitRepo.AssertExpectations(t)
+	})
+}
+
+func TestReadLockFile(t *testing.T) {
+	tests := map[string]struct {
+		content   string
+		withError string
+	}{
+		"valid lock file": {
+			content: `{"version": 1, "packages": [{"name": "cli-echo", "repository": "https://github.com/akamai/cli-echo.git", "commit": "abc"}]}`,
+		},
+		"unsupported version": {
+			content:   `{"version": 2, "packages": []}`,
+			withError: "invalid lock file: unsupported version 2",
+		},
+		"source package without commit": {
+			content:   `{"version": 1, "packages": [{"name": "cli-echo", "repository": "https://github.com/akamai/cli-echo.git"}]}`,
+			withError: "invalid lock file: package cli-echo has no commit",
+		},
+		"invalid json": {
+			content:   `invalid`,
+			withError: "invalid lock file",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "akamai.lock")
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0644))
+
+			_, err := readLockFile(path)
+			if test.withError != "" {
+				assert.ErrorIs(t, err, errInvalidLockFile)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCmdInstallFromLockFile(t *testing.T) {
+	cliTestCmdRepo := filepath.Join("testdata", ".akamai-cli", "src", "cli-test-cmd")
+	commitHash := "0123456789abcdef0123456789abcdef01234567"
+
+	tests := map[string]struct {
+		lock      lockFile
+		init      func(*testing.T, *mocked)
+		withError string
+	}{
+		"install source package at locked commit": {
+			lock: lockFile{Version: 1, Packages: []lockedPackage{{
+				Name:       "cli-test-cmd",
+				Repository: "https://github.com/akamai/cli-test-cmd.git",
+				Commit:     commitHash,
+			}}},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.gitRepo.On("Clone", cliTestCmdRepo, "https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					Run(func(_ mock.Arguments) {
+						mustCopyFile(t, filepath.Join("testdata", "repo_no_binary", "cli.json"), cliTestCmdRepo)
+					})
+				m.gitRepo.On("Checkout", &gogit.CheckoutOptions{Hash: plumbing.NewHash(commitHash)}).Return(nil).Once()
+				m.term.On("OK").Return()
+				m.term.On("Start", "Installing Dependencies...", []interface{}(nil)).Return().Once()
+				m.langManager.On("Install", cliTestCmdRepo,
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"app-1-cmd-1"}, []string{""}).Return(nil).Once()
+
+				m.term.On("Writeln", mock.Anything).Return(0, nil)
+				m.term.On("Printf", mock.Anything, mock.Anything).Return()
+			},
+		},
+		"checkout fails": {
+			lock: lockFile{Version: 1, Packages: []lockedPackage{{
+				Name:       "cli-test-cmd",
+				Repository: "https://github.com/akamai/cli-test-cmd.git",
+				Commit:     commitHash,
+			}}},
+			init: func(t *testing.T, m *mocked) {
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Attempting to fetch command from %s...", []interface{}{"https://github.com/akamai/cli-test-cmd.git"}).Return().Once()
+				m.gitRepo.On("Clone", cliTestCmdRepo, "https://github.com/akamai/cli-test-cmd.git", false, m.term).Return(nil).Once().
+					Run(func(_ mock.Arguments) {
+						mustCopyFile(t, filepath.Join("testdata", "repo_no_binary", "cli.json"), cliTestCmdRepo)
+					})
+				m.gitRepo.On("Checkout", &gogit.CheckoutOptions{Hash: plumbing.NewHash(commitHash)}).Return(errors.New("object not found")).Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+			},
+			withError: "Unable to check out commit " + commitHash + " of package cli-test-cmd",
+		},
+		"already installed at locked revision": {
+			lock: lockFile{Version: 1, Packages: []lockedPackage{{
+				Name:       "cli-echo",
+				Repository: "https://github.com/akamai/cli-echo.git",
+				Commit:     commitHash,
+			}}},
+			init: func(_ *testing.T, m *mocked) {
+				m.gitRepo.On("Open", filepath.Join("testdata", ".akamai-cli", "src", "cli-echo")).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.NewHash(commitHash)), nil).Once()
+				m.term.On("Writeln", mock.Anything).Return(0, nil)
+				m.term.On("Printf", mock.Anything, mock.Anything).Return()
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "testdata"))
+			lockPath := filepath.Join(t.TempDir(), "akamai.lock")
+			require.NoError(t, writeLockFile(lockPath, &test.lock))
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliTestCmdRepo))
+			}()
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.MockRepo{}, &packages.Mock{}, nil}
+			command := &cli.Command{
+				Name:   "install",
+				Action: cmdInstall(m.gitRepo, m.langManager),
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "from"},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "install", "--from", lockPath)
+
+			test.init(t, m)
+			err := app.RunContext(ctx, args)
+
+			m.gitRepo.AssertExpectations(t)
+			m.langManager.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestPinCommandVersions(t *testing.T) {
+	cmdPackage := subcommands{
+		Commands: []command{{Name: "echo", Version: "2.0.0"}, {Name: "other", Version: "1.0.0"}},
+		raw:      []byte(`{"commands": [{"name": "Echo", "version": "2.0.0", "bin": "url"}, {"name": "other", "version": "1.0.0"}]}`),
+	}
+
+	require.NoError(t, pinCommandVersions(&cmdPackage, []lockedCommand{{Name: "echo", Version: "1.5.0"}}))
+
+	assert.Equal(t, "1.5.0", cmdPackage.Commands[0].Version)
+	assert.Equal(t, "1.0.0", cmdPackage.Commands[1].Version)
+
+	var raw subcommands
+	require.NoError(t, json.Unmarshal(cmdPackage.raw, &raw))
+	assert.Equal(t, "1.5.0", raw.Commands[0].Version)
+	assert.Equal(t, "url", raw.Commands[0].Bin)
+	assert.Equal(t, "1.0.0", raw.Commands[1].Version)
+}
@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/color"
+	"github.com/akamai/cli/v2/pkg/git"
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/akamai/cli/v2/pkg/tools"
+	"github.com/akamai/cli/v2/pkg/version"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/urfave/cli/v2"
+)
+
+// outdatedExitCode is the exit code of the outdated command when updates are available
+const outdatedExitCode = 2
+
+type (
+	// outdatedCommand compares the installed and the latest version of a package command
+	outdatedCommand struct {
+		Package   string `json:"package" yaml:"package"`
+		Command   string `json:"command" yaml:"command"`
+		Current   string `json:"current" yaml:"current"`
+		Latest    string `json:"latest,omitempty" yaml:"latest,omitempty"`
+		Outdated  bool   `json:"outdated" yaml:"outdated"`
+		Changelog string `json:"changelog,omitempty" yaml:"changelog,omitempty"`
+		Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+	}
+
+	// outdatedOutput is the document written by the outdated command in JSON and YAML output formats
+	outdatedOutput struct {
+		Commands []outdatedCommand `json:"commands" yaml:"commands"`
+	}
+)
+
+func cmdOutdated(gitRepo git.Repository) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		logger := log.FromContext(c.Context)
+		start := time.Now()
+		logger.Debug("OUTDATED START")
+		defer func() {
+			if e == nil {
+				logger.Debug(fmt.Sprintf("OUTDATED FINISH: %v", time.Since(start)))
+			} else {
+				logger.Error(fmt.Sprintf("OUTDATED ERROR: %v", e))
+			}
+		}()
+		term := terminal.Get(c.Context)
+
+		structured := isStructuredOutput(c)
+		if !structured {
+			term.Spinner().Start("Checking installed packages for updates...")
+		}
+		commands := make([]outdatedCommand, 0)
+		for _, dir := range getPackagePaths() {
+			if strings.HasPrefix(filepath.Base(dir), ".") {
+				continue
+			}
+			commands = append(commands, checkOutdatedPackage(c.Context, gitRepo, dir)...)
+		}
+
+		var outdated, failed int
+		for _, cmd := range commands {
+			if cmd.Error != "" {
+				failed++
+			} else if cmd.Outdated {
+				outdated++
+			}
+		}
+
+		if structured {
+			if err := writeOutput(term, outputFormat(c), outdatedOutput{Commands: commands}); err != nil {
+				return err
+			}
+		} else {
+			if failed > 0 {
+				term.Spinner().Warn()
+			} else {
+				term.Spinner().OK()
+			}
+			if err := printOutdated(term, commands, outdated, failed); err != nil {
+				return err
+			}
+		}
+
+		if failed > 0 {
+			return cli.Exit(color.RedString("Unable to check %d command(s) for updates", failed), 1)
+		}
+		if outdated > 0 {
+			return cli.Exit("", outdatedExitCode)
+		}
+		return nil
+	}
+}
+
+// checkOutdatedPackage compares the versions of the commands in the local cli.json of the package in dir with the remote one.
+// Git repositories are fetched, and packages installed as binaries are checked against the cli.json on GitHub.
+func checkOutdatedPackage(ctx context.Context, gitRepo git.Repository, dir string) []outdatedCommand {
+	logger := log.FromContext(ctx)
+	name := filepath.Base(dir)
+
+	local, err := readPackage(dir)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Skipping package %s: %v", name, err))
+		return []outdatedCommand{{Package: name, Error: err.Error()}}
+	}
+
+	remote, repoURL, err := readRemotePackage(ctx, gitRepo, dir)
+	changelog := changelogURL(repoURL)
+	commands := make([]outdatedCommand, 0, len(local.Commands))
+	for _, cmd := range local.Commands {
+		result := outdatedCommand{Package: name, Command: cmd.Name, Current: cmd.Version, Changelog: changelog}
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Unable to check package %s for updates: %v", name, err))
+			result.Error = err.Error()
+		} else {
+			result.Latest = commandVersion(remote, cmd.Name)
+			result.Outdated = isNewerVersion(cmd.Version, result.Latest)
+		}
+		commands = append(commands, result)
+	}
+	return commands
+}
+
+// readRemotePackage returns the latest cli.json of the package in dir, and the repository it was read from
+func readRemotePackage(ctx context.Context, gitRepo git.Repository, dir string) (subcommands, string, error) {
+	logger := log.FromContext(ctx)
+	name := filepath.Base(dir)
+	pin := readPackagePin(dir)
+
+	if err := gitRepo.Open(dir); err != nil {
+		logger.Debug(fmt.Sprintf("Package %s is not a git repository, checking the released cli.json", name))
+		repoURL := tools.Githubize(name)
+		if origin, err := readPackageOrigin(dir); err == nil {
+			repoURL = origin.Repository
+		}
+		owner, repoName := extractOwnerAndRepo(repoURL)
+		if owner == "" || repoName == "" {
+			return subcommands{}, repoURL, fmt.Errorf("unable to parse repository URL: %s", repoURL)
+		}
+		remote, err := fetchPackageConfig(owner, repoName, name, refCandidates(pin))
+		return remote, repoURL, err
+	}
+
+	repoURL, err := gitRepo.RemoteURL(git.DefaultRemoteName)
+	if err != nil {
+		return subcommands{}, "", fmt.Errorf("unable to resolve repository: %w", err)
+	}
+
+	logger.Debug(fmt.Sprintf("Fetching package %s from %s", name, repoURL))
+	if err := gitRepo.Fetch(ctx); err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return subcommands{}, repoURL, err
+	}
+
+	hash, err := resolveRef(gitRepo, pin)
+	if err != nil {
+		return subcommands{}, repoURL, err
+	}
+	data, err := gitRepo.ReadFile(hash, "cli.json")
+	if err != nil {
+		return subcommands{}, repoURL, fmt.Errorf("unable to read cli.json at %s: %w", hash, err)
+	}
+
+	var remote subcommands
+	if err := json.Unmarshal(data, &remote); err != nil {
+		return subcommands{}, repoURL, fmt.Errorf("unable to unmarshal package: %v", err)
+	}
+	for key := range remote.Commands {
+		remote.Commands[key].Name = strings.ToLower(remote.Commands[key].Name)
+	}
+	return remote, repoURL, nil
+}
+
+// isNewerVersion reports whether latest is a newer version than current.
+// Versions which are not semantic versions are only compared for equality.
+func isNewerVersion(current, latest string) bool {
+	if latest == "" {
+		return false
+	}
+	switch version.Compare(current, latest) {
+	case version.Smaller:
+		return true
+	case version.Error:
+		return current != latest
+	default:
+		return false
+	}
+}
+
+// changelogURL returns the releases page of a repository, for repositories hosted on a web server
+func changelogURL(repoURL string) string {
+	repoURL = strings.TrimSuffix(repoURL, ".git")
+	if strings.HasPrefix(repoURL, "git@") {
+		// SCP-style SSH: git@github.com:owner/repo
+		repoURL = "https://" + strings.Replace(strings.TrimPrefix(repoURL, "git@"), ":", "/", 1)
+	}
+
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Host == "" || u.Scheme == "file" {
+		return ""
+	}
+	return fmt.Sprintf("https://%s%s/releases", u.Hostname(), strings.TrimSuffix(u.Path, "/"))
+}
+
+func printOutdated(term terminal.Terminal, commands []outdatedCommand, outdated, failed int) error {
+	for _, cmd := range commands {
+		if cmd.Error != "" {
+			name := cmd.Package
+			if cmd.Command != "" {
+				name = fmt.Sprintf("%s (%s)", cmd.Package, cmd.Command)
+			}
+			term.WriteErrorf("%s", color.YellowString("Unable to check %s for updates: %s\n", name, cmd.Error))
+		}
+	}
+
+	if outdated == 0 {
+		if failed > 0 {
+			return nil
+		}
+		_, err := term.Writeln(color.GreenString("All installed packages are up-to-date"))
+		return err
+	}
+
+	table := &bytes.Buffer{}
+	w := tabwriter.NewWriter(table, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PACKAGE\tCOMMAND\tCURRENT\tLATEST\tCHANGELOG")
+	for _, cmd := range commands {
+		if cmd.Outdated {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", cmd.Package, cmd.Command, cmd.Current, cmd.Latest, cmd.Changelog)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	term.Printf("%s", table.String())
+
+	term.Printf("\nUpdate using \"%s\".\n", color.BlueString("%s update [command]", tools.Self()))
+	return nil
+}
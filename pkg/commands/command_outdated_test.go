@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/git"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCmdOutdated(t *testing.T) {
+	cliHome := t.TempDir()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+	defer func() {
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "testdata"))
+	}()
+	echoDir := filepath.Join(cliHome, ".akamai-cli", "src", "cli-echo")
+	require.NoError(t, os.MkdirAll(echoDir, 0755))
+	cliJSON, err := os.ReadFile(filepath.Join("testdata", ".akamai-cli", "src", "cli-echo", "cli.json"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(echoDir, "cli.json"), cliJSON, 0644))
+
+	remoteJSON := func(version string) []byte {
+		return []byte(fmt.Sprintf(`{"requirements":{"go":"1.14.0"},"commands":[{"name":"echo","aliases":["e"],"version":"%s"}]}`, version))
+	}
+	hash := plumbing.NewHash("b7cb9ee1a7e6a4b3d1c7b9a0b6f2a4d4b1c5e2f3")
+
+	tests := map[string]struct {
+		args       []string
+		init       func(*testing.T, *mocked)
+		teardown   func()
+		withError  string
+		exitCode   int
+		structured bool
+		expected   []outdatedCommand
+	}{
+		"git package with newer version": {
+			init: func(t *testing.T, m *mocked) {
+				m.gitRepo.On("Open", echoDir).Return(nil).Once()
+				m.gitRepo.On("RemoteURL", "origin").Return("git@github.com:akamai/cli-echo.git", nil).Once()
+				m.gitRepo.On("Fetch", mock.Anything).Return(nil).Once()
+				m.gitRepo.On("ResolveRevision", "main").Return(hash, nil).Once()
+				m.gitRepo.On("ReadFile", hash, "cli.json").Return(remoteJSON("1.1.0"), nil).Once()
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Checking installed packages for updates...", []interface{}(nil)).Return().Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Printf", "%s", mock.Anything).Run(func(args mock.Arguments) {
+					table := args.Get(1).([]interface{})[0].(string)
+					assert.Contains(t, table, "PACKAGE")
+					assert.Contains(t, table, "https://github.com/akamai/cli-echo/releases")
+				}).Return().Once()
+				m.term.On("Printf", "\nUpdate using \"%s\".\n", mock.Anything).Return().Once()
+			},
+			exitCode: outdatedExitCode,
+		},
+		"unable to fetch git package": {
+			init: func(t *testing.T, m *mocked) {
+				m.gitRepo.On("Open", echoDir).Return(nil).Once()
+				m.gitRepo.On("RemoteURL", "origin").Return("https://github.com/akamai/cli-echo.git", nil).Once()
+				m.gitRepo.On("Fetch", mock.Anything).Return(errors.New("network unreachable")).Once()
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Checking installed packages for updates...", []interface{}(nil)).Return().Once()
+				m.term.On("Warn").Return().Once()
+				m.term.On("WriteErrorf", "%s", mock.Anything).Return().Once()
+			},
+			withError: "Unable to check 1 command(s) for updates",
+			exitCode:  1,
+		},
+		"binary package up-to-date": {
+			init: func(t *testing.T, m *mocked) {
+				h := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, "/akamai/cli-echo/main/cli.json", r.URL.String())
+					_, err := w.Write(remoteJSON("1.0.0"))
+					assert.NoError(t, err)
+				}))
+				buildRawGitHubURL = func(owner, repo, branch string) string {
+					return fmt.Sprintf("%s/%s/%s/%s/cli.json", h.URL, owner, repo, branch)
+				}
+				m.gitRepo.On("Open", echoDir).Return(git.ErrPackageNotAvailable).Once()
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Checking installed packages for updates...", []interface{}(nil)).Return().Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Writeln", []interface{}{"All installed packages are up-to-date"}).Return(0, nil).Once()
+			},
+			teardown: func() {
+				buildRawGitHubURL = func(owner, repo, branch string) string {
+					return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/cli.json", owner, repo, branch)
+				}
+			},
+		},
+		"binary package from recorded origin": {
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, writePackageOrigin(echoDir, packageOrigin{Repository: "https://github.com/example/cli-echo.git"}))
+				h := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					assert.Equal(t, "/example/cli-echo/main/cli.json", r.URL.String())
+					_, err := w.Write(remoteJSON("1.0.0"))
+					assert.NoError(t, err)
+				}))
+				buildRawGitHubURL = func(owner, repo, branch string) string {
+					return fmt.Sprintf("%s/%s/%s/%s/cli.json", h.URL, owner, repo, branch)
+				}
+				m.gitRepo.On("Open", echoDir).Return(git.ErrPackageNotAvailable).Once()
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", "Checking installed packages for updates...", []interface{}(nil)).Return().Once()
+				m.term.On("OK").Return().Once()
+				m.term.On("Writeln", []interface{}{"All installed packages are up-to-date"}).Return(0, nil).Once()
+			},
+			teardown: func() {
+				buildRawGitHubURL = func(owner, repo, branch string) string {
+					return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/cli.json", owner, repo, branch)
+				}
+				_ = os.Remove(filepath.Join(echoDir, originFileName))
+			},
+		},
+		"binary package with newer version, json output": {
+			args: []string{"--output", "json"},
+			init: func(t *testing.T, m *mocked) {
+				h := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					_, err := w.Write(remoteJSON("2.0.0"))
+					assert.NoError(t, err)
+				}))
+				buildRawGitHubURL = func(owner, repo, branch string) string {
+					return fmt.Sprintf("%s/%s/%s/%s/cli.json", h.URL, owner, repo, branch)
+				}
+				m.gitRepo.On("Open", echoDir).Return(git.ErrPackageNotAvailable).Once()
+			},
+			teardown: func() {
+				buildRawGitHubURL = func(owner, repo, branch string) string {
+					return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/cli.json", owner, repo, branch)
+				}
+			},
+			exitCode:   outdatedExitCode,
+			structured: true,
+			expected: []outdatedCommand{
+				{Package: "cli-echo", Command: "echo", Current: "1.0.0", Latest: "2.0.0", Outdated: true,
+					Changelog: "https://github.com/akamai/cli-echo/releases"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.MockRepo{}, nil, nil}
+			var output string
+			if test.structured {
+				m.term.On("Printf", "%s\n", mock.Anything).Run(func(args mock.Arguments) {
+					output = args.Get(1).([]interface{})[0].(string)
+				}).Return().Once()
+			}
+
+			command := &cli.Command{
+				Name:   "outdated",
+				Action: cmdOutdated(m.gitRepo),
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, test.args...)
+			args = append(args, "outdated")
+
+			test.init(t, m)
+			err := app.RunContext(ctx, args)
+			if test.teardown != nil {
+				test.teardown()
+			}
+
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			if test.exitCode == 0 {
+				require.NoError(t, err)
+			} else {
+				var exitErr cli.ExitCoder
+				require.True(t, errors.As(err, &exitErr))
+				assert.Equal(t, test.exitCode, exitErr.ExitCode())
+				assert.Contains(t, err.Error(), test.withError)
+			}
+			if test.structured {
+				var out outdatedOutput
+				require.NoError(t, json.Unmarshal([]byte(output), &out))
+				assert.Equal(t, test.expected, out.Commands)
+			}
+		})
+	}
+}
+
+func TestIsNewerVersion(t *testing.T) {
+	tests := map[string]struct {
+		current, latest string
+		expected        bool
+	}{
+		"newer":            {current: "1.0.0", latest: "1.1.0", expected: true},
+		"same":             {current: "1.0.0", latest: "1.0.0"},
+		"older":            {current: "2.0.0", latest: "1.1.0"},
+		"no latest":        {current: "1.0.0"},
+		"not semver, same": {current: "nightly", latest: "nightly"},
+		"not semver":       {current: "nightly", latest: "1.0.0", expected: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, isNewerVersion(test.current, test.latest))
+		})
+	}
+}
+
+func TestChangelogURL(t *testing.T) {
+	tests := map[string]struct {
+		repoURL  string
+		expected string
+	}{
+		"https":   {repoURL: "https://github.com/akamai/cli-echo.git", expected: "https://github.com/akamai/cli-echo/releases"},
+		"ssh":     {repoURL: "git@github.com:akamai/cli-echo.git", expected: "https://github.com/akamai/cli-echo/releases"},
+		"ssh url": {repoURL: "ssh://git@git.example.com:2222/tools/cli-echo", expected: "https://git.example.com/tools/cli-echo/releases"},
+		"file":    {repoURL: "file:///tmp/cli-echo", expected: ""},
+		"local":   {repoURL: "/tmp/cli-echo", expected: ""},
+		"empty":   {repoURL: "", expected: ""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, changelogURL(test.repoURL))
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/color"
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/urfave/cli/v2"
+)
+
+type profileInfo struct {
+	Name   string `json:"name" yaml:"name"`
+	Active bool   `json:"active" yaml:"active"`
+}
+
+func cmdProfileList(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("PROFILE LIST START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("PROFILE LIST FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("PROFILE LIST ERROR: %v", e))
+		}
+	}()
+	cfg := config.Get(c.Context)
+	term := terminal.Get(c.Context)
+
+	active := cfg.Profile()
+	profiles := make([]profileInfo, 0)
+	for _, name := range cfg.Profiles() {
+		profiles = append(profiles, profileInfo{Name: name, Active: name == active})
+	}
+	if isStructuredOutput(c) {
+		return writeOutput(term, outputFormat(c), profiles)
+	}
+
+	if len(profiles) == 0 {
+		term.Printf("No profiles defined, create one with 'akamai profile create <name>'.\n")
+		return nil
+	}
+	for _, p := range profiles {
+		if p.Active {
+			term.Printf("* %s\n", color.GreenString("%s", p.Name))
+			continue
+		}
+		term.Printf("  %s\n", p.Name)
+	}
+	return nil
+}
+
+func cmdProfileUse(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("PROFILE USE START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("PROFILE USE FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("PROFILE USE ERROR: %v", e))
+		}
+	}()
+	cfg := config.Get(c.Context)
+
+	name := c.Args().First()
+	if name == "" {
+		return cli.Exit(color.RedString("Unable to use profile: profile name has to be provided"), 1)
+	}
+	if err := cfg.UseProfile(name); err != nil {
+		logger.Error(fmt.Sprintf("Error using profile: %v", err))
+		return cli.Exit(color.RedString("Unable to use profile: %v", err), 1)
+	}
+	cfg.SetValue("cli", "profile", name)
+	if err := cfg.Save(c.Context); err != nil {
+		logger.Error(fmt.Sprintf("Error saving config: %v", err))
+		return cli.Exit(color.RedString("Unable to use profile: %v", err), 1)
+	}
+	terminal.Get(c.Context).Printf("Using profile %s by default.\n", name)
+	return nil
+}
+
+func cmdProfileCreate(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("PROFILE CREATE START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("PROFILE CREATE FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("PROFILE CREATE ERROR: %v", e))
+		}
+	}()
+	cfg := config.Get(c.Context)
+
+	name := c.Args().First()
+	if name == "" {
+		return cli.Exit(color.RedString("Unable to create profile: profile name has to be provided"), 1)
+	}
+	if err := cfg.CreateProfile(name); err != nil {
+		logger.Error(fmt.Sprintf("Error creating profile: %v", err))
+		return cli.Exit(color.RedString("Unable to create profile: %v", err), 1)
+	}
+	if err := cfg.Save(c.Context); err != nil {
+		logger.Error(fmt.Sprintf("Error saving config: %v", err))
+		return cli.Exit(color.RedString("Unable to create profile: %v", err), 1)
+	}
+	terminal.Get(c.Context).Printf("Created profile %s, add settings with 'akamai config set --profile %s <setting> <value>'.\n", name, name)
+	return nil
+}
+
+func cmdProfileDelete(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("PROFILE DELETE START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("PROFILE DELETE FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("PROFILE DELETE ERROR: %v", e))
+		}
+	}()
+	cfg := config.Get(c.Context)
+
+	name := c.Args().First()
+	if name == "" {
+		return cli.Exit(color.RedString("Unable to delete profile: profile name has to be provided"), 1)
+	}
+	if err := cfg.DeleteProfile(name); err != nil {
+		logger.Error(fmt.Sprintf("Error deleting profile: %v", err))
+		return cli.Exit(color.RedString("Unable to delete profile: %v", err), 1)
+	}
+	if err := cfg.Save(c.Context); err != nil {
+		logger.Error(fmt.Sprintf("Error saving config: %v", err))
+		return cli.Exit(color.RedString("Unable to delete profile: %v", err), 1)
+	}
+	terminal.Get(c.Context).Printf("Deleted profile %s.\n", name)
+	return nil
+}
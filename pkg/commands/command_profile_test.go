@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCmdProfile(t *testing.T) {
+	tests := map[string]struct {
+		args       []string
+		globalArgs []string
+		init       func(*mocked)
+		withError  string
+	}{
+		"list profiles": {
+			args: []string{"list"},
+			init: func(m *mocked) {
+				m.cfg.On("Profile").Return("prod").Once()
+				m.cfg.On("Profiles").Return([]string{"prod", "staging"}).Once()
+				m.term.On("Printf", "* %s\n", []interface{}{"prod"}).Return().Once()
+				m.term.On("Printf", "  %s\n", []interface{}{"staging"}).Return().Once()
+			},
+		},
+		"list profiles as json": {
+			args:       []string{"list"},
+			globalArgs: []string{"--output", "json"},
+			init: func(m *mocked) {
+				m.cfg.On("Profile").Return("").Once()
+				m.cfg.On("Profiles").Return([]string{"prod"}).Once()
+				m.term.On("Printf", "%s\n", []interface{}{`[
+  {
+    "name": "prod",
+    "active": false
+  }
+]`}).Return().Once()
+			},
+		},
+		"list without profiles": {
+			args: []string{"list"},
+			init: func(m *mocked) {
+				m.cfg.On("Profile").Return("").Once()
+				m.cfg.On("Profiles").Return(nil).Once()
+				m.term.On("Printf", "No profiles defined, create one with 'akamai profile create <name>'.\n", []interface{}(nil)).Return().Once()
+			},
+		},
+		"use profile": {
+			args: []string{"use", "prod"},
+			init: func(m *mocked) {
+				m.cfg.On("UseProfile", "prod").Return(nil).Once()
+				m.cfg.On("SetValue", "cli", "profile", "prod").Return().Once()
+				m.cfg.On("Save").Return(nil).Once()
+				m.term.On("Printf", "Using profile %s by default.\n", []interface{}{"prod"}).Return().Once()
+			},
+		},
+		"use unknown profile": {
+			args: []string{"use", "dev"},
+			init: func(m *mocked) {
+				m.cfg.On("UseProfile", "dev").Return(fmt.Errorf("%w: dev", config.ErrUnknownProfile)).Once()
+			},
+			withError: "Unable to use profile: unknown profile: dev",
+		},
+		"create profile": {
+			args: []string{"create", "dev"},
+			init: func(m *mocked) {
+				m.cfg.On("CreateProfile", "dev").Return(nil).Once()
+				m.cfg.On("Save").Return(nil).Once()
+				m.term.On("Printf", "Created profile %s, add settings with 'akamai config set --profile %s <setting> <value>'.\n", []interface{}{"dev", "dev"}).Return().Once()
+			},
+		},
+		"create existing profile": {
+			args: []string{"create", "prod"},
+			init: func(m *mocked) {
+				m.cfg.On("CreateProfile", "prod").Return(fmt.Errorf("%w: prod", config.ErrProfileExists)).Once()
+			},
+			withError: "Unable to create profile: profile already exists: prod",
+		},
+		"create without name": {
+			args:      []string{"create"},
+			init:      func(_ *mocked) {},
+			withError: "Unable to create profile: profile name has to be provided",
+		},
+		"delete profile": {
+			args: []string{"delete", "staging"},
+			init: func(m *mocked) {
+				m.cfg.On("DeleteProfile", "staging").Return(nil).Once()
+				m.cfg.On("Save").Return(nil).Once()
+				m.term.On("Printf", "Deleted profile %s.\n", []interface{}{"staging"}).Return().Once()
+			},
+		},
+		"error saving deleted profile": {
+			args: []string{"delete", "staging"},
+			init: func(m *mocked) {
+				m.cfg.On("DeleteProfile", "staging").Return(nil).Once()
+				m.cfg.On("Save").Return(fmt.Errorf("save error")).Once()
+			},
+			withError: "Unable to delete profile: save error",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil, nil}
+			command := &cli.Command{
+				Name: "profile",
+				Subcommands: []*cli.Command{
+					{Name: "create", Action: cmdProfileCreate},
+					{Name: "delete", Action: cmdProfileDelete},
+					{Name: "list", Action: cmdProfileList},
+					{Name: "use", Action: cmdProfileUse},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, test.globalArgs...)
+			args = append(args, "profile")
+			args = append(args, test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/color"
+	"github.com/akamai/cli/v2/pkg/git"
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/packages"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/akamai/cli/v2/pkg/tools"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/urfave/cli/v2"
+)
+
+func cmdRollback(gitRepo git.Repository, langManager packages.LangManager) cli.ActionFunc {
+	return func(c *cli.Context) (e error) {
+		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+		logger := log.FromContext(c.Context)
+		start := time.Now()
+		logger.Debug("ROLLBACK START")
+		defer func() {
+			if e == nil {
+				logger.Debug(fmt.Sprintf("ROLLBACK FINISH: %v", time.Since(start)))
+			} else {
+				logger.Error(fmt.Sprintf("ROLLBACK ERROR: %v", e))
+			}
+		}()
+
+		if c.NArg() != 1 {
+			return cli.Exit(color.RedString("You must specify exactly one command to roll back"), 1)
+		}
+
+		return rollbackPackage(c.Context, gitRepo, langManager, logger, c.Args().First(), c.String("to"))
+	}
+}
+
+func rollbackPackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, logger *slog.Logger, cmd, version string) error {
+	term := terminal.Get(ctx)
+
+	exec, _, err := findExec(ctx, langManager, cmd)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Command \"%s\" not found: %v", cmd, err))
+		return cli.Exit(color.RedString("Command \"%s\" not found. Try \"%s help\".\n", cmd, tools.Self()), 1)
+	}
+
+	repoDir := findPackageDir(filepath.Dir(exec[len(exec)-1]))
+	if repoDir == "" {
+		logger.Error("Unable to find package directory")
+		return cli.Exit(color.RedString("unable to roll back, was it installed using %s", color.CyanString("\"akamai install\"")+"?"), 1)
+	}
+	pkgName := filepath.Base(repoDir)
+
+	history, err := readPackageHistory(pkgName)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Unable to read package history: %v", err))
+		return cli.Exit(color.RedString("Unable to read package history: %v", err), 1)
+	}
+
+	idx := selectHistoryEntry(history, version)
+	if idx == -1 {
+		msg := fmt.Sprintf("No previous install of command \"%s\" found", cmd)
+		if version != "" {
+			msg = fmt.Sprintf("No previous install of command \"%s\" with version %s found", cmd, version)
+		}
+		logger.Error(msg)
+		return cli.Exit(color.RedString("%s", msg), 1)
+	}
+	entry := history.Entries[idx]
+
+	term.Spinner().Start("Rolling back \"%s\" command...", cmd)
+
+	if err := restorePackage(ctx, gitRepo, langManager, logger, repoDir, entry); err != nil {
+		term.Spinner().Fail()
+		logger.Error(fmt.Sprintf("Unable to roll back: %v", err))
+		return cli.Exit(color.RedString("Unable to roll back: %v", err), 1)
+	}
+
+	historyPath, err := getPackageHistoryPath(pkgName)
+	if err != nil {
+		term.Spinner().Fail()
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(historyPath, entry.ID)); err != nil {
+		logger.Warn(fmt.Sprintf("Unable to remove restored history entry: %v", err))
+	}
+	history.Entries = append(history.Entries[:idx], history.Entries[idx+1:]...)
+	if err := writePackageHistory(pkgName, history); err != nil {
+		logger.Warn(fmt.Sprintf("Unable to save package history: %v", err))
+	}
+
+	term.Spinner().OK()
+	logger.Debug(fmt.Sprintf("Rolled back package %s to history entry %s", pkgName, entry.ID))
+
+	return nil
+}
+
+// restorePackage replaces the installed package and its virtualenv with a history entry.
+// If any step fails, the package is left as it was before the rollback.
+func restorePackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, logger *slog.Logger, repoDir string, entry historyEntry) error {
+	pkgName := filepath.Base(repoDir)
+	historyPath, err := getPackageHistoryPath(pkgName)
+	if err != nil {
+		return err
+	}
+	entryDir := filepath.Join(historyPath, entry.ID)
+
+	venvPath, err := tools.GetPkgVenvPath(pkgName)
+	if err != nil {
+		return err
+	}
+
+	var venvAside string
+	if entry.Venv {
+		logger.Debug(fmt.Sprintf("Restoring virtualenv %s", venvPath))
+		if venvAside, err = swapDir(venvPath, filepath.Join(entryDir, historyVenvDir)); err != nil {
+			return fmt.Errorf("unable to restore virtualenv: %w", err)
+		}
+	}
+	revertVenv := func() {
+		if !entry.Venv {
+			return
+		}
+		if err := revertSwap(venvPath, filepath.Join(entryDir, historyVenvDir), venvAside); err != nil {
+			logger.Error(fmt.Sprintf("Unable to revert virtualenv: %v", err))
+		}
+	}
+
+	var treeAside string
+	if entry.Tree {
+		logger.Debug(fmt.Sprintf("Restoring package tree %s", repoDir))
+		if treeAside, err = swapDir(repoDir, filepath.Join(entryDir, historyTreeDir)); err != nil {
+			revertVenv()
+			return fmt.Errorf("unable to restore package: %w", err)
+		}
+	} else if err := checkoutHistoryCommit(ctx, gitRepo, langManager, logger, repoDir, entry); err != nil {
+		revertVenv()
+		return err
+	}
+
+	for _, dir := range []string{venvAside, treeAside} {
+		if dir == "" {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			logger.Warn(fmt.Sprintf("Unable to remove directory %s: %v", dir, err))
+		}
+	}
+
+	return nil
+}
+
+func checkoutHistoryCommit(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, logger *slog.Logger, repoDir string, entry historyEntry) error {
+	if err := gitRepo.Open(repoDir); err != nil {
+		return fmt.Errorf("unable to open package repository: %w", err)
+	}
+	head, err := gitRepo.Head()
+	if err != nil {
+		return err
+	}
+
+	logger.Debug(fmt.Sprintf("Checking out commit %s", entry.Commit))
+	if err := gitRepo.Checkout(&gogit.CheckoutOptions{Hash: plumbing.NewHash(entry.Commit), Force: true}); err != nil {
+		return err
+	}
+
+	// a restored virtualenv already contains the dependencies of the restored revision
+	if entry.Venv {
+		return nil
+	}
+
+	if ok, _ := installPackageDependencies(ctx, langManager, repoDir, logger); !ok {
+		if err := gitRepo.Checkout(&gogit.CheckoutOptions{Hash: head.Hash(), Force: true}); err != nil {
+			logger.Error(fmt.Sprintf("Unable to revert to commit %s: %v", head.Hash().String(), err))
+		}
+		return fmt.Errorf("unable to install dependencies of commit %s", entry.Commit)
+	}
+
+	return nil
+}
+
+// swapDir moves replacement into the place of target and returns the path where the previous target was kept
+func swapDir(target, replacement string) (string, error) {
+	aside := filepath.Join(filepath.Dir(target), rollbackAsidePrefix+filepath.Base(target))
+	if err := os.RemoveAll(aside); err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(target); err == nil {
+		if err := os.Rename(target, aside); err != nil {
+			return "", err
+		}
+	} else {
+		aside = ""
+	}
+
+	if err := os.Rename(replacement, target); err != nil {
+		if aside != "" {
+			_ = os.Rename(aside, target)
+		}
+		return "", err
+	}
+
+	return aside, nil
+}
+
+// revertSwap undoes swapDir
+func revertSwap(target, replacement, aside string) error {
+	if err := os.Rename(target, replacement); err != nil {
+		return err
+	}
+	if aside == "" {
+		return nil
+	}
+	return os.Rename(aside, target)
+}
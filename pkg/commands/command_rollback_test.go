@@ -0,0 +1,252 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/git"
+	"github.com/akamai/cli/v2/pkg/packages"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/akamai/cli/v2/pkg/tools"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCmdRollback(t *testing.T) {
+	cliEchoRepo := filepath.Join("testdata", ".akamai-cli", "src", "cli-echo")
+	cliEchoBin := filepath.Join("testdata", ".akamai-cli", "src", "cli-echo", "bin", "akamai-echo")
+	historyDir := filepath.Join("testdata", ".akamai-cli", "history")
+	backupDir := filepath.Join("testdata", "temp")
+	oldTreeDir := filepath.Join("testdata", "temp-tree")
+	prevHash := plumbing.NewHash("0123456789abcdef0123456789abcdef01234567")
+	headHash := plumbing.NewHash("89abcdef0123456789abcdef0123456789abcdef")
+
+	recordTree := func(t *testing.T) {
+		require.NoError(t, tools.CopyDir(cliEchoRepo, oldTreeDir))
+		cliJSON, err := os.ReadFile(filepath.Join(oldTreeDir, "cli.json"))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(oldTreeDir, "cli.json"), []byte(strings.ReplaceAll(string(cliJSON), "1.0.0", "0.9.0")), 0644))
+		pkg := subcommands{Commands: []command{{Name: "echo", Version: "0.9.0"}}}
+		require.NoError(t, recordPackageHistory(context.Background(), "cli-echo", pkg, "", oldTreeDir, ""))
+	}
+	recordCommit := func(t *testing.T) {
+		pkg := subcommands{Commands: []command{{Name: "echo", Version: "0.9.0"}}}
+		require.NoError(t, recordPackageHistory(context.Background(), "cli-echo", pkg, prevHash.String(), "", ""))
+	}
+
+	tests := map[string]struct {
+		args            []string
+		init            func(*testing.T, *mocked)
+		expectedVersion string
+		withError       string
+	}{
+		"roll back binary package": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				recordTree(t)
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, cliEchoBin).Return([]string{cliEchoBin}, nil).Once()
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", `Rolling back "%s" command...`, []interface{}{"echo"}).Return().Once()
+				m.term.On("OK").Return().Once()
+			},
+			expectedVersion: "0.9.0",
+		},
+		"roll back source package": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				recordCommit(t)
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, cliEchoBin).Return([]string{cliEchoBin}, nil).Once()
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", `Rolling back "%s" command...`, []interface{}{"echo"}).Return().Once()
+				m.gitRepo.On("Open", cliEchoRepo).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", headHash), nil).Once()
+				m.gitRepo.On("Checkout", &gogit.CheckoutOptions{Hash: prevHash, Force: true}).Return(nil).Once()
+				m.term.On("Start", "Installing Dependencies...", []interface{}(nil)).Return().Once()
+				m.langManager.On("Install", cliEchoRepo,
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"echo"}, []string{""}).Return(nil).Once()
+				m.term.On("OK").Return().Twice()
+			},
+			expectedVersion: "1.0.0",
+		},
+		"dependencies of previous commit fail to install": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				recordCommit(t)
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, cliEchoBin).Return([]string{cliEchoBin}, nil).Once()
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", `Rolling back "%s" command...`, []interface{}{"echo"}).Return().Once()
+				m.gitRepo.On("Open", cliEchoRepo).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", headHash), nil).Once()
+				m.gitRepo.On("Checkout", &gogit.CheckoutOptions{Hash: prevHash, Force: true}).Return(nil).Once()
+				m.term.On("Start", "Installing Dependencies...", []interface{}(nil)).Return().Once()
+				m.langManager.On("Install", cliEchoRepo,
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"echo"}, []string{""}).Return(errors.New("oops")).Once()
+				m.term.On("Stop", terminal.SpinnerStatusFail).Return().Once()
+				m.term.On("WriteError", "oops").Return(0, nil).Once()
+				m.gitRepo.On("Checkout", &gogit.CheckoutOptions{Hash: headHash, Force: true}).Return(nil).Once()
+				m.term.On("Fail").Return().Once()
+			},
+			withError: "Unable to roll back: unable to install dependencies of commit " + prevHash.String(),
+		},
+		"roll back to specific version": {
+			args: []string{"--to", "0.9.0", "echo"},
+			init: func(t *testing.T, m *mocked) {
+				recordTree(t)
+				recordCommit(t)
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, cliEchoBin).Return([]string{cliEchoBin}, nil).Once()
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", `Rolling back "%s" command...`, []interface{}{"echo"}).Return().Once()
+				m.gitRepo.On("Open", cliEchoRepo).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", headHash), nil).Once()
+				m.gitRepo.On("Checkout", &gogit.CheckoutOptions{Hash: prevHash, Force: true}).Return(nil).Once()
+				m.term.On("Start", "Installing Dependencies...", []interface{}(nil)).Return().Once()
+				m.langManager.On("Install", cliEchoRepo,
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"echo"}, []string{""}).Return(nil).Once()
+				m.term.On("OK").Return().Twice()
+			},
+			expectedVersion: "1.0.0",
+		},
+		"no previous install": {
+			args: []string{"echo"},
+			init: func(_ *testing.T, m *mocked) {
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, cliEchoBin).Return([]string{cliEchoBin}, nil).Once()
+			},
+			withError: `No previous install of command "echo" found`,
+		},
+		"no previous install with version": {
+			args: []string{"--to", "2.0.0", "echo"},
+			init: func(t *testing.T, m *mocked) {
+				recordCommit(t)
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, cliEchoBin).Return([]string{cliEchoBin}, nil).Once()
+			},
+			withError: `No previous install of command "echo" with version 2.0.0 found`,
+		},
+		"missing command name": {
+			args:      []string{},
+			init:      func(_ *testing.T, _ *mocked) {},
+			withError: "You must specify exactly one command to roll back",
+		},
+		"error finding executable": {
+			args:      []string{"not-found"},
+			init:      func(_ *testing.T, _ *mocked) {},
+			withError: fmt.Sprintf("Command \"not-found\" not found. Try \"%s help\".\n", tools.Self()),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			require.NoError(t, tools.CopyDir(cliEchoRepo, backupDir))
+			defer func() {
+				require.NoError(t, os.RemoveAll(cliEchoRepo))
+				require.NoError(t, os.Rename(backupDir, cliEchoRepo))
+				require.NoError(t, os.RemoveAll(oldTreeDir))
+				require.NoError(t, os.RemoveAll(historyDir))
+			}()
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.MockRepo{}, &packages.Mock{}, nil}
+			command := &cli.Command{
+				Name:   "rollback",
+				Action: cmdRollback(m.gitRepo, m.langManager),
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "to"},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			args := os.Args[0:1]
+			args = append(args, "rollback")
+			args = append(args, test.args...)
+
+			test.init(t, m)
+			err := app.RunContext(ctx, args)
+
+			m.term.AssertExpectations(t)
+			m.gitRepo.AssertExpectations(t)
+			m.langManager.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+
+			pkg, err := readPackage(cliEchoRepo)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedVersion, pkg.Commands[0].Version)
+
+			history, err := readPackageHistory("cli-echo")
+			require.NoError(t, err)
+			for _, entry := range history.Entries {
+				assert.NotEqual(t, test.expectedVersion, entry.Commands[0].Version)
+			}
+		})
+	}
+}
+
+func TestRecordPackageHistory(t *testing.T) {
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+	defer func() {
+		require.NoError(t, os.RemoveAll(filepath.Join("testdata", ".akamai-cli", "history")))
+	}()
+
+	for i := 0; i < packageHistoryLimit+2; i++ {
+		pkg := subcommands{Commands: []command{{Name: "echo", Version: fmt.Sprintf("1.%d.0", i)}}}
+		require.NoError(t, recordPackageHistory(context.Background(), "cli-echo", pkg, plumbing.Hash{byte(i)}.String(), "", ""))
+	}
+
+	history, err := readPackageHistory("cli-echo")
+	require.NoError(t, err)
+	require.Len(t, history.Entries, packageHistoryLimit)
+	assert.Equal(t, "1.2.0", history.Entries[0].Commands[0].Version)
+	assert.Equal(t, "1.4.0", history.Entries[packageHistoryLimit-1].Commands[0].Version)
+
+	assert.Equal(t, 2, selectHistoryEntry(history, ""))
+	assert.Equal(t, 1, selectHistoryEntry(history, "v1.3.0"))
+	assert.Equal(t, -1, selectHistoryEntry(history, "1.0.0"))
+
+	entries, err := os.ReadDir(filepath.Join("testdata", ".akamai-cli", "history", "cli-echo"))
+	require.NoError(t, err)
+	assert.Len(t, entries, packageHistoryLimit+1)
+}
+
+func TestRecordPackageHistoryVenv(t *testing.T) {
+	cliHome := t.TempDir()
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+	defer func() {
+		require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", filepath.Join(".", "testdata")))
+	}()
+	venvPath := filepath.Join(cliHome, ".akamai-cli", "venv", "cli-echo")
+	require.NoError(t, os.MkdirAll(venvPath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(venvPath, "version"), []byte("current"), 0644))
+	pkg := subcommands{Commands: []command{{Name: "echo", Version: "1.0.0"}}}
+
+	require.NoError(t, recordPackageHistory(context.Background(), "cli-echo", pkg, "", "", ""))
+
+	snapshot := filepath.Join(t.TempDir(), "venv")
+	require.NoError(t, os.MkdirAll(snapshot, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(snapshot, "version"), []byte("snapshot"), 0644))
+	require.NoError(t, recordPackageHistory(context.Background(), "cli-echo", pkg, "", "", snapshot))
+
+	history, err := readPackageHistory("cli-echo")
+	require.NoError(t, err)
+	require.Len(t, history.Entries, 2)
+	for i, expected := range []string{"current", "snapshot"} {
+		assert.True(t, history.Entries[i].Venv)
+		data, err := os.ReadFile(filepath.Join(cliHome, ".akamai-cli", "history", "cli-echo", history.Entries[i].ID, historyVenvDir, "version"))
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(data))
+	}
+	_, err = os.Stat(snapshot)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(venvPath)
+	assert.NoError(t, err)
+}
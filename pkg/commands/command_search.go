@@ -21,12 +21,36 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
+type (
+	// searchOutput is the document written by the search command in JSON and YAML output formats
+	searchOutput struct {
+		Results []searchResultOutput `json:"results" yaml:"results"`
+	}
+
+	searchResultOutput struct {
+		Name     string                `json:"name" yaml:"name"`
+		Title    string                `json:"title" yaml:"title"`
+		Score    int                   `json:"score" yaml:"score"`
+		URL      string                `json:"url,omitempty" yaml:"url,omitempty"`
+		Registry string                `json:"registry,omitempty" yaml:"registry,omitempty"`
+		Commands []searchCommandOutput `json:"commands" yaml:"commands"`
+	}
+
+	searchCommandOutput struct {
+		Name             string   `json:"name" yaml:"name"`
+		Aliases          []string `json:"aliases" yaml:"aliases"`
+		Description      string   `json:"description,omitempty" yaml:"description,omitempty"`
+		AvailableVersion string   `json:"available-version" yaml:"available-version"`
+		InstalledVersion string   `json:"installed-version,omitempty" yaml:"installed-version,omitempty"`
+	}
+)
+
 var (
 	githubURLTemplate = "https://raw.githubusercontent.com/akamai/%s/master/cli.json"
 )
 
 func cmdSearch(c *cli.Context) (e error) {
-	pr := newPackageReader(embeddedPackages)
+	pr := newRegistryReader(c.Context)
 	return cmdSearchWithPackageReader(c, pr)
 }
 
@@ -53,7 +77,11 @@ func cmdSearchWithPackageReader(c *cli.Context, pr packageReader) (e error) {
 		return cli.Exit(color.RedString("%s", err.Error()), 1)
 	}
 
-	err = searchPackages(c.Context, c.Args().Slice(), packages)
+	if isStructuredOutput(c) {
+		err = writeSearchOutput(c, c.Args().Slice(), packages)
+	} else {
+		err = searchPackages(c.Context, c.Args().Slice(), packages)
+	}
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to search packages: %v", err))
 		return cli.Exit(color.RedString("%s", err.Error()), 1)
@@ -63,9 +91,18 @@ func cmdSearchWithPackageReader(c *cli.Context, pr packageReader) (e error) {
 }
 
 func searchPackages(ctx context.Context, keywords []string, packageList *packageList) error {
-	results := make(map[int]map[string]packageListItem)
-
 	term := terminal.Get(ctx)
+	resultHits, resultPkgs, results := matchPackages(keywords, packageList)
+
+	term.Printf(color.YellowString("Results Found:")+" %d\n\n", len(resultPkgs))
+
+	return printResult(resultHits, resultPkgs, results, term)
+}
+
+// matchPackages scores the packages matching keywords. It returns the scores in descending order,
+// the sorted names of matching packages, and the matching packages by score.
+func matchPackages(keywords []string, packageList *packageList) ([]int, []string, map[int]map[string]packageListItem) {
+	results := make(map[int]map[string]packageListItem)
 
 	var hits int
 	for key, pkg := range packageList.Packages {
@@ -131,9 +168,53 @@ func searchPackages(ctx context.Context, keywords []string, packageList *package
 	sort.Sort(sort.Reverse(sort.IntSlice(resultHits)))
 	sort.Strings(resultPkgs)
 
-	term.Printf(color.YellowString("Results Found:")+" %d\n\n", len(resultPkgs))
+	return resultHits, resultPkgs, results
+}
 
-	return printResult(resultHits, resultPkgs, results, term)
+func writeSearchOutput(c *cli.Context, keywords []string, packageList *packageList) error {
+	resultHits, resultPkgs, results := matchPackages(keywords, packageList)
+
+	out := searchOutput{Results: make([]searchResultOutput, 0, len(resultPkgs))}
+	for _, hits := range resultHits {
+		for _, pkgName := range resultPkgs {
+			pkg, ok := results[hits][pkgName]
+			if !ok {
+				continue
+			}
+			installedVersion, err := getVersionFromSystem(pkg.Name)
+			if err != nil {
+				return err
+			}
+			result := searchResultOutput{
+				Name:     pkg.Name,
+				Title:    pkg.Title,
+				Score:    hits,
+				URL:      pkg.URL,
+				Registry: pkg.Source,
+				Commands: make([]searchCommandOutput, 0, len(pkg.Commands)),
+			}
+			for _, cmd := range pkg.Commands {
+				availableVersion, err := getAvailableVersion(pkg, cmd)
+				if err != nil {
+					return err
+				}
+				aliases := cmd.Aliases
+				if aliases == nil {
+					aliases = []string{}
+				}
+				result.Commands = append(result.Commands, searchCommandOutput{
+					Name:             cmd.Name,
+					Aliases:          aliases,
+					Description:      cmd.Description,
+					AvailableVersion: availableVersion,
+					InstalledVersion: installedVersion,
+				})
+			}
+			out.Results = append(out.Results, result)
+		}
+	}
+
+	return writeOutput(terminal.Get(c.Context), outputFormat(c), out)
 }
 
 func printResult(resultHits []int, resultPkgs []string, results map[int]map[string]packageListItem, term terminal.Terminal) error {
@@ -142,7 +223,11 @@ func printResult(resultHits []int, resultPkgs []string, results map[int]map[stri
 		for _, pkgName := range resultPkgs {
 			if _, ok := results[hits][pkgName]; ok {
 				pkg := results[hits][pkgName]
-				term.Printf(color.GreenString("Package: ")+"%s [%s]\n", pkg.Title, color.BlueString("%s", pkg.Name))
+				if pkg.Source != "" {
+					term.Printf(color.GreenString("Package: ")+"%s [%s] (registry: %s)\n", pkg.Title, color.BlueString("%s", pkg.Name), pkg.Source)
+				} else {
+					term.Printf(color.GreenString("Package: ")+"%s [%s]\n", pkg.Title, color.BlueString("%s", pkg.Name))
+				}
 				for _, cmd := range pkg.Commands {
 					var aliases string
 					if len(cmd.Aliases) == 1 {
@@ -152,10 +237,8 @@ func printResult(resultHits []int, resultPkgs []string, results map[int]map[stri
 					}
 					term.Printf(color.BoldString("  Command:")+" %s %s\n", cmd.Name, aliases)
 
-					url := pkg.URL
 					var err error
-					availableVersion, err = getLatestVersion(url)
-					if err != nil {
+					if availableVersion, err = getAvailableVersion(pkg, cmd); err != nil {
 						return cli.Exit(color.RedString("%s", err.Error()), 1)
 					}
 					term.Printf(color.BoldString("  Available Version:")+" %s\n", availableVersion)
@@ -184,6 +267,15 @@ func printResult(resultHits []int, resultPkgs []string, results map[int]map[stri
 	return nil
 }
 
+// getAvailableVersion returns the latest version of a package command
+func getAvailableVersion(pkg packageListItem, cmd command) (string, error) {
+	if pkg.Source != "" && cmd.Version != "" {
+		// registries other than the built-in one publish the versions of their packages
+		return cmd.Version, nil
+	}
+	return getLatestVersion(pkg.URL)
+}
+
 func getLatestVersion(s string) (string, error) {
 
 	u, err := url.Parse(s)
@@ -238,7 +330,7 @@ type CommandObject struct {
 }
 
 func getVersionFromSystem(command string) (string, error) {
-	paths := filepath.SplitList(getPackageBinPaths())
+	paths := getPackageBinDirs()
 	suffix := "cli-" + command
 	finalPath := ""
 	for _, path := range paths {
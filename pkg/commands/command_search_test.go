@@ -21,10 +21,11 @@ import (
 
 func TestCmdSearch(t *testing.T) {
 	tests := map[string]struct {
-		args      []string
-		init      func(*mocked)
-		packages  *packageList
-		withError string
+		args       []string
+		globalArgs []string
+		init       func(*mocked)
+		packages   *packageList
+		withError  string
 	}{
 		"search and find single package - sample when package is not installed": {
 			args: []string{"sample"},
@@ -47,6 +48,34 @@ func TestCmdSearch(t *testing.T) {
 			},
 			packages: packagesForTest,
 		},
+		"search and find single package as json": {
+			args:       []string{"echo-uninstall"},
+			globalArgs: []string{"--output", "json"},
+			init: func(m *mocked) {
+				h := mockedServer("echo-uninstall", "2.0.0", t)
+				githubURLTemplate = h.URL + "/akamai/%s/master/cli.json"
+
+				m.term.On("Printf", "%s\n", []interface{}{`{
+  "results": [
+    {
+      "name": "echo",
+      "title": "echo",
+      "score": 30,
+      "commands": [
+        {
+          "name": "echo-uninstall",
+          "aliases": [],
+          "description": "test for single match",
+          "available-version": "2.0.0",
+          "installed-version": "1.0.0"
+        }
+      ]
+    }
+  ]
+}`}).Return().Once()
+			},
+			packages: packagesForTest,
+		},
 		"search and find single package - echo when installed version is less than available version": {
 			args: []string{"echo-uninstall"},
 			init: func(m *mocked) {
@@ -232,6 +261,7 @@ func TestCmdSearch(t *testing.T) {
 
 			app, ctx := setupTestApp(commandToExecute, m)
 			args := os.Args[0:1]
+			args = append(args, test.globalArgs...)
 			args = append(args, "search")
 			args = append(args, test.args...)
 
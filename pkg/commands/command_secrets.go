@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/color"
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/akamai/cli/v2/pkg/vault"
+	"github.com/urfave/cli/v2"
+)
+
+func cmdSecretsList(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("SECRETS LIST START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("SECRETS LIST FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("SECRETS LIST ERROR: %v", e))
+		}
+	}()
+	term := terminal.Get(c.Context)
+	session := vault.Get(c.Context)
+
+	names := make([]string, 0)
+	if session.Exists() {
+		v, err := session.Unlock()
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to list secrets: %v", err), 1)
+		}
+		names = v.Names()
+	}
+	if isStructuredOutput(c) {
+		return writeOutput(term, outputFormat(c), names)
+	}
+
+	if len(names) == 0 {
+		term.Printf("No secrets stored, add one with 'akamai secrets set <name>'.\n")
+		return nil
+	}
+	for _, name := range names {
+		term.Printf("%s\n", name)
+	}
+	return nil
+}
+
+func cmdSecretsGet(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("SECRETS GET START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("SECRETS GET FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("SECRETS GET ERROR: %v", e))
+		}
+	}()
+
+	name := c.Args().First()
+	if name == "" {
+		return cli.Exit(color.RedString("Unable to get secret: secret name has to be provided"), 1)
+	}
+	value, err := vault.Get(c.Context).Resolve(vault.Ref(name))
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to get secret: %v", err), 1)
+	}
+	terminal.Get(c.Context).Printf("%s\n", value)
+	return nil
+}
+
+func cmdSecretsSet(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("SECRETS SET START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("SECRETS SET FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("SECRETS SET ERROR: %v", e))
+		}
+	}()
+	term := terminal.Get(c.Context)
+
+	name := c.Args().First()
+	if name == "" {
+		return cli.Exit(color.RedString("Unable to set secret: secret name has to be provided"), 1)
+	}
+	v, err := vault.Get(c.Context).Unlock()
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to set secret: %v", err), 1)
+	}
+	value, err := readSecretValue(term, name, c.Args().Get(1))
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to set secret: %v", err), 1)
+	}
+	if err := v.Set(name, value); err != nil {
+		return cli.Exit(color.RedString("Unable to set secret: %v", err), 1)
+	}
+	if err := v.Save(); err != nil {
+		logger.Error(fmt.Sprintf("Error saving vault: %v", err))
+		return cli.Exit(color.RedString("Unable to set secret: %v", err), 1)
+	}
+	term.Printf("Stored secret %s, reference it as %s.\n", name, vault.Ref(name))
+	return nil
+}
+
+func cmdSecretsRemove(c *cli.Context) (e error) {
+	c.Context = log.WithCommandContext(c.Context, c.Command.Name)
+	logger := log.FromContext(c.Context)
+	start := time.Now()
+	logger.Debug("SECRETS REMOVE START")
+	defer func() {
+		if e == nil {
+			logger.Debug(fmt.Sprintf("SECRETS REMOVE FINISH: %v", time.Since(start)))
+		} else {
+			logger.Error(fmt.Sprintf("SECRETS REMOVE ERROR: %v", e))
+		}
+	}()
+	session := vault.Get(c.Context)
+
+	name := c.Args().First()
+	if name == "" {
+		return cli.Exit(color.RedString("Unable to remove secret: secret name has to be provided"), 1)
+	}
+	if !session.Exists() {
+		return cli.Exit(color.RedString("Unable to remove secret: %v: %s", vault.ErrSecretNotFound, name), 1)
+	}
+	v, err := session.Unlock()
+	if err != nil {
+		return cli.Exit(color.RedString("Unable to remove secret: %v", err), 1)
+	}
+	if err := v.Delete(name); err != nil {
+		return cli.Exit(color.RedString("Unable to remove secret: %v", err), 1)
+	}
+	if err := v.Save(); err != nil {
+		logger.Error(fmt.Sprintf("Error saving vault: %v", err))
+		return cli.Exit(color.RedString("Unable to remove secret: %v", err), 1)
+	}
+	terminal.Get(c.Context).Printf("Removed secret %s.\n", name)
+	return nil
+}
+
+// readSecretValue returns the value given as argument, read from stdin when the argument is '-', or asked for without echo
+func readSecretValue(term terminal.Terminal, name, arg string) (string, error) {
+	switch arg {
+	case "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("unable to read secret from stdin: %w", err)
+		}
+		value := strings.TrimRight(string(data), "\r\n")
+		if value == "" {
+			return "", errors.New("no secret given on stdin")
+		}
+		return value, nil
+	case "":
+		if !term.IsTTY() {
+			return "", errors.New("secret value has to be provided, use '-' to read it from stdin")
+		}
+		return term.Password(fmt.Sprintf("Value of secret %s", name))
+	}
+	return arg, nil
+}
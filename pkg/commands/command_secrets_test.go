@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/akamai/cli/v2/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCmdSecrets(t *testing.T) {
+	tests := map[string]struct {
+		args       []string
+		globalArgs []string
+		seed       bool
+		passphrase string
+		init       func(*mocked)
+		expected   map[string]string
+		withError  string
+	}{
+		"list secrets": {
+			args: []string{"list"},
+			seed: true,
+			init: func(m *mocked) {
+				m.term.On("Printf", "%s\n", []interface{}{"prod/client_secret"}).Return().Once()
+			},
+		},
+		"list secrets as json": {
+			args:       []string{"list"},
+			globalArgs: []string{"--output", "json"},
+			seed:       true,
+			init: func(m *mocked) {
+				m.term.On("Printf", "%s\n", []interface{}{"[\n  \"prod/client_secret\"\n]"}).Return().Once()
+			},
+		},
+		"list without vault": {
+			args: []string{"list"},
+			init: func(m *mocked) {
+				m.term.On("Printf", "No secrets stored, add one with 'akamai secrets set <name>'.\n", []interface{}(nil)).Return().Once()
+			},
+		},
+		"get secret": {
+			args: []string{"get", "prod/client_secret"},
+			seed: true,
+			init: func(m *mocked) {
+				m.term.On("Printf", "%s\n", []interface{}{"s3cret"}).Return().Once()
+			},
+		},
+		"get unknown secret": {
+			args:      []string{"get", "prod/missing"},
+			seed:      true,
+			init:      func(*mocked) {},
+			withError: "secret not found: prod/missing",
+		},
+		"get secret without vault": {
+			args:      []string{"get", "prod/client_secret"},
+			init:      func(*mocked) {},
+			withError: "secret not found: prod/client_secret, no vault at",
+		},
+		"get secret with wrong passphrase": {
+			args:       []string{"get", "prod/client_secret"},
+			seed:       true,
+			passphrase: "wrong",
+			init:       func(*mocked) {},
+			withError:  "wrong passphrase or corrupted vault",
+		},
+		"set secret in new vault": {
+			args: []string{"set", "prod/client_secret", "s3cret"},
+			init: func(m *mocked) {
+				m.term.On("Printf", "Stored secret %s, reference it as %s.\n", []interface{}{"prod/client_secret", "secret://prod/client_secret"}).Return().Once()
+			},
+			expected: map[string]string{"prod/client_secret": "s3cret"},
+		},
+		"set prompted secret": {
+			args: []string{"set", "token"},
+			seed: true,
+			init: func(m *mocked) {
+				m.term.On("IsTTY").Return(true).Once()
+				m.term.On("Password", "Value of secret token").Return("abc", nil).Once()
+				m.term.On("Printf", "Stored secret %s, reference it as %s.\n", []interface{}{"token", "secret://token"}).Return().Once()
+			},
+			expected: map[string]string{"prod/client_secret": "s3cret", "token": "abc"},
+		},
+		"set secret without value in non-interactive session": {
+			args: []string{"set", "token"},
+			init: func(m *mocked) {
+				m.term.On("IsTTY").Return(false).Once()
+			},
+			withError: "secret value has to be provided, use '-' to read it from stdin",
+		},
+		"set secret with invalid name": {
+			args:      []string{"set", "prod secret", "s3cret"},
+			init:      func(*mocked) {},
+			withError: `invalid secret name "prod secret"`,
+		},
+		"set secret without name": {
+			args:      []string{"set"},
+			init:      func(*mocked) {},
+			withError: "secret name has to be provided",
+		},
+		"remove secret": {
+			args: []string{"remove", "prod/client_secret"},
+			seed: true,
+			init: func(m *mocked) {
+				m.term.On("Printf", "Removed secret %s.\n", []interface{}{"prod/client_secret"}).Return().Once()
+			},
+			expected: map[string]string{},
+		},
+		"remove unknown secret": {
+			args:      []string{"remove", "token"},
+			seed:      true,
+			init:      func(*mocked) {},
+			withError: "secret not found: token",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), vault.FileName)
+			if test.seed {
+				v, err := vault.Open(path, "passphrase")
+				require.NoError(t, err)
+				require.NoError(t, v.Set("prod/client_secret", "s3cret"))
+				require.NoError(t, v.Save())
+			}
+			passphrase := test.passphrase
+			if passphrase == "" {
+				passphrase = "passphrase"
+			}
+			t.Setenv(vault.EnvPassphrase, passphrase)
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil, nil}
+			command := &cli.Command{
+				Name: "secrets",
+				Subcommands: []*cli.Command{
+					{Name: "get", Action: cmdSecretsGet},
+					{Name: "list", Action: cmdSecretsList},
+					{Name: "remove", Action: cmdSecretsRemove},
+					{Name: "set", Action: cmdSecretsSet},
+				},
+			}
+			app, ctx := setupTestApp(command, m)
+			ctx = vault.Context(ctx, vault.NewSession(path, vault.PromptPassphrase(m.term)))
+			args := os.Args[0:1]
+			args = append(args, test.globalArgs...)
+			args = append(args, "secrets")
+			args = append(args, test.args...)
+
+			test.init(m)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			if test.withError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+			if test.expected != nil {
+				v, err := vault.Open(path, "passphrase")
+				require.NoError(t, err)
+				secrets := make(map[string]string)
+				for _, name := range v.Names() {
+					secrets[name], err = v.Get(name)
+					require.NoError(t, err)
+				}
+				assert.Equal(t, test.expected, secrets)
+			}
+		})
+	}
+}
@@ -1,17 +1,21 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 
+	"github.com/akamai/cli/v2/pkg/broker"
 	"github.com/akamai/cli/v2/pkg/color"
+	"github.com/akamai/cli/v2/pkg/config"
 	"github.com/akamai/cli/v2/pkg/git"
 	"github.com/akamai/cli/v2/pkg/log"
 	"github.com/akamai/cli/v2/pkg/packages"
 	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/akamai/cli/v2/pkg/vault"
 	"github.com/urfave/cli/v2"
 )
 
@@ -53,6 +57,8 @@ func cmdSubcommand(git git.Repository, langManager packages.LangManager) cli.Act
 			return err
 		}
 
+		// the variables are only set for the package command, as the process environment is shared by concurrent invocations
+		env := make([]string, 0)
 		if cmdPackage.Requirements.Python != "" {
 			exec, err := langManager.FindExec(c.Context, cmdPackage.Requirements, packageDir)
 			if err != nil {
@@ -69,16 +75,7 @@ func cmdSubcommand(git git.Repository, langManager packages.LangManager) cli.Act
 				}
 			}
 
-			switch runtime.GOOS {
-			case "linux":
-				_, err = os.Stat(filepath.Join(packageDir, ".local"))
-			case "darwin":
-				_, err = os.Stat(filepath.Join(packageDir, "Library"))
-			case "windows":
-				_, err = os.Stat(filepath.Join(packageDir, "Lib"))
-			}
-
-			if err == nil {
+			if hasUserSitePackages(packageDir) {
 				answer, err := term.Confirm("Would you like to reinstall it", true)
 				logger.Debug(fmt.Sprintf("Would you like to reinstall it? %v", answer))
 				if err != nil {
@@ -90,18 +87,23 @@ func cmdSubcommand(git git.Repository, langManager packages.LangManager) cli.Act
 					return cli.Exit(color.RedString("%s", packages.ErrPackageNeedsReinstall.Error()), -1)
 				}
 
-				if err = uninstallPackage(c.Context, langManager, commandName, logger); err != nil {
+				reinstall := withHomeLock(withCommandIndexRebuild(func(c *cli.Context) error {
+					// another process may have reinstalled the package while this one waited for the lock
+					if !hasUserSitePackages(packageDir) {
+						return nil
+					}
+					pin := readPackagePin(packageDir)
+					if err := uninstallPackage(c.Context, langManager, commandName, logger); err != nil {
+						return err
+					}
+					_, err := installPackage(c.Context, git, langManager, commandName, pin)
 					return err
-				}
-
-				if _, err = installPackage(c.Context, git, langManager, commandName); err != nil {
+				}))
+				if err := reinstall(c); err != nil {
 					return err
 				}
 			}
-			if err := os.Setenv("PYTHONUSERBASE", packageDir); err != nil {
-				logger.Error(fmt.Sprintf("Error setting PYTHONUSERBASE: %v", err))
-				return err
-			}
+			env = append(env, "PYTHONUSERBASE="+packageDir)
 		}
 
 		var currentCmd command
@@ -118,14 +120,7 @@ func cmdSubcommand(git git.Repository, langManager packages.LangManager) cli.Act
 			}
 		}
 
-		if err := os.Setenv("AKAMAI_CLI_COMMAND", commandName); err != nil {
-			logger.Error(fmt.Sprintf("Error setting AKAMAI_CLI_COMMAND: %v", err))
-			return err
-		}
-		if err := os.Setenv("AKAMAI_CLI_COMMAND_VERSION", currentCmd.Version); err != nil {
-			logger.Error(fmt.Sprintf("Error setting AKAMAI_CLI_COMMAND_VERSION: %v", err))
-			return err
-		}
+		env = append(env, "AKAMAI_CLI_COMMAND="+commandName, "AKAMAI_CLI_COMMAND_VERSION="+currentCmd.Version)
 
 		cmdPackage, err = readPackage(packageDir)
 		if err != nil {
@@ -133,13 +128,97 @@ func cmdSubcommand(git git.Repository, langManager packages.LangManager) cli.Act
 			return err
 		}
 
-		executable = prepareCommand(c, executable, c.Args().Slice(), "edgerc", "section", "accountkey")
+		args := expandAccountAliases(config.Get(c.Context), c.Args().Slice())
+		executable = prepareCommand(c, executable, args, "edgerc", "section", "accountkey")
 
 		subCmd := createCommand(executable[0], executable[1:])
+		pkgName := strings.TrimPrefix(filepath.Base(packageDir), "cli-")
+		pkgEnv := config.PackageEnv(config.Get(c.Context), pkgName)
+		if len(pkgEnv) > 0 {
+			logger.Debug(fmt.Sprintf("Passing %d value(s) of the [%s] config section", len(pkgEnv), config.PackageSection(pkgName)))
+		}
+		env = append(env, pkgEnv...)
+		if b := startCredentialBroker(c); b != nil {
+			defer func() {
+				if err := b.Close(); err != nil {
+					logger.Warn(fmt.Sprintf("Error stopping credential broker: %v", err))
+				}
+			}()
+			env = append(env, b.Env()...)
+		}
+		// config values are exported unresolved, their secrets are only read for the package command
+		subCmd.cmd.Env, err = resolveEnvSecrets(c.Context, append(os.Environ(), env...))
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error resolving secrets of config values: %v", err))
+			return cli.Exit(color.RedString("Unable to resolve secrets of config values: %v", err), 1)
+		}
 		return passthruCommand(c.Context, subCmd, langManager, cmdPackage.Requirements, fmt.Sprintf("cli-%s", cmdPackage.Commands[0].Name))
 	}
 }
 
+// resolveEnvSecrets replaces the values of NAME=value variables which reference a secret with the secret of the vault.
+// The vault is only unlocked when a value references a secret.
+func resolveEnvSecrets(ctx context.Context, env []string) ([]string, error) {
+	resolved := make([]string, 0, len(env))
+	for _, variable := range env {
+		name, value, _ := strings.Cut(variable, "=")
+		if vault.IsRef(value) {
+			secret, err := vault.Get(ctx).Resolve(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			variable = name + "=" + secret
+		}
+		resolved = append(resolved, variable)
+	}
+	return resolved, nil
+}
+
+// startCredentialBroker serves the credentials of the selected section to the package command when cli.credential-broker is on.
+// A broker which cannot be started is reported, and the command runs without it, as it may not need credentials at all.
+func startCredentialBroker(c *cli.Context) *broker.Broker {
+	if enabled, _ := config.Get(c.Context).GetValue("cli", "credential-broker"); enabled != "on" {
+		return nil
+	}
+	logger := log.FromContext(c.Context)
+	file, err := loadEdgerc(c)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Credential broker not started: %v", err))
+		_, _ = fmt.Fprintln(terminal.Get(c.Context).Error(), color.YellowString("Credential broker not started: %v", err))
+		return nil
+	}
+	section := selectedSection(c)
+	b, err := broker.Start(c.Context, secretCredentials{file: file, secrets: vault.Get(c.Context)}, section, c.String("accountkey"))
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Credential broker not started: %v", err))
+		_, _ = fmt.Fprintln(terminal.Get(c.Context).Error(), color.YellowString("Credential broker not started: %v", err))
+		return nil
+	}
+	logger.Debug(fmt.Sprintf("Serving credentials of section %s of %s on %s", section, file.Path(), b.Address()))
+	return b
+}
+
+// expandAccountAliases replaces account aliases given to the --accountkey flag of the package command with their account switch key
+func expandAccountAliases(cfg config.Config, args []string) []string {
+	expanded := make([]string, len(args))
+	copy(expanded, args)
+	for i, arg := range expanded {
+		for _, name := range []string{"--accountkey", "--account-key"} {
+			switch {
+			case arg == name && i+1 < len(expanded):
+				if key, ok := config.ExpandAccountKey(cfg, expanded[i+1]); ok {
+					expanded[i+1] = key
+				}
+			case strings.HasPrefix(arg, name+"="):
+				if key, ok := config.ExpandAccountKey(cfg, strings.TrimPrefix(arg, name+"=")); ok {
+					expanded[i] = name + "=" + key
+				}
+			}
+		}
+	}
+	return expanded
+}
+
 func prepareCommand(c *cli.Context, command, args []string, flags ...string) []string {
 	// dont search for flags is there are no args
 	if len(args) == 0 {
@@ -177,3 +256,21 @@ func containsString(s []string, item string) bool {
 	}
 	return false
 }
+
+// hasUserSitePackages reports whether the dependencies of a Python package were installed in its directory
+// as user site packages, the way packages were installed before virtual environments were used
+func hasUserSitePackages(packageDir string) bool {
+	var dir string
+	switch runtime.GOOS {
+	case "linux":
+		dir = filepath.Join(packageDir, ".local")
+	case "darwin":
+		dir = filepath.Join(packageDir, "Library")
+	case "windows":
+		dir = filepath.Join(packageDir, "Lib")
+	default:
+		return false
+	}
+	_, err := os.Stat(dir)
+	return err == nil
+}
@@ -1,7 +1,9 @@
 package commands
 
 import (
+	"context"
 	"flag"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -11,6 +13,7 @@ import (
 	"github.com/akamai/cli/v2/pkg/git"
 	"github.com/akamai/cli/v2/pkg/packages"
 	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/akamai/cli/v2/pkg/vault"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
@@ -38,6 +41,7 @@ func TestCmdSubcommand(t *testing.T) {
 				m.langManager.On("FinishExecution", packages.LanguageRequirements{Go: "1.14.0"}, "cli-echo").Once()
 				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, akamaiEchoBin).Return([]string{akamaiEchoBin}, nil)
 
+				m.cfg.On("Values").Return(map[string]map[string]string{}).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
 			},
@@ -53,6 +57,7 @@ func TestCmdSubcommand(t *testing.T) {
 				m.langManager.On("FinishExecution", packages.LanguageRequirements{Go: "1.14.0"}, "cli-echo").Once()
 				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, akamaiEchoBin).Return([]string{akamaiEchoBin}, nil)
 
+				m.cfg.On("Values").Return(map[string]map[string]string{}).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
 			},
@@ -70,6 +75,7 @@ func TestCmdSubcommand(t *testing.T) {
 				m.langManager.On("FinishExecution", packages.LanguageRequirements{Go: "1.14.0"}, "cli-echo").Once()
 				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, akamaiEBin).Return([]string{akamaiEBin}, nil)
 
+				m.cfg.On("Values").Return(map[string]map[string]string{}).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
 			},
@@ -86,6 +92,7 @@ func TestCmdSubcommand(t *testing.T) {
 				m.langManager.On("PrepareExecution", packages.LanguageRequirements{Go: "1.14.0"}, "cli-echo").Return(nil).Once()
 				m.langManager.On("FinishExecution", packages.LanguageRequirements{Go: "1.14.0"}, "cli-echo").Once()
 
+				m.cfg.On("Values").Return(map[string]map[string]string{}).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
 			},
@@ -102,6 +109,64 @@ func TestCmdSubcommand(t *testing.T) {
 				m.langManager.On("PrepareExecution", packages.LanguageRequirements{Go: "1.14.0"}, "cli-echo").Return(nil).Once()
 				m.langManager.On("FinishExecution", packages.LanguageRequirements{Go: "1.14.0"}, "cli-echo").Once()
 
+				m.cfg.On("Values").Return(map[string]map[string]string{}).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+			},
+		},
+		"run installed akamai echo command with package config": {
+			command: "echo",
+			args:    []string{"abc"},
+			init: func(m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Running echo command...", []interface{}(nil)).Return().Once()
+
+				m.langManager.On("PrepareExecution", packages.LanguageRequirements{Go: "1.14.0"}, "cli-echo").Return(nil).Once()
+				m.langManager.On("FinishExecution", packages.LanguageRequirements{Go: "1.14.0"}, "cli-echo").Once()
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, akamaiEchoBin).Return([]string{akamaiEchoBin}, nil)
+
+				m.cfg.On("Values").Return(map[string]map[string]string{
+					"package.echo":  {"greeting": "hello"},
+					"package.other": {"greeting": "bye"},
+				}).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+			},
+		},
+		"run installed akamai echo command with credential broker": {
+			command:        "echo",
+			args:           []string{"abc"},
+			edgercLocation: filepath.Join("testdata", "broker.edgerc"),
+			init: func(m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Running echo command...", []interface{}(nil)).Return().Once()
+
+				m.langManager.On("PrepareExecution", packages.LanguageRequirements{Go: "1.14.0"}, "cli-echo").Return(nil).Once()
+				m.langManager.On("FinishExecution", packages.LanguageRequirements{Go: "1.14.0"}, "cli-echo").Once()
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, akamaiEchoBin).Return([]string{akamaiEchoBin}, nil)
+
+				m.cfg.On("Values").Return(map[string]map[string]string{}).Once()
+				m.cfg.On("GetValue", "cli", "credential-broker").Return("on", true).Once()
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("OK").Return().Once()
+			},
+		},
+		"credential broker not started for unknown section": {
+			command:        "echo",
+			args:           []string{"abc"},
+			edgercLocation: filepath.Join("testdata", "broker.edgerc"),
+			section:        "missing",
+			init: func(m *mocked) {
+				m.term.On("Spinner").Return(m.term).Once()
+				m.term.On("Start", "Running echo command...", []interface{}(nil)).Return().Once()
+
+				m.langManager.On("PrepareExecution", packages.LanguageRequirements{Go: "1.14.0"}, "cli-echo").Return(nil).Once()
+				m.langManager.On("FinishExecution", packages.LanguageRequirements{Go: "1.14.0"}, "cli-echo").Once()
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, akamaiEchoBin).Return([]string{akamaiEchoBin}, nil)
+
+				m.cfg.On("Values").Return(map[string]map[string]string{}).Once()
+				m.cfg.On("GetValue", "cli", "credential-broker").Return("on", true).Once()
+				m.term.On("Error").Return(io.Discard).Once()
 				m.term.On("Spinner").Return(m.term).Once()
 				m.term.On("OK").Return().Once()
 			},
@@ -139,9 +204,13 @@ func TestCmdSubcommand(t *testing.T) {
 			args = append(args, test.args...)
 
 			test.init(m)
+			m.cfg.On("GetValue", "cli", "credential-broker").Return("", false).Maybe()
 			err := app.RunContext(ctx, args)
 
 			m.cfg.AssertExpectations(t)
+			// the variables are passed to the package command only
+			assert.Empty(t, os.Getenv("AKAMAI_CLI_COMMAND"))
+			assert.Empty(t, os.Getenv("AKAMAI_CLI_COMMAND_VERSION"))
 			if test.withError != "" {
 				assert.Error(t, err)
 				assert.Contains(t, err.Error(), test.withError)
@@ -182,6 +251,8 @@ func TestPythonCmdSubcommand(t *testing.T) {
 			m.langManager.On("FindExec", packages.LanguageRequirements{Python: "3.0.0"}, filepath.Join("testdata", ".akamai-cli", "src", "cli-echo-python")).
 				Return([]string{pythonBin, filepath.Join("testdata", ".akamai-cli", "src", "cli-echo-python", "bin", "akamai-echo-python")}, nil).Once()
 			m.langManager.On("FileExists", filepath.Join("testdata", ".akamai-cli", "venv", "cli-echo-python")).Return(true, nil)
+			m.cfg.On("Values").Return(map[string]map[string]string{}).Once()
+			m.cfg.On("GetValue", "cli", "credential-broker").Return("", false).Once()
 			m.term.On("Spinner").Return(m.term).Once()
 			m.term.On("OK").Return().Once()
 		}
@@ -189,6 +260,7 @@ func TestPythonCmdSubcommand(t *testing.T) {
 		err := app.RunContext(ctx, args)
 
 		m.cfg.AssertExpectations(t)
+		assert.Empty(t, os.Getenv("PYTHONUSERBASE"))
 		require.NoError(t, err)
 	})
 }
@@ -334,3 +406,31 @@ func TestPrepareCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveEnvSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), vault.FileName)
+	v, err := vault.Open(path, "passphrase")
+	require.NoError(t, err)
+	require.NoError(t, v.Set("purge/token", "s3cret"))
+	require.NoError(t, v.Save())
+	ctx := vault.Context(context.Background(), vault.NewSession(path, func(bool) (string, error) {
+		return "passphrase", nil
+	}))
+
+	env, err := resolveEnvSecrets(ctx, []string{"AKAMAI_PURGE_NETWORK=staging", "AKAMAI_PURGE_TOKEN=secret://purge/token"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AKAMAI_PURGE_NETWORK=staging", "AKAMAI_PURGE_TOKEN=s3cret"}, env)
+
+	_, err = resolveEnvSecrets(ctx, []string{"AKAMAI_PURGE_KEY=secret://purge/key"})
+	assert.EqualError(t, err, "AKAMAI_PURGE_KEY: secret not found: purge/key")
+}
+
+func TestExpandAccountAliases(t *testing.T) {
+	cfg := &config.Mock{}
+	cfg.On("Values").Return(map[string]map[string]string{"account acme": {"key": "1-ABC:1-2XYZ"}})
+
+	args := []string{"--accountkey", "acme", "--account-key=acme", "--accountkey", "1-OTHER", "list", "acme"}
+	assert.Equal(t, []string{"--accountkey", "1-ABC:1-2XYZ", "--account-key=1-ABC:1-2XYZ", "--accountkey", "1-OTHER", "list", "acme"},
+		expandAccountAliases(cfg, args))
+	assert.Equal(t, "acme", args[1], "given args are left as is")
+}
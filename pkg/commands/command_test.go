@@ -11,6 +11,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/akamai/cli/v2/pkg/config"
 	"github.com/akamai/cli/v2/pkg/git"
 	"github.com/akamai/cli/v2/pkg/packages"
 	"github.com/stretchr/testify/assert"
@@ -20,7 +21,9 @@ import (
 
 func TestCommandsLocator(t *testing.T) {
 	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
-	res := CommandLocator(context.Background())
+	cfg := &config.Mock{}
+	cfg.On("GetValue", "cli", "cache-path").Return(t.TempDir(), true)
+	res := CommandLocator(config.Context(context.Background(), cfg))
 	for i := 0; i < len(res)-1; i++ {
 		assert.True(t, strings.Compare(res[i].Name, res[i+1].Name) == -1)
 	}
@@ -32,6 +32,18 @@ func cmdUninstall(langManager packages.LangManager) cli.ActionFunc {
 				logger.Error(fmt.Sprintf("UNINSTALL ERROR: %v", e))
 			}
 		}()
+		if c.Bool("dry-run") {
+			plans := make([]dryRunPlan, 0, c.Args().Len())
+			for _, cmd := range c.Args().Slice() {
+				plan, err := planUninstall(c.Context, langManager, cmd)
+				if err != nil {
+					plan.Error = err.Error()
+				}
+				plans = append(plans, plan)
+			}
+			return writeDryRun(c, plans)
+		}
+
 		for _, cmd := range c.Args().Slice() {
 			if err := uninstallPackage(c.Context, langManager, cmd, logger); err != nil {
 				logger.Error(fmt.Sprintf("Error uninstalling package: %v", err))
@@ -51,7 +63,6 @@ func uninstallPackage(ctx context.Context, langManager packages.LangManager, cmd
 		logger.Error(fmt.Sprintf("No home directory detected: %v", err))
 		return fmt.Errorf("no home directory detected: %v", err)
 	}
-	home += string(filepath.Separator)
 	exec, _, err := findExec(ctx, langManager, cmd)
 	if err != nil {
 		if !errors.Is(err, packages.ErrNoExeFound) {
@@ -60,19 +71,13 @@ func uninstallPackage(ctx context.Context, langManager packages.LangManager, cmd
 		}
 
 		// err = ErrNoExeFound - there is a directory but without any executables
-		paths := filepath.SplitList(getPackageBinPaths())
-		for i, path := range paths {
-			// trim home directory part of a path to exclude cases where command name could be a part of it
-			path = strings.TrimPrefix(path, home)
-			// if trimmed path (akamai-cli defined) contains name of command to uninstall, delete directory
-			if strings.Contains(path, cmd) {
-				if err = os.RemoveAll(paths[i]); err != nil {
-					logger.Error(fmt.Sprintf("Unable to remove directory: %s", paths[i]))
-					return fmt.Errorf("could not remove directory %s: %v", paths[i], err)
-				}
-				logger.Debug(fmt.Sprintf("Removed directory: %s", paths[i]))
-				return nil
+		if dir := findPackageDirWithoutExec(home, cmd); dir != "" {
+			if err = os.RemoveAll(dir); err != nil {
+				logger.Error(fmt.Sprintf("Unable to remove directory: %s", dir))
+				return fmt.Errorf("could not remove directory %s: %v", dir, err)
 			}
+			logger.Debug(fmt.Sprintf("Removed directory: %s", dir))
+			return nil
 		}
 		logger.Error(fmt.Sprintf("Command \"%s\" not found", cmd))
 		return fmt.Errorf("command \"%s\" not found. Try \"%s help\"", cmd, tools.Self())
@@ -115,8 +120,24 @@ func uninstallPackage(ctx context.Context, langManager packages.LangManager, cmd
 		}
 	}
 
+	if err := removePackageHistory(filepath.Base(repoDir)); err != nil {
+		logger.Warn(fmt.Sprintf("Unable to remove package history: %v", err))
+	}
+
 	term.Spinner().OK()
 	logger.Debug(fmt.Sprintf("Uninstalled \"%s\" command", cmd))
 
 	return nil
 }
+
+// findPackageDirWithoutExec returns the package directory containing cmd in its path, for packages without any executables
+func findPackageDirWithoutExec(home, cmd string) string {
+	home += string(filepath.Separator)
+	for _, path := range getPackageBinDirs() {
+		// trim home directory part of a path to exclude cases where command name could be a part of it
+		if strings.Contains(strings.TrimPrefix(path, home), cmd) {
+			return path
+		}
+	}
+	return ""
+}
@@ -29,6 +29,7 @@ func TestCmdUninstall(t *testing.T) {
 	tests := map[string]struct {
 		args      []string
 		init      func(*testing.T, *mocked)
+		teardown  func(*testing.T)
 		withError string
 	}{
 		"uninstall command": {
@@ -48,6 +49,28 @@ func TestCmdUninstall(t *testing.T) {
 				m.term.On("OK").Return().Once()
 			},
 		},
+		"dry run of uninstall command": {
+			args: []string{"--dry-run", "echo-uninstall"},
+			init: func(t *testing.T, m *mocked) {
+				mustCopyFile(t, cliEchoJSON, cliEchoUninstallRepo)
+				mustCopyFile(t, cliEchoBin, cliEchoUninstallBinDir)
+				err := os.Rename(cliEchoInUninstallBin, cliEchoUninstallBin)
+				require.NoError(t, err)
+				err = os.Chmod(cliEchoUninstallBin, 0755)
+				require.NoError(t, err)
+
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, cliEchoUninstallBin).Return([]string{cliEchoUninstallBin}, nil).Once()
+				m.term.On("Printf", "%s\n", []interface{}{color.YellowString("Would %s %s:", "uninstall", color.BoldString("%s", "cli-echo-uninstall"))}).Return().Once()
+				m.term.On("Printf", "  %s: %s\n", []interface{}{"Version", "1.0.0"}).Return().Once()
+				m.term.On("Printf", "  %s %s\n", []interface{}{color.RedString("- directory"), filepath.Join("testdata", ".akamai-cli", "src", "cli-echo-uninstall")}).Return().Once()
+				m.term.On("Printf", "  %s %s\n", []interface{}{color.RedString("- command"), "echo"}).Return().Once()
+				m.term.On("Printf", "%s\n", []interface{}{color.CyanString("Dry run, no changes were made.")}).Return().Once()
+			},
+			teardown: func(t *testing.T) {
+				_, err := os.Stat(cliEchoUninstallBin)
+				assert.NoError(t, err)
+			},
+		},
 		"package does not contain cli.json": {
 			args:      []string{"echo-uninstall"},
 			init:      func(_ *testing.T, _ *mocked) {},
@@ -106,6 +129,11 @@ func TestCmdUninstall(t *testing.T) {
 			command := &cli.Command{
 				Name:   "uninstall",
 				Action: cmdUninstall(m.langManager),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name: "dry-run",
+					},
+				},
 			}
 			app, ctx := setupTestApp(command, m)
 			defer func() {
@@ -117,6 +145,9 @@ func TestCmdUninstall(t *testing.T) {
 
 			test.init(t, m)
 			err := app.RunContext(ctx, args)
+			if test.teardown != nil {
+				test.teardown(t)
+			}
 
 			m.cfg.AssertExpectations(t)
 			if test.withError != "" {
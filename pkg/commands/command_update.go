@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/akamai/cli/v2/pkg/color"
@@ -18,9 +19,42 @@ import (
 	"github.com/akamai/cli/v2/pkg/terminal"
 	"github.com/akamai/cli/v2/pkg/tools"
 	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/urfave/cli/v2"
 )
 
+type (
+	// packageUpdate describes the outcome of updating the package providing a command
+	packageUpdate struct {
+		Command         string
+		Package         string
+		Updated         bool
+		PreviousVersion string
+		Version         string
+	}
+
+	// updateOutput is the document written by the update command in JSON and YAML output formats
+	updateOutput struct {
+		Results []updateResultOutput `json:"results" yaml:"results"`
+	}
+
+	updateResultOutput struct {
+		Command         string `json:"command" yaml:"command"`
+		Package         string `json:"package,omitempty" yaml:"package,omitempty"`
+		Status          string `json:"status" yaml:"status"`
+		PreviousVersion string `json:"previous-version,omitempty" yaml:"previous-version,omitempty"`
+		Version         string `json:"version,omitempty" yaml:"version,omitempty"`
+		Error           string `json:"error,omitempty" yaml:"error,omitempty"`
+	}
+)
+
+// update statuses reported in JSON and YAML output formats
+const (
+	updateStatusUpdated  = "updated"
+	updateStatusUpToDate = "up-to-date"
+	updateStatusFailed   = "failed"
+)
+
 func cmdUpdate(gitRepo git.Repository, langManager packages.LangManager) cli.ActionFunc {
 	return func(c *cli.Context) (e error) {
 		c.Context = log.WithCommandContext(c.Context, c.Command.Name)
@@ -34,6 +68,8 @@ func cmdUpdate(gitRepo git.Repository, langManager packages.LangManager) cli.Act
 				logger.Error(fmt.Sprintf("UPDATE ERROR: %v", e))
 			}
 		}()
+
+		cmds := c.Args().Slice()
 		if !c.Args().Present() {
 			var builtinCmds = make(map[string]bool)
 			for _, cmd := range getBuiltinCommands(c) {
@@ -43,40 +79,230 @@ func cmdUpdate(gitRepo git.Repository, langManager packages.LangManager) cli.Act
 			for _, cmd := range getCommands(c) {
 				for _, command := range cmd.Commands {
 					if _, ok := builtinCmds[command.Name]; !ok {
-						if err := updatePackage(c.Context, gitRepo, langManager, logger, command.Name); err != nil {
-							logger.Error(fmt.Sprintf("Error updating package: %v", err))
-							return err
-						}
+						cmds = append(cmds, command.Name)
 					}
 				}
 			}
+		}
 
-			return nil
+		jobs := c.Int("jobs")
+		if jobs < 1 {
+			jobs = 1
+		}
+		targets := resolveUpdateTargets(c.Context, langManager, logger, cmds)
+
+		if c.Bool("dry-run") {
+			plans := make([]dryRunPlan, 0, len(targets))
+			for _, target := range targets {
+				plan := dryRunPlan{Action: dryRunUpdate, Package: target.command}
+				if target.err == nil {
+					plan, target.err = planUpdate(c.Context, gitRepo, target.command, target.dir)
+				}
+				if target.err != nil {
+					plan.Error = strings.TrimSpace(target.err.Error())
+				}
+				plans = append(plans, plan)
+			}
+			return writeDryRun(c, plans)
 		}
 
-		for _, cmd := range c.Args().Slice() {
-			if err := updatePackage(c.Context, gitRepo, langManager, logger, cmd); err != nil {
+		if isStructuredOutput(c) {
+			return updatePackagesWithOutput(c, gitRepo, langManager, logger, targets, jobs)
+		}
+
+		if len(targets) == 1 {
+			target := targets[0]
+			if target.err != nil {
+				return target.err
+			}
+			if _, err := updatePackage(c.Context, gitRepo, langManager, logger, target.command, target.dir); err != nil {
 				logger.Error(fmt.Sprintf("Error updating package: %v", err))
 				return err
 			}
+			return nil
 		}
 
-		return nil
+		term := terminal.Get(c.Context)
+		progress := terminal.NewProgress(term.Error(), term.IsTTY())
+		outcomes := runUpdates(c.Context, gitRepo, langManager, logger, targets, jobs, progress)
+		return printUpdateSummary(term, outcomes)
 	}
 }
 
-func updatePackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, logger *slog.Logger, cmd string) error {
+// updateTarget is a package to update, identified by the first command given for it
+type updateTarget struct {
+	command string
+	dir     string
+	err     error
+}
+
+// updateOutcome is the result of updating a single package
+type updateOutcome struct {
+	update packageUpdate
+	err    error
+}
+
+// resolveUpdateTargets finds the package directory of each command, so that every package is updated only once.
+// Commands which cannot be resolved are kept as targets failing with the resolution error.
+func resolveUpdateTargets(ctx context.Context, langManager packages.LangManager, logger *slog.Logger, cmds []string) []updateTarget {
+	targets := make([]updateTarget, 0, len(cmds))
+	seen := make(map[string]bool)
+	for _, cmd := range cmds {
+		dir, err := findUpdatePackageDir(ctx, langManager, logger, cmd)
+		if err == nil {
+			if seen[dir] {
+				logger.Debug(fmt.Sprintf("Package %s of command %s is already being updated", filepath.Base(dir), cmd))
+				continue
+			}
+			seen[dir] = true
+		}
+		targets = append(targets, updateTarget{command: cmd, dir: dir, err: err})
+	}
+	return targets
+}
+
+// runUpdates updates the target packages with at most jobs updates running at the same time.
+// The progress of each update is shown on its own line of progress. Outcomes are returned in the order of targets.
+func runUpdates(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, logger *slog.Logger,
+	targets []updateTarget, jobs int, progress *terminal.Progress) []updateOutcome {
 	term := terminal.Get(ctx)
+	outcomes := make([]updateOutcome, len(targets))
+
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	progress.Start()
+	for i := 0; i < jobs && i < len(targets); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			repo := gitRepo.New()
+			for idx := range queue {
+				target := targets[idx]
+				if target.err != nil {
+					outcomes[idx] = updateOutcome{update: packageUpdate{Command: target.command}, err: target.err}
+					continue
+				}
+				lineCtx := terminal.Context(ctx, progress.Terminal(term, filepath.Base(target.dir)))
+				update, err := updatePackage(lineCtx, repo, langManager, logger, target.command, target.dir)
+				if err != nil {
+					logger.Error(fmt.Sprintf("Error updating package: %v", err))
+				}
+				outcomes[idx] = updateOutcome{update: update, err: err}
+			}
+		}()
+	}
+	for idx := range targets {
+		queue <- idx
+	}
+	close(queue)
+	wg.Wait()
+	progress.Stop()
+
+	return outcomes
+}
+
+// printUpdateSummary prints the outcome of every package update and fails if any of them failed
+func printUpdateSummary(term terminal.Terminal, outcomes []updateOutcome) error {
+	var updated, upToDate, failed int
+	if _, err := term.Writeln(color.YellowString("\nUpdate Summary:\n")); err != nil {
+		return err
+	}
+	for _, outcome := range outcomes {
+		name := outcome.update.Package
+		if name == "" {
+			name = outcome.update.Command
+		}
+		switch {
+		case outcome.err != nil:
+			failed++
+			term.Printf("  %s: %s\n", color.BoldString("%s", name), color.RedString("failed: %s", strings.TrimSpace(outcome.err.Error())))
+		case outcome.update.Updated:
+			updated++
+			term.Printf("  %s: %s\n", color.BoldString("%s", name), color.GreenString("updated %s", versionChange(outcome.update)))
+		default:
+			upToDate++
+			term.Printf("  %s: %s\n", color.BoldString("%s", name), color.CyanString("up-to-date"))
+		}
+	}
+	term.Printf("\n%d updated, %d up-to-date, %d failed\n", updated, upToDate, failed)
+
+	if failed > 0 {
+		return cli.Exit(color.RedString("Unable to update %d of %d package(s)", failed, len(outcomes)), 1)
+	}
+	return nil
+}
+
+// versionChange describes the version change of an updated package command
+func versionChange(update packageUpdate) string {
+	if update.PreviousVersion == "" || update.PreviousVersion == update.Version {
+		return update.Version
+	}
+	return fmt.Sprintf("%s -> %s", update.PreviousVersion, update.Version)
+}
+
+// updatePackagesWithOutput updates all target packages and writes the result of each update as a JSON or YAML document.
+// Progress messages are written to the error stream to keep the document the only content of the output stream.
+func updatePackagesWithOutput(c *cli.Context, gitRepo git.Repository, langManager packages.LangManager, logger *slog.Logger, targets []updateTarget, jobs int) error {
+	term := terminal.Get(c.Context)
+	ctx := terminal.Context(c.Context, terminal.ErrorOnly(term))
+	outcomes := runUpdates(ctx, gitRepo, langManager, logger, targets, jobs, terminal.NewProgress(term.Error(), false))
+
+	out := updateOutput{Results: make([]updateResultOutput, 0, len(outcomes))}
+	var failed int
+	for _, outcome := range outcomes {
+		update := outcome.update
+		result := updateResultOutput{
+			Command:         update.Command,
+			Package:         update.Package,
+			Status:          updateStatusUpToDate,
+			PreviousVersion: update.PreviousVersion,
+			Version:         update.Version,
+		}
+		switch {
+		case outcome.err != nil:
+			failed++
+			result.Status = updateStatusFailed
+			result.Error = strings.TrimSpace(outcome.err.Error())
+		case update.Updated:
+			result.Status = updateStatusUpdated
+		}
+		out.Results = append(out.Results, result)
+	}
+
+	if err := writeOutput(term, outputFormat(c), out); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return cli.Exit(color.RedString("Unable to update %d of %d package(s)", failed, len(outcomes)), 1)
+	}
+	return nil
+}
+
+// commandVersion returns the version of the package command with the given name or alias
+func commandVersion(cmdPackage subcommands, name string) string {
+	for _, command := range cmdPackage.Commands {
+		if strings.EqualFold(command.Name, name) {
+			return command.Version
+		}
+		for _, alias := range command.Aliases {
+			if strings.EqualFold(alias, name) {
+				return command.Version
+			}
+		}
+	}
+	return ""
+}
+
+// findUpdatePackageDir returns the directory of the package providing cmd
+func findUpdatePackageDir(ctx context.Context, langManager packages.LangManager, logger *slog.Logger, cmd string) (string, error) {
 	exec, _, err := findExec(ctx, langManager, cmd)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Command \"%s\" not found: %v", cmd, err))
-		return cli.Exit(color.RedString("Command \"%s\" not found. Try \"%s help\".\n", cmd, tools.Self()), 1)
+		return "", cli.Exit(color.RedString("Command \"%s\" not found. Try \"%s help\".\n", cmd, tools.Self()), 1)
 	}
 
 	logger.Debug(fmt.Sprintf("Command found: %s", filepath.Join(exec...)))
 
-	term.Spinner().Start("Attempting to update \"%s\" command...", cmd)
-
 	var repoDir string
 	logger.Debug("Searching for package repo")
 	if len(exec) == 1 {
@@ -86,14 +312,27 @@ func updatePackage(ctx context.Context, gitRepo git.Repository, langManager pack
 	}
 
 	if repoDir == "" {
-		term.Spinner().Fail()
 		logger.Error("Unable to find package directory")
-		return cli.Exit(color.RedString("unable to update, was it installed using %s", color.CyanString("\"akamai install\"")+"?"), 1)
+		return "", cli.Exit(color.RedString("unable to update, was it installed using %s", color.CyanString("\"akamai install\"")+"?"), 1)
 	}
 
 	logger.Debug(fmt.Sprintf("Repo found: %s", repoDir))
+	return repoDir, nil
+}
+
+// updatePackage updates the package in repoDir, which provides cmd
+func updatePackage(ctx context.Context, gitRepo git.Repository, langManager packages.LangManager, logger *slog.Logger, cmd, repoDir string) (packageUpdate, error) {
+	term := terminal.Get(ctx)
+	update := packageUpdate{Command: cmd, Package: filepath.Base(repoDir)}
+
+	term.Spinner().Start("Attempting to update \"%s\" command...", cmd)
+
+	pin := readPackagePin(repoDir)
+	if pin != "" {
+		logger.Debug(fmt.Sprintf("Package is pinned to: %s", pin))
+	}
 
-	err = gitRepo.Open(repoDir)
+	err := gitRepo.Open(repoDir)
 	if err != nil {
 		logger.Debug("Unable to open repo")
 
@@ -101,13 +340,11 @@ func updatePackage(ctx context.Context, gitRepo git.Repository, langManager pack
 		if err != nil {
 			term.Spinner().Fail()
 			logger.Error(fmt.Sprintf("Failed to read package: %v", err))
-			return cli.Exit(color.RedString("unable to update, there was an issue with the package repo: %v", err), 1)
+			return update, cli.Exit(color.RedString("unable to update, there was an issue with the package repo: %v", err), 1)
 		}
 
-		packageVersions := map[string]string{}
-		for _, command := range cmdPackage.Commands {
-			packageVersions[command.Name] = command.Version
-		}
+		update.PreviousVersion = commandVersion(cmdPackage, cmd)
+		update.Version = update.PreviousVersion
 
 		owner, repoName := extractOwnerAndRepo(tools.Githubize(cmd))
 		if owner == "" || repoName == "" {
@@ -115,103 +352,143 @@ func updatePackage(ctx context.Context, gitRepo git.Repository, langManager pack
 			msg := fmt.Sprintf("Unable to parse repository URL: %s", repoDir)
 			logger.Error(msg)
 			term.WriteError(msg)
-			return cli.Exit("Unable to install selected package", 1)
+			return update, cli.Exit("Unable to install selected package", 1)
 		}
 
-		var remotePackage subcommands
-		var fetchErr error
-		for _, branch := range []string{"main", "master"} {
-			url := buildRawGitHubURL(owner, repoName, branch)
-			remotePackage, fetchErr = readPackageFromGithub(url, filepath.Base(repoDir))
-			if fetchErr == nil {
-				break
-			}
-		}
+		remotePackage, fetchErr := fetchPackageConfig(owner, repoName, filepath.Base(repoDir), refCandidates(pin))
 		if fetchErr != nil {
 			term.Spinner().Fail()
 			logger.Error(fmt.Sprintf("Failed to read package from github: %v", fetchErr))
-			return cli.Exit(color.RedString("unable to update, there was an issue with fetching latest configuration file: %v", fetchErr), 1)
-		}
-
-		remoteVersions := map[string]string{}
-		for _, command := range remotePackage.Commands {
-			remoteVersions[command.Name] = command.Version
+			return update, cli.Exit(color.RedString("unable to update, there was an issue with fetching latest configuration file: %v", fetchErr), 1)
 		}
 
-		if reflect.DeepEqual(packageVersions, remoteVersions) {
+		if reflect.DeepEqual(commandVersions(cmdPackage), commandVersions(remotePackage)) {
 			term.Spinner().WarnOK()
 			debugMessage := fmt.Sprintf("command \"%s\" already up-to-date", cmd)
 			logger.Warn(debugMessage)
 			if _, err := term.Writeln(color.CyanString("%s", debugMessage)); err != nil {
 				term.WriteError(err.Error())
-				return err
+				return update, err
 			}
-			return nil
+			return update, nil
 		}
 
-		tempDir := filepath.Dir(repoDir) + "/.tmp_" + filepath.Base(repoDir)
+		tempDir := filepath.Join(filepath.Dir(repoDir), updateAsidePrefix+filepath.Base(repoDir))
 		logger.Debug(fmt.Sprintf("Moving package to temporary dir: %s", tempDir))
 		if err = os.Rename(repoDir, tempDir); err != nil {
 			term.Spinner().Fail()
 			logger.Error(fmt.Sprintf("Unable to move package to temporary dir: %v", err))
-			return cli.Exit(color.RedString("unable to update, there was an issue with the package repo: %v", err), 1)
+			return update, cli.Exit(color.RedString("unable to update, there was an issue with the package repo: %v", err), 1)
+		}
+
+		// the install may fall back to a source install, which replaces the package virtualenv
+		venvDir, err := moveVenvAside(filepath.Base(repoDir))
+		if err != nil {
+			term.Spinner().Fail()
+			if err := os.Rename(tempDir, repoDir); err != nil {
+				logger.Error(fmt.Sprintf("Unable to move package back to original dir: %v", err))
+			}
+			logger.Error(fmt.Sprintf("Unable to move package virtualenv to temporary dir: %v", err))
+			return update, cli.Exit(color.RedString("unable to update, there was an issue with the package virtualenv: %v", err), 1)
 		}
 
 		logger.Debug(fmt.Sprintf("Attempting to install package: %s", cmd))
-		_, err = installPackage(ctx, gitRepo, langManager, tools.Githubize(cmd))
+		_, err = installPackage(ctx, gitRepo, langManager, tools.Githubize(cmd), pin)
 		if err != nil {
 			term.Spinner().Fail()
+			if err := restoreVenv(filepath.Base(repoDir), venvDir); err != nil {
+				logger.Error(fmt.Sprintf("Unable to move package virtualenv back to original dir: %v", err))
+			}
 			if err := os.Rename(tempDir, repoDir); err != nil {
 				logger.Error(fmt.Sprintf("Unable to move package back to original dir: %v", err))
-				return cli.Exit(color.RedString("unable to update, there was an issue with the package repo: %v", err), 1)
+				return update, cli.Exit(color.RedString("unable to update, there was an issue with the package repo: %v", err), 1)
 			}
 			logger.Error(fmt.Sprintf("Failed to install package: %v", err))
-			return cli.Exit(color.RedString("unable to update: %v", err), 1)
+			return update, cli.Exit(color.RedString("unable to update: %v", err), 1)
 		}
 
-		if err := os.RemoveAll(tempDir); err != nil {
-			term.Spinner().Fail()
-			logger.Error(fmt.Sprintf("Unable to remove temporary dir: %v", err))
-			return cli.Exit(color.RedString("unable to update, there was an issue with the package repo: %v", err), 1)
+		if err := recordPackageHistory(ctx, filepath.Base(repoDir), cmdPackage, "", tempDir, venvDir); err != nil {
+			logger.Warn(fmt.Sprintf("Unable to keep previous install for rollback: %v", err))
+			if err := os.RemoveAll(tempDir); err != nil {
+				term.Spinner().Fail()
+				logger.Error(fmt.Sprintf("Unable to remove temporary dir: %v", err))
+				return update, cli.Exit(color.RedString("unable to update, there was an issue with the package repo: %v", err), 1)
+			}
+			if venvDir != "" {
+				if err := os.RemoveAll(venvDir); err != nil {
+					logger.Warn(fmt.Sprintf("Unable to remove temporary dir: %v", err))
+				}
+			}
 		}
 
+		update.Updated = true
+		update.Version = commandVersion(remotePackage, cmd)
 		term.Spinner().OK()
 		logger.Debug("Repo updated successfully")
 
-		return nil
+		return update, nil
+	}
+
+	cmdPackage, err := readPackage(repoDir)
+	if err != nil {
+		term.Spinner().Fail()
+		logger.Error(fmt.Sprintf("Failed to read package: %v", err))
+		return update, cli.Exit(color.RedString("unable to update, there was an issue with the package repo: %v", err), 1)
 	}
+	update.PreviousVersion = commandVersion(cmdPackage, cmd)
+	update.Version = update.PreviousVersion
 
-	err = updateRepo(ctx, gitRepo, logger, term, cmd)
+	var previousCommit string
+	if pin != "" {
+		previousCommit, err = updatePinnedRepo(ctx, gitRepo, logger, term, cmd, pin)
+	} else {
+		previousCommit, err = updateRepo(ctx, gitRepo, logger, term, cmd)
+	}
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to update repo: %v", err))
-		return err
+		return update, err
 	}
 
-	if ok, _ := installPackageDependencies(ctx, langManager, repoDir, logger); !ok {
+	if previousCommit != "" {
+		if err := recordPackageHistory(ctx, filepath.Base(repoDir), cmdPackage, previousCommit, "", ""); err != nil {
+			logger.Warn(fmt.Sprintf("Unable to keep previous install for rollback: %v", err))
+		}
+	}
+
+	ok, _ := installPackageDependencies(ctx, langManager, repoDir, logger)
+	if !ok {
 		term.Spinner().Fail()
 		logger.Debug("Error updating dependencies")
-		return cli.Exit("Unable to update command", 1)
+		return update, cli.Exit("Unable to update command", 1)
+	}
+
+	if previousCommit != "" {
+		update.Updated = true
+		if updatedPackage, err := readPackage(repoDir); err == nil {
+			update.Version = commandVersion(updatedPackage, cmd)
+		}
 	}
 
 	term.Spinner().OK()
 	logger.Debug("Repo updated successfully")
 
-	return nil
+	return update, nil
 }
 
-func updateRepo(ctx context.Context, gitRepo git.Repository, logger *slog.Logger, term terminal.Terminal, cmd string) error {
+// updateRepo pulls the latest changes of the package repository and returns the commit it was at before, if it changed
+func updateRepo(ctx context.Context, gitRepo git.Repository, logger *slog.Logger, term terminal.Terminal, cmd string) (string, error) {
 	w, err := gitRepo.Worktree()
 	if err != nil {
 		term.Spinner().Fail()
 		logger.Error("Unable to open repo")
-		return cli.Exit(color.RedString("unable to update, there was an issue with the package repo: %v", err), 1)
+		return "", cli.Exit(color.RedString("unable to update, there was an issue with the package repo: %v", err), 1)
 	}
 
 	if err := gitRepo.Reset(&gogit.ResetOptions{Mode: gogit.HardReset}); err != nil {
 		term.Spinner().Warn()
 		logger.Error(fmt.Sprintf("Unable to reset the branch changes: %v", err))
 		if _, err := term.Writeln(color.YellowString("unable to reset the branch changes, we will try to continue anyway: %v", err)); err != nil {
-			return err
+			return "", err
 		}
 	}
 
@@ -224,21 +501,30 @@ func updateRepo(ctx context.Context, gitRepo git.Repository, logger *slog.Logger
 	if errBeforePull != nil {
 		term.Spinner().Fail()
 		logger.Error(fmt.Sprintf("Fetch error: %v", errBeforePull))
-		return cli.Exit(color.RedString("Unable to fetch updates: %v", errBeforePull), 1)
+		return "", cli.Exit(color.RedString("Unable to fetch updates: %v", errBeforePull), 1)
+	}
+
+	// a rollback leaves the package at a detached commit, which cannot be pulled
+	if refBeforePull.Name() == plumbing.HEAD {
+		if err := checkoutDefaultBranch(gitRepo, logger); err != nil {
+			term.Spinner().Fail()
+			logger.Error(fmt.Sprintf("Unable to check out the default branch: %v", err))
+			return "", cli.Exit(color.RedString("Unable to fetch updates: %v", err), 1)
+		}
 	}
 
 	err = gitRepo.Pull(ctx, w)
 	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
 		term.Spinner().Fail()
 		logger.Error(fmt.Sprintf("Fetch error: %v", err))
-		return cli.Exit(color.RedString("%s", tools.CapitalizeFirstWord(err.Error())), 1)
+		return "", cli.Exit(color.RedString("%s", tools.CapitalizeFirstWord(err.Error())), 1)
 	}
 
 	ref, err := gitRepo.Head()
 	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
 		term.Spinner().Fail()
 		logger.Error(fmt.Sprintf("Fetch error: %v", err))
-		return cli.Exit(color.RedString("Unable to fetch updates: %v", err), 1)
+		return "", cli.Exit(color.RedString("Unable to fetch updates: %v", err), 1)
 	}
 
 	if refBeforePull.Hash() != ref.Hash() {
@@ -249,7 +535,7 @@ func updateRepo(ctx context.Context, gitRepo git.Repository, logger *slog.Logger
 		if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
 			term.Spinner().Fail()
 			logger.Error(fmt.Sprintf("Fetch error: %v", err))
-			return cli.Exit(color.RedString("Unable to fetch updates: %v", err), 1)
+			return "", cli.Exit(color.RedString("Unable to fetch updates: %v", err), 1)
 		}
 	} else {
 		term.Spinner().OK()
@@ -257,9 +543,105 @@ func updateRepo(ctx context.Context, gitRepo git.Repository, logger *slog.Logger
 		debugMessage := fmt.Sprintf("command \"%s\" already up-to-date", cmd)
 		logger.Warn(debugMessage)
 		if _, err := term.Writeln(color.CyanString("%s", debugMessage)); err != nil {
-			return err
+			return "", err
 		}
+		return "", nil
 	}
 
-	return nil
+	return refBeforePull.Hash().String(), nil
+}
+
+// checkoutDefaultBranch checks out the local branch the package was installed from
+func checkoutDefaultBranch(gitRepo git.Repository, logger *slog.Logger) error {
+	for _, branch := range defaultBranches {
+		name := plumbing.NewBranchReferenceName(branch)
+		if _, err := gitRepo.ResolveRevision(name.String()); err != nil {
+			continue
+		}
+		logger.Debug(fmt.Sprintf("Checking out branch %s", branch))
+		return gitRepo.Checkout(&gogit.CheckoutOptions{Branch: name, Force: true})
+	}
+	return fmt.Errorf("none of the default branches found: %s", strings.Join(defaultBranches, ", "))
+}
+
+// updatePinnedRepo fetches the remote and checks out the latest commit of the ref the package is pinned to.
+// For tags and commits this is a no-op unless the ref has been moved on the remote.
+// It returns the commit the repository was at before, if it changed.
+func updatePinnedRepo(ctx context.Context, gitRepo git.Repository, logger *slog.Logger, term terminal.Terminal, cmd, pin string) (string, error) {
+	refBeforeFetch, err := gitRepo.Head()
+	if err != nil {
+		term.Spinner().Fail()
+		logger.Error(fmt.Sprintf("Fetch error: %v", err))
+		return "", cli.Exit(color.RedString("Unable to fetch updates: %v", err), 1)
+	}
+
+	logger.Debug(fmt.Sprintf("Fetching from remote: %s", git.DefaultRemoteName))
+	if err := gitRepo.Fetch(ctx); err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		term.Spinner().Fail()
+		logger.Error(fmt.Sprintf("Fetch error: %v", err))
+		return "", cli.Exit(color.RedString("%s", tools.CapitalizeFirstWord(err.Error())), 1)
+	}
+
+	logger.Debug(fmt.Sprintf("Using pinned ref: %s", pin))
+	if err := checkoutRef(gitRepo, pin); err != nil {
+		term.Spinner().Fail()
+		logger.Error(fmt.Sprintf("Checkout error: %v", err))
+		return "", cli.Exit(color.RedString("%s", tools.CapitalizeFirstWord(err.Error())), 1)
+	}
+
+	ref, err := gitRepo.Head()
+	if err != nil {
+		term.Spinner().Fail()
+		logger.Error(fmt.Sprintf("Fetch error: %v", err))
+		return "", cli.Exit(color.RedString("Unable to fetch updates: %v", err), 1)
+	}
+
+	if refBeforeFetch.Hash() == ref.Hash() {
+		term.Spinner().OK()
+		logger.Debug(fmt.Sprintf("HEAD is the same as the pinned ref: %s", ref.Hash().String()))
+		debugMessage := fmt.Sprintf("command \"%s\" already up-to-date with %s", cmd, pin)
+		logger.Warn(debugMessage)
+		if _, err := term.Writeln(color.CyanString("%s", debugMessage)); err != nil {
+			return "", err
+		}
+		return "", nil
+	}
+
+	logger.Debug(fmt.Sprintf("HEAD moved: %s (old) vs %s (new)", refBeforeFetch.Hash().String(), ref.Hash().String()))
+	return refBeforeFetch.Hash().String(), nil
+}
+
+// moveVenvAside moves the virtualenv of a package to a temporary dir and returns its path, or an empty string if there is no virtualenv
+func moveVenvAside(pkgName string) (string, error) {
+	venvPath, err := tools.GetPkgVenvPath(pkgName)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(venvPath); os.IsNotExist(err) {
+		return "", nil
+	}
+
+	aside := filepath.Join(filepath.Dir(venvPath), updateAsidePrefix+pkgName)
+	if err := os.RemoveAll(aside); err != nil {
+		return "", err
+	}
+	if err := os.Rename(venvPath, aside); err != nil {
+		return "", err
+	}
+	return aside, nil
+}
+
+// restoreVenv undoes moveVenvAside, replacing any virtualenv created in the meantime
+func restoreVenv(pkgName, aside string) error {
+	venvPath, err := tools.GetPkgVenvPath(pkgName)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(venvPath); err != nil {
+		return err
+	}
+	if aside == "" {
+		return nil
+	}
+	return os.Rename(aside, venvPath)
 }
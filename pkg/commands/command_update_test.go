@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -12,6 +14,7 @@ import (
 	"github.com/akamai/cli/v2/pkg/color"
 	"github.com/akamai/cli/v2/pkg/config"
 	"github.com/akamai/cli/v2/pkg/git"
+	"github.com/akamai/cli/v2/pkg/log"
 	"github.com/akamai/cli/v2/pkg/packages"
 	"github.com/akamai/cli/v2/pkg/terminal"
 	"github.com/akamai/cli/v2/pkg/tools"
@@ -29,10 +32,11 @@ func TestCmdUpdate(t *testing.T) {
 	cliEchoBin := filepath.Join("testdata", ".akamai-cli", "src", "cli-echo", "bin", "akamai-echo")
 	tempTestDir := filepath.Join(".", "testdata", "temp")
 	tests := map[string]struct {
-		args      []string
-		init      func(*testing.T, *mocked)
-		teardown  func(*testing.T)
-		withError string
+		args       []string
+		globalArgs []string
+		init       func(*testing.T, *mocked)
+		teardown   func(*testing.T)
+		withError  string
 	}{
 		"update specific package": {
 			args: []string{"echo"},
@@ -61,6 +65,172 @@ func TestCmdUpdate(t *testing.T) {
 				m.term.On("OK").Return().Once()
 			},
 		},
+		"update specific package as json": {
+			args:       []string{"echo"},
+			globalArgs: []string{"--output", "json"},
+			init: func(_ *testing.T, m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.term.On("Error").Return(&bytes.Buffer{})
+
+				m.gitRepo.On("Open", cliEchoRepo).Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Reset", &gogit.ResetOptions{Mode: gogit.HardReset}).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.gitRepo.On("Pull", worktree).Return(nil)
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
+				m.gitRepo.On("CommitObject", plumbing.Hash{1}).Return(&object.Commit{}, nil).Once()
+
+				m.langManager.On("Install", cliEchoRepo,
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"echo"}, []string{""}).Return(nil).Once()
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, cliEchoBin).Return([]string{cliEchoBin}, nil).Once()
+
+				m.term.On("Printf", "%s\n", []interface{}{`{
+  "results": [
+    {
+      "command": "echo",
+      "package": "cli-echo",
+      "status": "updated",
+      "previous-version": "1.0.0",
+      "version": "1.0.0"
+    }
+  ]
+}`}).Return().Once()
+			},
+		},
+		"dry run of binary package update as json": {
+			args:       []string{"--dry-run", "echo"},
+			globalArgs: []string{"--output", "json"},
+			init: func(t *testing.T, m *mocked) {
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, cliEchoBin).Return([]string{cliEchoBin}, nil).Once()
+				configJSON, err := os.ReadFile(filepath.Join(cliEchoRepo, "cli.json"))
+				require.NoError(t, err)
+				h := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					output := strings.ReplaceAll(string(configJSON), "1.0.0", "9.9.9")
+					_, err = w.Write([]byte(output))
+					require.NoError(t, err)
+				}))
+				buildRawGitHubURL = func(owner, repo, branch string) string {
+					return fmt.Sprintf("%s/%s/%s/%s/cli.json", h.URL, owner, repo, branch)
+				}
+				m.gitRepo.On("Open", cliEchoRepo).Return(fmt.Errorf("oops")).Once()
+
+				m.term.On("Printf", "%s\n", []interface{}{`{
+  "plans": [
+    {
+      "action": "update",
+      "package": "cli-echo",
+      "repository": "https://github.com/akamai/cli-echo.git",
+      "strategy": "source",
+      "runtime": "go 1.14.0",
+      "previous-version": "1.0.0",
+      "version": "9.9.9",
+      "create-directories": [
+        "` + filepath.Join("testdata", ".akamai-cli", "history", "cli-echo") + `"
+      ],
+      "notes": [
+        "The package is reinstalled in ` + cliEchoRepo + ` and the current install is kept in ` + filepath.Join("testdata", ".akamai-cli", "history", "cli-echo") + ` for rollback."
+      ]
+    }
+  ]
+}`}).Return().Once()
+			},
+			teardown: func(t *testing.T) {
+				buildRawGitHubURL = func(owner, repo, branch string) string {
+					return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/cli.json", owner, repo, branch)
+				}
+				_, err := os.Stat(filepath.Join("testdata", ".akamai-cli", "history", "cli-echo"))
+				assert.True(t, os.IsNotExist(err))
+			},
+		},
+		"failed update as yaml": {
+			args:       []string{"not-found"},
+			globalArgs: []string{"--output", "yaml"},
+			init: func(_ *testing.T, m *mocked) {
+				m.term.On("Error").Return(&bytes.Buffer{})
+				m.term.On("Printf", "%s\n", []interface{}{`results:
+  - command: not-found
+    status: failed
+    error: Command "not-found" not found. Try "` + tools.Self() + ` help".`}).Return().Once()
+			},
+			withError: "Unable to update 1 of 1 package(s)",
+		},
+		"update several packages once each": {
+			args: []string{"echo", "e", "echo-invalid-json"},
+			init: func(_ *testing.T, m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.term.On("Error").Return(&bytes.Buffer{})
+				m.term.On("IsTTY").Return(false).Once()
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, cliEchoBin).Return([]string{cliEchoBin}, nil).Once()
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, filepath.Join(filepath.Dir(cliEchoBin), "akamai-e")).
+					Return([]string{filepath.Join(filepath.Dir(cliEchoBin), "akamai-e")}, nil).Once()
+
+				m.gitRepo.On("Open", cliEchoRepo).Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Reset", &gogit.ResetOptions{Mode: gogit.HardReset}).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.gitRepo.On("Pull", worktree).Return(nil)
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
+				m.gitRepo.On("CommitObject", plumbing.Hash{1}).Return(&object.Commit{}, nil).Once()
+				m.langManager.On("Install", cliEchoRepo,
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"echo"}, []string{""}).Return(nil).Once()
+
+				m.term.On("Writeln", []interface{}{color.YellowString("\nUpdate Summary:\n")}).Return(0, nil).Once()
+				m.term.On("Printf", "  %s: %s\n", []interface{}{color.BoldString("cli-echo"), color.GreenString("updated 1.0.0")}).Return().Once()
+				m.term.On("Printf", "  %s: %s\n", []interface{}{color.BoldString("echo-invalid-json"),
+					color.RedString("failed: %s", fmt.Sprintf("Command \"echo-invalid-json\" not found. Try \"%s help\".", tools.Self()))}).Return().Once()
+				m.term.On("Printf", "\n%d updated, %d up-to-date, %d failed\n", []interface{}{1, 0, 1}).Return().Once()
+			},
+			withError: "Unable to update 1 of 2 package(s)",
+		},
+		"update pinned package": {
+			args: []string{"echo"},
+			init: func(t *testing.T, m *mocked) {
+				require.NoError(t, writePackagePin(cliEchoRepo, "v1.2.0"))
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+
+				m.gitRepo.On("Open", cliEchoRepo).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{0}), nil).Once()
+				m.gitRepo.On("Fetch").Return(gogit.NoErrAlreadyUpToDate).Once()
+				m.gitRepo.On("ResolveRevision", "v1.2.0").Return(plumbing.Hash{1}, nil).Once()
+				m.gitRepo.On("Checkout", &gogit.CheckoutOptions{Hash: plumbing.Hash{1}, Force: true}).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference("", plumbing.Hash{1}), nil).Once()
+
+				m.term.On("Start", "Installing Dependencies...", []interface{}(nil)).Return().Once()
+				m.langManager.On("Install", cliEchoRepo,
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"echo"}, []string{""}).Return(nil).Once()
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, cliEchoBin).Return([]string{cliEchoBin}, nil).Once()
+				m.term.On("OK").Return()
+			},
+			teardown: func(t *testing.T) {
+				require.NoError(t, os.Remove(filepath.Join(cliEchoRepo, pinFileName)))
+			},
+		},
+		"update package after a rollback": {
+			args: []string{"echo"},
+			init: func(_ *testing.T, m *mocked) {
+				worktree := &gogit.Worktree{}
+				m.term.On("Spinner").Return(m.term)
+				m.term.On("Start", `Attempting to update "%s" command...`, []interface{}{"echo"}).Return().Once()
+
+				m.gitRepo.On("Open", cliEchoRepo).Return(nil).Once()
+				m.gitRepo.On("Worktree").Return(worktree, nil).Once()
+				m.gitRepo.On("Reset", &gogit.ResetOptions{Mode: gogit.HardReset}).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.HEAD, plumbing.Hash{0}), nil).Once()
+				m.gitRepo.On("ResolveRevision", "refs/heads/main").Return(plumbing.ZeroHash, fmt.Errorf("not found")).Once()
+				m.gitRepo.On("ResolveRevision", "refs/heads/master").Return(plumbing.Hash{1}, nil).Once()
+				m.gitRepo.On("Checkout", &gogit.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("master"), Force: true}).Return(nil).Once()
+				m.gitRepo.On("Pull", worktree).Return(nil).Once()
+				m.gitRepo.On("Head").Return(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), plumbing.Hash{2}), nil).Once()
+				m.gitRepo.On("CommitObject", plumbing.Hash{2}).Return(&object.Commit{}, nil).Once()
+
+				m.term.On("Start", "Installing Dependencies...", []interface{}(nil)).Return().Once()
+				m.langManager.On("Install", cliEchoRepo,
+					packages.LanguageRequirements{Go: "1.14.0"}, []string{"echo"}, []string{""}).Return(nil).Once()
+				m.langManager.On("FindExec", packages.LanguageRequirements{Go: "1.14.0"}, cliEchoBin).Return([]string{cliEchoBin}, nil).Once()
+				m.term.On("OK").Return()
+			},
+		},
 		"update all packages": {
 			args: []string{},
 			init: func(_ *testing.T, m *mocked) {
@@ -443,10 +613,18 @@ func TestCmdUpdate(t *testing.T) {
 			}))
 			defer srv.Close()
 			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", "./testdata"))
+			defer func() {
+				require.NoError(t, os.RemoveAll(filepath.Join("testdata", ".akamai-cli", "history")))
+			}()
 			m := &mocked{&terminal.Mock{}, &config.Mock{}, &git.MockRepo{}, &packages.Mock{}, nil}
 			command := &cli.Command{
 				Name:   "update",
 				Action: cmdUpdate(m.gitRepo, m.langManager),
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name: "dry-run",
+					},
+				},
 			}
 			app, ctx := setupTestApp(command, m)
 			app.Commands = append(app.Commands, &cli.Command{
@@ -454,6 +632,7 @@ func TestCmdUpdate(t *testing.T) {
 				Category: "Installed",
 			})
 			args := os.Args[0:1]
+			args = append(args, test.globalArgs...)
 			args = append(args, "update")
 			args = append(args, test.args...)
 
@@ -475,3 +654,41 @@ func TestCmdUpdate(t *testing.T) {
 	}
 
 }
+
+func TestUpdateRepoAfterRollback(t *testing.T) {
+	remoteDir := t.TempDir()
+	remote, err := gogit.PlainInit(remoteDir, false)
+	require.NoError(t, err)
+	remoteTree, err := remote.Worktree()
+	require.NoError(t, err)
+	commit := func(version string) plumbing.Hash {
+		require.NoError(t, os.WriteFile(filepath.Join(remoteDir, "cli.json"), []byte(`{"commands": [{"name": "echo", "version": "`+version+`"}]}`), 0644))
+		_, err := remoteTree.Add("cli.json")
+		require.NoError(t, err)
+		hash, err := remoteTree.Commit(version, &gogit.CommitOptions{Author: &object.Signature{Name: "test", Email: "test@example.com"}})
+		require.NoError(t, err)
+		return hash
+	}
+	first := commit("1.0.0")
+	commit("1.1.0")
+
+	ctx := terminal.Context(context.Background(), &terminal.Mock{})
+	logger := log.FromContext(ctx)
+	packageDir := filepath.Join(t.TempDir(), "cli-echo")
+	repo := git.NewRepository()
+	require.NoError(t, repo.Clone(ctx, packageDir, remoteDir, false, nil))
+
+	require.NoError(t, checkoutHistoryCommit(ctx, repo, &packages.Mock{}, logger, packageDir, historyEntry{Commit: first.String(), Venv: true}))
+	head, err := repo.Head()
+	require.NoError(t, err)
+	require.Equal(t, plumbing.HEAD, head.Name(), "rollback leaves a detached HEAD")
+	third := commit("1.2.0")
+
+	previous, err := updateRepo(ctx, repo, logger, terminal.Get(ctx), "echo")
+	require.NoError(t, err)
+	assert.Equal(t, first.String(), previous)
+	head, err = repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, plumbing.NewBranchReferenceName("master"), head.Name())
+	assert.Equal(t, third, head.Hash())
+}
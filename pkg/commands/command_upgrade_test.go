@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
@@ -14,6 +15,7 @@ import (
 	"github.com/akamai/cli/v2/pkg/config"
 	"github.com/akamai/cli/v2/pkg/terminal"
 	"github.com/akamai/cli/v2/pkg/version"
+	"github.com/go-ini/ini"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -26,6 +28,8 @@ func mockIsTTY(term *terminal.Mock, isTTY bool) {
 
 func mockGetAndUpdateLastUpgradeCheck(cfg *config.Mock, lastUpgradeCheckValue string) {
 	cfg.On("GetValue", "cli", "last-upgrade-check").Return(lastUpgradeCheckValue, true).Once()
+	cfg.On("GetValue", "cli", "lock-timeout").Return("", false).Once()
+	cfg.On("Reload").Return(nil).Once()
 	cfg.On("SetValue", "cli", "last-upgrade-check", time.Now().Format(time.RFC3339)).Return().Once()
 	cfg.On("Save").Return(nil).Once()
 }
@@ -53,6 +57,7 @@ func mockConfirmUpgrade(term *terminal.Mock, latestVersion, currentVersion strin
 }
 
 func TestCmdUpgrade(t *testing.T) {
+	t.Setenv("AKAMAI_CLI_HOME", t.TempDir())
 	binURLRegexp := regexp.MustCompile(`/releases/download/\d+\.\d+\.\d+/akamai-\d+\.\d+\.\d+-[A-Za-z\d]+(\.exe)?$`)
 
 	tests := map[string]struct {
@@ -370,6 +375,7 @@ func Test_checkUpgradeVersion(t *testing.T) {
 			expectedResult: "2.0.0",
 		},
 	}
+	t.Setenv("AKAMAI_CLI_HOME", t.TempDir())
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			term := terminal.Mock{}
@@ -388,3 +394,34 @@ func Test_checkUpgradeVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckUpgradeVersionKeepsConcurrentConfigChanges(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("AKAMAI_CLI_HOME", home)
+	t.Setenv("AKAMAI_CLI_SYSTEM_CONFIG", filepath.Join(home, "system"))
+	configPath := filepath.Join(home, ".akamai-cli", "config")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0700))
+	require.NoError(t, os.WriteFile(configPath, []byte("[cli]\nlast-upgrade-check = never\n"), 0600))
+	cfg, err := config.NewIni()
+	require.NoError(t, err)
+
+	// another process, such as 'akamai config set', writes the config after this one loaded it
+	require.NoError(t, os.WriteFile(configPath, []byte("[cli]\nlast-upgrade-check = never\nproxy = http://proxy:3128\n"), 0600))
+
+	term := &terminal.Mock{}
+	mockIsTTY(term, true)
+	ctx := terminal.Context(context.Background(), term)
+	ctx = config.Context(ctx, cfg)
+	vp := &mockVersionProvider{}
+	vp.On("getLatestReleaseVersion", ctx).Return("2.0.0").Once()
+	vp.On("getCurrentVersion").Return("2.0.0").Once()
+
+	assert.Equal(t, "2.0.0", checkUpgradeVersion(ctx, false, vp))
+
+	saved, err := ini.Load(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy:3128", saved.Section("cli").Key("proxy").String())
+	assert.NotEqual(t, "never", saved.Section("cli").Key("last-upgrade-check").String())
+	term.AssertExpectations(t)
+	vp.AssertExpectations(t)
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/akamai/cli/v2/pkg/autocomplete"
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/edgerc"
+	"github.com/urfave/cli/v2"
+)
+
+// CompleteGlobalFlags completes the values of the --section and --accountkey flags of the akamai app,
+// and everything else as autocomplete.Default does.
+// It is kept out of the autocomplete package, which package binaries use, as it needs the config and the credentials file.
+func CompleteGlobalFlags(c *cli.Context) {
+	switch {
+	case completesFlagValue(os.Args, "section", "s"):
+		completeEdgercSections(c)
+	case completesFlagValue(os.Args, "accountkey", "account-key"):
+		completeAccountAliases(c)
+	default:
+		autocomplete.Default(c)
+	}
+}
+
+// completeEdgercSections completes the section names of the credentials file given with the --edgerc flag, or of the one in the home directory
+func completeEdgercSections(c *cli.Context) {
+	path := c.String("edgerc")
+	if path == "" {
+		var err error
+		if path, err = edgerc.DefaultPath(); err != nil {
+			return
+		}
+	}
+	file, err := edgerc.Load(path)
+	if err != nil {
+		return
+	}
+	for _, section := range file.Sections() {
+		_, _ = fmt.Fprintln(c.App.Writer, section)
+	}
+}
+
+// completeAccountAliases completes the aliases of the account switch keys defined in the config
+func completeAccountAliases(c *cli.Context) {
+	for _, account := range config.Accounts(config.Get(c.Context)) {
+		_, _ = fmt.Fprintln(c.App.Writer, account.Alias)
+	}
+}
+
+// completesFlagValue checks whether the completion is requested for the value of one of given flags
+func completesFlagValue(args []string, names ...string) bool {
+	if len(args) < 3 || args[len(args)-1] != "--"+cli.BashCompletionFlag.Names()[0] {
+		return false
+	}
+	prev := args[len(args)-2]
+	for _, name := range names {
+		if prev == "--"+name || prev == "-"+name {
+			return true
+		}
+	}
+	return false
+}
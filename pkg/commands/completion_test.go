@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestCompleteGlobalFlags(t *testing.T) {
+	edgercPath := filepath.Join(t.TempDir(), ".edgerc")
+	require.NoError(t, os.WriteFile(edgercPath, []byte("[default]\nhost = a\n[prod]\nhost = b\n"), 0600))
+
+	tests := map[string]struct {
+		args     []string
+		expected string
+	}{
+		"section flag": {
+			args:     []string{"akamai", "--edgerc", edgercPath, "--section", "--generate-bash-completion"},
+			expected: "default\nprod\n",
+		},
+		"accountkey flag": {
+			args:     []string{"akamai", "--accountkey", "--generate-bash-completion"},
+			expected: "acme\nbeta\n",
+		},
+		"other completion": {
+			args:     []string{"akamai", "--generate-bash-completion"},
+			expected: "list\n--edgerc\n--section\n--accountkey\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			args := os.Args
+			defer func() {
+				os.Args = args
+			}()
+			os.Args = test.args
+
+			cfg := &config.Mock{}
+			cfg.On("Values").Return(map[string]map[string]string{
+				"account beta": {"key": "1-BETA"},
+				"account acme": {"key": "1-ABC:1-2XYZ", "description": "ACME production"},
+				"context prod": {"account-key": "acme"},
+			})
+			outbuf := &bytes.Buffer{}
+			app := cli.NewApp()
+			app.Writer = outbuf
+			app.Commands = []*cli.Command{{Name: "list"}}
+			app.Flags = []cli.Flag{&cli.StringFlag{Name: "edgerc"}, &cli.StringFlag{Name: "section"}, &cli.StringFlag{Name: "accountkey"}}
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.String("edgerc", "", "")
+			require.NoError(t, fs.Parse([]string{"--edgerc", edgercPath}))
+			ctx := cli.NewContext(app, fs, nil)
+			ctx.Context = config.Context(context.Background(), cfg)
+
+			CompleteGlobalFlags(ctx)
+
+			assert.Equal(t, test.expected, outbuf.String())
+		})
+	}
+}
@@ -0,0 +1,416 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/akamai/cli/v2/pkg/color"
+	"github.com/akamai/cli/v2/pkg/git"
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/packages"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/akamai/cli/v2/pkg/tools"
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli/v2"
+)
+
+// install strategies reported by dry runs
+const (
+	installStrategyBinary = "binary"
+	installStrategySource = "source"
+	installStrategyBundle = "bundle"
+)
+
+type (
+	// dryRunPlan describes the changes an install, update or uninstall of a single package would make
+	dryRunPlan struct {
+		Action          string   `json:"action" yaml:"action"`
+		Package         string   `json:"package" yaml:"package"`
+		Repository      string   `json:"repository,omitempty" yaml:"repository,omitempty"`
+		Ref             string   `json:"ref,omitempty" yaml:"ref,omitempty"`
+		Strategy        string   `json:"strategy,omitempty" yaml:"strategy,omitempty"`
+		Runtime         string   `json:"runtime,omitempty" yaml:"runtime,omitempty"`
+		PreviousVersion string   `json:"previous-version,omitempty" yaml:"previous-version,omitempty"`
+		Version         string   `json:"version,omitempty" yaml:"version,omitempty"`
+		CreateDirs      []string `json:"create-directories,omitempty" yaml:"create-directories,omitempty"`
+		RemoveDirs      []string `json:"remove-directories,omitempty" yaml:"remove-directories,omitempty"`
+		AddCommands     []string `json:"add-commands,omitempty" yaml:"add-commands,omitempty"`
+		RemoveCommands  []string `json:"remove-commands,omitempty" yaml:"remove-commands,omitempty"`
+		Notes           []string `json:"notes,omitempty" yaml:"notes,omitempty"`
+		Error           string   `json:"error,omitempty" yaml:"error,omitempty"`
+	}
+
+	// dryRunOutput is the document written by dry runs in JSON and YAML output formats
+	dryRunOutput struct {
+		Plans []dryRunPlan `json:"plans" yaml:"plans"`
+	}
+)
+
+// dry run actions
+const (
+	dryRunInstall   = "install"
+	dryRunUpdate    = "update"
+	dryRunUninstall = "uninstall"
+)
+
+// planInstall resolves the repository and cli.json of a package and describes how it would be installed.
+// Configuration of packages outside GitHub can only be read after cloning, so their runtime is not reported.
+func planInstall(ctx context.Context, repo, ref string) (dryRunPlan, error) {
+	logger := log.FromContext(ctx)
+	plan := dryRunPlan{Action: dryRunInstall, Repository: repo, Ref: ref}
+
+	owner, repoName := extractOwnerAndRepo(repo)
+	if owner == "" || repoName == "" {
+		plan.Package = repo
+		return plan, fmt.Errorf("unable to parse repository URL: %s", repo)
+	}
+	plan.Package = repoName
+
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return plan, err
+	}
+	packageDir := filepath.Join(srcPath, repoName)
+	if _, err := os.Stat(packageDir); err == nil {
+		plan.Notes = append(plan.Notes, fmt.Sprintf("Package directory already exists (%s), nothing would be installed. To reinstall this package, first run 'akamai uninstall' command.", packageDir))
+		return plan, nil
+	}
+
+	if !strings.HasPrefix(repo, "https://github.com/") {
+		plan.Strategy = installStrategySource
+		plan.CreateDirs = []string{packageDir}
+		plan.Notes = append(plan.Notes, "The package configuration is only available after cloning the repository, the runtime and commands are determined then.")
+		return plan, nil
+	}
+
+	logger.Debug(fmt.Sprintf("Fetching package configuration of %s", repo))
+	cmdPackage, err := fetchPackageConfig(owner, repoName, repoName, refCandidates(ref))
+	if err != nil {
+		return plan, fmt.Errorf("unable to fetch package configuration: %w", err)
+	}
+	plan.Runtime = runtimeDescription(cmdPackage.Requirements)
+	plan.Version = packageVersion(cmdPackage)
+	plan.AddCommands = commandNames(cmdPackage)
+	plan.CreateDirs = []string{packageDir}
+
+	if isBinary(cmdPackage) {
+		plan.Strategy = installStrategyBinary
+		plan.CreateDirs = append(plan.CreateDirs, filepath.Join(packageDir, "bin"))
+		if !hasBinaryVerification(cmdPackage) {
+			plan.Notes = append(plan.Notes, "If the binaries cannot be downloaded, the repository is cloned and built from source.")
+		}
+		return plan, nil
+	}
+
+	plan.Strategy = installStrategySource
+	venvDir, err := plannedVenvDir(cmdPackage.Requirements, repoName)
+	if err != nil {
+		return plan, err
+	}
+	if venvDir != "" {
+		plan.CreateDirs = append(plan.CreateDirs, venvDir)
+	}
+	return plan, nil
+}
+
+// planLockedInstall describes how a package from a lock file would be installed
+func planLockedInstall(ctx context.Context, gitRepo git.Repository, pkg lockedPackage) (dryRunPlan, error) {
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return dryRunPlan{Action: dryRunInstall, Package: pkg.Name}, err
+	}
+	packageDir := filepath.Join(srcPath, pkg.Name)
+	if _, err := os.Stat(packageDir); err == nil {
+		plan := dryRunPlan{Action: dryRunInstall, Package: pkg.Name, Repository: pkg.Repository, Ref: pkg.Commit}
+		if isLockedRevisionInstalled(gitRepo, packageDir, pkg) {
+			plan.Notes = append(plan.Notes, "Package is already installed at the locked revision.")
+		} else {
+			plan.Notes = append(plan.Notes, "Package is installed at a different revision than locked, nothing would be installed.")
+		}
+		return plan, nil
+	}
+	return planInstall(ctx, pkg.Repository, pkg.Commit)
+}
+
+// planUpdate describes how the package in repoDir, which provides cmd, would be updated.
+// Git repositories are not fetched, the latest cli.json is read from GitHub instead.
+func planUpdate(ctx context.Context, gitRepo git.Repository, cmd, repoDir string) (dryRunPlan, error) {
+	logger := log.FromContext(ctx)
+	name := filepath.Base(repoDir)
+	pin := readPackagePin(repoDir)
+	plan := dryRunPlan{Action: dryRunUpdate, Package: name, Ref: pin}
+
+	cmdPackage, err := readPackage(repoDir)
+	if err != nil {
+		return plan, fmt.Errorf("unable to read package: %w", err)
+	}
+	plan.PreviousVersion = commandVersion(cmdPackage, cmd)
+
+	if err := gitRepo.Open(repoDir); err != nil {
+		logger.Debug(fmt.Sprintf("Package %s is not a git repository, checking the released cli.json", name))
+		plan.Repository = tools.Githubize(cmd)
+		owner, repoName := extractOwnerAndRepo(plan.Repository)
+		if owner == "" || repoName == "" {
+			return plan, fmt.Errorf("unable to parse repository URL: %s", plan.Repository)
+		}
+		remotePackage, err := fetchPackageConfig(owner, repoName, name, refCandidates(pin))
+		if err != nil {
+			return plan, fmt.Errorf("unable to fetch latest configuration file: %w", err)
+		}
+		plan.Version = commandVersion(remotePackage, cmd)
+		plan.Runtime = runtimeDescription(remotePackage.Requirements)
+		if reflect.DeepEqual(commandVersions(cmdPackage), commandVersions(remotePackage)) {
+			plan.Notes = append(plan.Notes, fmt.Sprintf("Command \"%s\" is already up-to-date.", cmd))
+			return plan, nil
+		}
+
+		plan.Strategy = installStrategySource
+		if isBinary(remotePackage) {
+			plan.Strategy = installStrategyBinary
+		}
+		historyDir, err := getPackageHistoryPath(name)
+		if err != nil {
+			return plan, err
+		}
+		if _, err := os.Stat(historyDir); err != nil {
+			plan.CreateDirs = append(plan.CreateDirs, historyDir)
+		}
+		if plan.Strategy == installStrategySource {
+			venvDir, err := plannedVenvDir(remotePackage.Requirements, name)
+			if err != nil {
+				return plan, err
+			}
+			if venvDir != "" {
+				plan.CreateDirs = append(plan.CreateDirs, venvDir)
+			}
+		}
+		plan.AddCommands, plan.RemoveCommands = diffCommands(cmdPackage, remotePackage)
+		plan.Notes = append(plan.Notes, fmt.Sprintf("The package is reinstalled in %s and the current install is kept in %s for rollback.", repoDir, historyDir))
+		return plan, nil
+	}
+
+	plan.Strategy = installStrategySource
+	plan.Repository, err = gitRepo.RemoteURL(git.DefaultRemoteName)
+	if err != nil {
+		return plan, fmt.Errorf("unable to resolve repository: %w", err)
+	}
+
+	remotePackage := cmdPackage
+	owner, repoName := extractOwnerAndRepo(plan.Repository)
+	if strings.Contains(plan.Repository, "github.com") && owner != "" && repoName != "" {
+		if remotePackage, err = fetchPackageConfig(owner, repoName, name, refCandidates(pin)); err != nil {
+			return plan, fmt.Errorf("unable to fetch latest configuration file: %w", err)
+		}
+		plan.Version = commandVersion(remotePackage, cmd)
+		plan.AddCommands, plan.RemoveCommands = diffCommands(cmdPackage, remotePackage)
+	} else {
+		plan.Notes = append(plan.Notes, "The latest package configuration is only available after fetching the repository, the installed one is shown.")
+	}
+	plan.Runtime = runtimeDescription(remotePackage.Requirements)
+
+	venvDir, err := plannedVenvDir(remotePackage.Requirements, name)
+	if err != nil {
+		return plan, err
+	}
+	if venvDir != "" {
+		plan.CreateDirs = append(plan.CreateDirs, venvDir)
+	}
+	plan.Notes = append(plan.Notes, "The repository is pulled and the package dependencies are reinstalled if it has changed.")
+	return plan, nil
+}
+
+// planUninstall describes which directories and commands the uninstall of the package providing cmd would remove
+func planUninstall(ctx context.Context, langManager packages.LangManager, cmd string) (dryRunPlan, error) {
+	plan := dryRunPlan{Action: dryRunUninstall, Package: cmd}
+
+	exec, _, err := findExec(ctx, langManager, cmd)
+	if err != nil {
+		if !errors.Is(err, packages.ErrNoExeFound) {
+			return plan, fmt.Errorf("command \"%s\" not found. Try \"%s help\" : %v", cmd, tools.Self(), err)
+		}
+		home, err := homedir.Dir()
+		if err != nil {
+			return plan, fmt.Errorf("no home directory detected: %v", err)
+		}
+		if dir := findPackageDirWithoutExec(home, cmd); dir != "" {
+			plan.RemoveDirs = []string{dir}
+			return plan, nil
+		}
+		return plan, fmt.Errorf("command \"%s\" not found. Try \"%s help\"", cmd, tools.Self())
+	}
+
+	var repoDir string
+	if len(exec) > 0 {
+		repoDir = findPackageDir(filepath.Dir(exec[len(exec)-1]))
+	}
+	if repoDir == "" {
+		return plan, errors.New("unable to uninstall, was it installed using " + color.CyanString("\"akamai install\"") + "?")
+	}
+	plan.Package = filepath.Base(repoDir)
+	plan.RemoveDirs = []string{repoDir}
+
+	if cmdPackage, err := readPackage(repoDir); err == nil {
+		plan.RemoveCommands = commandNames(cmdPackage)
+		plan.PreviousVersion = packageVersion(cmdPackage)
+	}
+
+	venvPath, err := tools.GetPkgVenvPath(fmt.Sprintf("cli-%s", cmd))
+	if err != nil {
+		return plan, err
+	}
+	historyPath, err := getPackageHistoryPath(plan.Package)
+	if err != nil {
+		return plan, err
+	}
+	for _, dir := range []string{venvPath, historyPath} {
+		if _, err := os.Stat(dir); err == nil {
+			plan.RemoveDirs = append(plan.RemoveDirs, dir)
+		}
+	}
+	return plan, nil
+}
+
+// plannedVenvDir returns the virtual environment directory that installing the package would create, if any
+func plannedVenvDir(reqs packages.LanguageRequirements, name string) (string, error) {
+	if lang, _ := reqs.Runtime(); lang != packages.Python {
+		return "", nil
+	}
+	venvDir, err := tools.GetPkgVenvPath(name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(venvDir); err == nil {
+		return "", nil
+	}
+	return venvDir, nil
+}
+
+// runtimeDescription returns the runtime a package is installed with, as the language and its minimum version
+func runtimeDescription(reqs packages.LanguageRequirements) string {
+	lang, version := reqs.Runtime()
+	if lang == packages.Undefined {
+		return "unknown"
+	}
+	if version == "" || version == "*" {
+		return lang
+	}
+	return fmt.Sprintf("%s %s", lang, version)
+}
+
+// commandNames returns the names of all commands of a package
+func commandNames(cmdPackage subcommands) []string {
+	names := make([]string, 0, len(cmdPackage.Commands))
+	for _, cmd := range cmdPackage.Commands {
+		names = append(names, cmd.Name)
+	}
+	return names
+}
+
+// commandVersions maps the commands of a package to their versions
+func commandVersions(cmdPackage subcommands) map[string]string {
+	versions := make(map[string]string, len(cmdPackage.Commands))
+	for _, cmd := range cmdPackage.Commands {
+		versions[cmd.Name] = cmd.Version
+	}
+	return versions
+}
+
+// packageVersion returns the version of the first command of a package
+func packageVersion(cmdPackage subcommands) string {
+	if len(cmdPackage.Commands) == 0 {
+		return ""
+	}
+	return cmdPackage.Commands[0].Version
+}
+
+// diffCommands returns the commands added and removed between two versions of a package
+func diffCommands(current, latest subcommands) ([]string, []string) {
+	currentVersions, latestVersions := commandVersions(current), commandVersions(latest)
+	var added, removed []string
+	for _, name := range commandNames(latest) {
+		if _, ok := currentVersions[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	for _, name := range commandNames(current) {
+		if _, ok := latestVersions[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	return added, removed
+}
+
+// writeDryRun prints the plans of a dry run and fails if any of them could not be made
+func writeDryRun(c *cli.Context, plans []dryRunPlan) error {
+	term := terminal.Get(c.Context)
+
+	var failed int
+	for _, plan := range plans {
+		if plan.Error != "" {
+			failed++
+		}
+	}
+
+	if isStructuredOutput(c) {
+		if err := writeOutput(term, outputFormat(c), dryRunOutput{Plans: plans}); err != nil {
+			return err
+		}
+	} else {
+		for _, plan := range plans {
+			printDryRunPlan(term, plan)
+		}
+		term.Printf("%s\n", color.CyanString("Dry run, no changes were made."))
+	}
+
+	if failed > 0 {
+		return cli.Exit(color.RedString("Unable to %s %d of %d package(s)", plans[0].Action, failed, len(plans)), 1)
+	}
+	return nil
+}
+
+func printDryRunPlan(term terminal.Terminal, plan dryRunPlan) {
+	term.Printf("%s\n", color.YellowString("Would %s %s:", plan.Action, color.BoldString("%s", plan.Package)))
+	if plan.Error != "" {
+		term.Printf("  %s\n", color.RedString("Error: %s", plan.Error))
+		return
+	}
+
+	field := func(name, value string) {
+		if value != "" {
+			term.Printf("  %s: %s\n", name, value)
+		}
+	}
+	field("Repository", plan.Repository)
+	field("Ref", plan.Ref)
+	field("Strategy", plan.Strategy)
+	field("Runtime", plan.Runtime)
+	switch {
+	case plan.PreviousVersion != "" && plan.Version != "" && plan.PreviousVersion != plan.Version:
+		field("Version", fmt.Sprintf("%s -> %s", plan.PreviousVersion, plan.Version))
+	case plan.Version != "":
+		field("Version", plan.Version)
+	default:
+		field("Version", plan.PreviousVersion)
+	}
+
+	for _, dir := range plan.CreateDirs {
+		term.Printf("  %s %s\n", color.GreenString("+ directory"), dir)
+	}
+	for _, dir := range plan.RemoveDirs {
+		term.Printf("  %s %s\n", color.RedString("- directory"), dir)
+	}
+	for _, cmd := range plan.AddCommands {
+		term.Printf("  %s %s\n", color.GreenString("+ command"), cmd)
+	}
+	for _, cmd := range plan.RemoveCommands {
+		term.Printf("  %s %s\n", color.RedString("- command"), cmd)
+	}
+	for _, note := range plan.Notes {
+		term.Printf("  %s\n", color.CyanString("%s", note))
+	}
+}
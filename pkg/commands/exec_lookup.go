@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/akamai/cli/v2/pkg/tools"
+)
+
+var errNotExecutable = errors.New("not an executable file")
+
+// getPackageBinDirs returns the directories searched for package executables: the package directories, followed by their bin directories
+func getPackageBinDirs() []string {
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return nil
+	}
+	dirs, _ := filepath.Glob(filepath.Join(srcPath, "*"))
+	binDirs, _ := filepath.Glob(filepath.Join(srcPath, "*", "bin"))
+	return append(dirs, binDirs...)
+}
+
+// lookPath searches the directories for an executable named file, as exec.LookPath does for the directories of PATH.
+// The process environment is neither read for the directories nor changed, so that commands can be resolved concurrently.
+// Like exec.LookPath, an executable found in a relative directory is returned along with exec.ErrDot.
+func lookPath(file string, dirs []string) (string, error) {
+	for _, dir := range dirs {
+		for _, name := range executableNames(file) {
+			path := filepath.Join(dir, name)
+			if err := checkExecutable(path); err != nil {
+				continue
+			}
+			if !filepath.IsAbs(path) {
+				return path, &exec.Error{Name: file, Err: exec.ErrDot}
+			}
+			return path, nil
+		}
+	}
+	return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+}
+
+// executableNames returns the file names an executable may have. On Windows, these are the name with each extension of PATHEXT.
+func executableNames(file string) []string {
+	if runtime.GOOS != "windows" {
+		return []string{file}
+	}
+	pathExt := os.Getenv("PATHEXT")
+	if pathExt == "" {
+		pathExt = ".com;.exe;.bat;.cmd"
+	}
+	names := make([]string, 0)
+	if filepath.Ext(file) != "" {
+		names = append(names, file)
+	}
+	for _, ext := range strings.Split(strings.ToLower(pathExt), ";") {
+		if ext != "" {
+			names = append(names, file+ext)
+		}
+	}
+	return names
+}
+
+func checkExecutable(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() || (runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0) {
+		return errNotExecutable
+	}
+	return nil
+}
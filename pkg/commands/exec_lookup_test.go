@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executables are found by extension on windows")
+	}
+	dir := t.TempDir()
+	binDir := filepath.Join(dir, "bin")
+	require.NoError(t, os.MkdirAll(filepath.Join(binDir, "akamai-dir"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "akamai-echo"), []byte("#!/bin/sh"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "akamai-echo"), []byte("#!/bin/sh"), 0755))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	relDir, err := filepath.Rel(wd, binDir)
+	require.NoError(t, err)
+	path := os.Getenv("PATH")
+
+	tests := map[string]struct {
+		file      string
+		dirs      []string
+		expected  string
+		withError error
+	}{
+		"executable in second directory": {
+			file:     "akamai-echo",
+			dirs:     []string{dir, binDir},
+			expected: filepath.Join(binDir, "akamai-echo"),
+		},
+		"not executable": {
+			file:      "akamai-echo",
+			dirs:      []string{dir},
+			withError: exec.ErrNotFound,
+		},
+		"directory": {
+			file:      "akamai-dir",
+			dirs:      []string{binDir},
+			withError: exec.ErrNotFound,
+		},
+		"relative directory": {
+			file:      "akamai-echo",
+			dirs:      []string{relDir},
+			expected:  filepath.Join(relDir, "akamai-echo"),
+			withError: exec.ErrDot,
+		},
+		"no directories": {
+			file:      "akamai-echo",
+			withError: exec.ErrNotFound,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := lookPath(test.file, test.dirs)
+			assert.Equal(t, test.expected, res)
+			if test.withError != nil {
+				assert.True(t, errors.Is(err, test.withError), "want: %s; got: %s", test.withError, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, path, os.Getenv("PATH"))
+		})
+	}
+
+	t.Run("concurrent lookups", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res, err := lookPath("akamai-echo", []string{dir, binDir})
+				assert.NoError(t, err)
+				assert.Equal(t, filepath.Join(binDir, "akamai-echo"), res)
+			}()
+		}
+		wg.Wait()
+	})
+}
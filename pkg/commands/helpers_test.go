@@ -16,6 +16,7 @@ import (
 	"github.com/akamai/cli/v2/pkg/log"
 	"github.com/akamai/cli/v2/pkg/packages"
 	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/akamai/cli/v2/pkg/vault"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v2"
 )
@@ -53,6 +54,7 @@ func TestMain(m *testing.M) {
 			}
 		}
 	}
+	buildGitHubCommitURL = unresolvableGitHubCommitURL
 	exitCode := m.Run()
 	if err := os.RemoveAll(binaryPath); err != nil {
 		log.Error(err.Error())
@@ -95,6 +97,7 @@ func setupTestApp(command *cli.Command, m *mocked) (*cli.App, context.Context) {
 	cli.OsExiter = func(_ int) {}
 	ctx := terminal.Context(context.Background(), m.term)
 	ctx = config.Context(ctx, m.cfg)
+	ctx = vault.Context(ctx, vault.NewSession(filepath.Join("testdata", "missing.vault"), vault.PromptPassphrase(m.term)))
 	app := cli.NewApp()
 	app.Commands = []*cli.Command{command}
 	app.Flags = []cli.Flag{
@@ -108,6 +111,10 @@ func setupTestApp(command *cli.Command, m *mocked) (*cli.App, context.Context) {
 			Usage:   "edgerc section name passed to executed commands, defaults to 'default'",
 			Aliases: []string{"s"},
 		},
+		&cli.StringFlag{
+			Name:  "output",
+			Usage: "output format of built-in commands",
+		},
 	}
 	return app, ctx
 }
@@ -197,3 +204,8 @@ func createIfNotExists(dir string, perm os.FileMode) error {
 	err := os.MkdirAll(dir, perm)
 	return err
 }
+
+// unresolvableGitHubCommitURL keeps tests from resolving commits against GitHub, unless they serve the commit themselves
+func unresolvableGitHubCommitURL(_, _, _ string) string {
+	return "http://127.0.0.1:0/commits"
+}
@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/color"
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/akamai/cli/v2/pkg/tools"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	homeLockFileName = ".lock"
+
+	installMarkerSuffix = ".installing"
+	updateAsidePrefix   = ".tmp_"
+	rollbackAsidePrefix = ".rollback_"
+)
+
+// withHomeLock runs the action while holding the advisory lock on the CLI home directory,
+// so that parallel akamai processes do not modify installed packages or config at the same time
+func withHomeLock(action cli.ActionFunc) cli.ActionFunc {
+	return func(c *cli.Context) error {
+		if c.Bool("dry-run") {
+			return action(c)
+		}
+
+		lock, err := LockCliHome(c.Context)
+		if err != nil {
+			return cli.Exit(color.RedString("Unable to lock akamai cli home: %v", err), 1)
+		}
+		defer func() {
+			if err := lock.Unlock(); err != nil {
+				log.FromContext(c.Context).Warn(fmt.Sprintf("Unable to release lock: %v", err))
+			}
+		}()
+
+		if err := recoverCliHome(c.Context); err != nil {
+			log.FromContext(c.Context).Warn(fmt.Sprintf("Unable to clean up after an interrupted operation: %v", err))
+		}
+
+		return action(c)
+	}
+}
+
+// LockCliHome acquires the lock on the CLI home, waiting for as long as the cli.lock-timeout config value allows.
+// It is also used outside of commands, such as to update the config when the CLI starts.
+func LockCliHome(ctx context.Context) (*tools.FileLock, error) {
+	logger := log.FromContext(ctx)
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := config.Get(ctx).GetValue("cli", "lock-timeout")
+	if !ok || value == "" {
+		value = config.DefaultValue("cli", "lock-timeout")
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cli.lock-timeout value %q: %w", value, err)
+	}
+
+	path := filepath.Join(cliPath, homeLockFileName)
+	logger.Debug(fmt.Sprintf("Acquiring lock %s", path))
+	lock, err := tools.LockFile(ctx, path, timeout, func() {
+		logger.Debug(fmt.Sprintf("Lock %s is held by another process, waiting up to %v", path, timeout))
+		terminal.Get(ctx).WriteError(color.YellowString("Waiting for another akamai process to finish..."))
+	})
+	if errors.Is(err, tools.ErrLockTimeout) {
+		return nil, fmt.Errorf("%w after %v, you can change how long to wait with the cli.lock-timeout config value", err, timeout)
+	}
+	return lock, err
+}
+
+// SaveConfigValue sets a value of the user config file while holding the lock on the CLI home.
+// The config is reloaded first, so that values other processes wrote since it was loaded are kept.
+func SaveConfigValue(ctx context.Context, section, key, value string) error {
+	lock, err := LockCliHome(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := lock.Unlock(); err != nil {
+			log.FromContext(ctx).Warn(fmt.Sprintf("Unable to release lock: %v", err))
+		}
+	}()
+
+	cfg := config.Get(ctx)
+	if err := cfg.Reload(); err != nil {
+		return err
+	}
+	cfg.SetValue(section, key, value)
+	return cfg.Save(ctx)
+}
+
+// markInstalling records that a package is being installed into packageDir. The returned function
+// removes the mark once the install completed or its directory was cleaned up.
+func markInstalling(packageDir string) (func(), error) {
+	marker := installMarkerPath(packageDir)
+	if err := os.WriteFile(marker, nil, 0600); err != nil {
+		return nil, err
+	}
+	return func() {
+		_ = os.Remove(marker)
+	}, nil
+}
+
+func installMarkerPath(packageDir string) string {
+	return filepath.Join(filepath.Dir(packageDir), "."+filepath.Base(packageDir)+installMarkerSuffix)
+}
+
+// recoverCliHome undoes what an interrupted install, update or rollback left in the src and virtualenv directories:
+// partially installed packages are removed and packages or virtualenvs moved aside are put back in place
+func recoverCliHome(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+	srcPath, err := tools.GetAkamaiCliSrcPath()
+	if err != nil {
+		return err
+	}
+	venvPath, err := tools.GetAkamaiCliVenvPath()
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(srcPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, ".") || !strings.HasSuffix(name, installMarkerSuffix) {
+			continue
+		}
+		pkgName := strings.TrimSuffix(strings.TrimPrefix(name, "."), installMarkerSuffix)
+		packageDir := filepath.Join(srcPath, pkgName)
+		logger.Warn(fmt.Sprintf("Removing partially installed package %s", packageDir))
+		if err := os.RemoveAll(packageDir); err != nil {
+			return err
+		}
+		if err := os.RemoveAll(filepath.Join(venvPath, pkgName)); err != nil {
+			return err
+		}
+		if err := os.Remove(filepath.Join(srcPath, name)); err != nil {
+			return err
+		}
+	}
+
+	if err := restoreAsideDirs(ctx, srcPath); err != nil {
+		return err
+	}
+	return restoreAsideDirs(ctx, venvPath)
+}
+
+// restoreAsideDirs puts directories moved aside by an update or rollback back in place, unless they were replaced already
+func restoreAsideDirs(ctx context.Context, dir string) error {
+	logger := log.FromContext(ctx)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		var target string
+		switch {
+		case strings.HasPrefix(name, updateAsidePrefix):
+			target = strings.TrimPrefix(name, updateAsidePrefix)
+		case strings.HasPrefix(name, rollbackAsidePrefix):
+			target = strings.TrimPrefix(name, rollbackAsidePrefix)
+		default:
+			continue
+		}
+
+		aside := filepath.Join(dir, name)
+		targetDir := filepath.Join(dir, target)
+		if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+			logger.Warn(fmt.Sprintf("Restoring %s", targetDir))
+			if err := os.Rename(aside, targetDir); err != nil {
+				return err
+			}
+			continue
+		}
+		logger.Debug(fmt.Sprintf("Removing leftover directory %s", aside))
+		if err := os.RemoveAll(aside); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
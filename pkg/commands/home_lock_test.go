@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/akamai/cli/v2/pkg/tools"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestWithHomeLock(t *testing.T) {
+	tests := map[string]struct {
+		args        []string
+		lockTimeout string
+		locked      bool
+		called      bool
+		withError   string
+	}{
+		"lock acquired": {
+			lockTimeout: "1s",
+			called:      true,
+		},
+		"lock held by another process": {
+			lockTimeout: "0s",
+			locked:      true,
+			withError:   "Unable to lock akamai cli home: timed out waiting for another akamai process to finish after 0s",
+		},
+		"invalid timeout": {
+			lockTimeout: "soon",
+			withError:   `Unable to lock akamai cli home: invalid cli.lock-timeout value "soon"`,
+		},
+		"dry run does not lock": {
+			args:   []string{"--dry-run"},
+			locked: true,
+			called: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := t.TempDir()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+			defer func() {
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", filepath.Join(".", "testdata")))
+			}()
+			if test.locked {
+				require.NoError(t, os.MkdirAll(filepath.Join(cliHome, ".akamai-cli"), 0755))
+				lock, err := tools.LockFile(context.Background(), filepath.Join(cliHome, ".akamai-cli", homeLockFileName), 0, nil)
+				require.NoError(t, err)
+				defer func() {
+					require.NoError(t, lock.Unlock())
+				}()
+			}
+
+			m := &mocked{&terminal.Mock{}, &config.Mock{}, nil, nil, nil}
+			if test.lockTimeout != "" {
+				m.cfg.On("GetValue", "cli", "lock-timeout").Return(test.lockTimeout, true).Once()
+			}
+			var called bool
+			command := &cli.Command{
+				Name:  "install",
+				Flags: []cli.Flag{&cli.BoolFlag{Name: "dry-run"}},
+				Action: withHomeLock(func(_ *cli.Context) error {
+					called = true
+					return nil
+				}),
+			}
+			app, ctx := setupTestApp(command, m)
+			args := append([]string{os.Args[0], "install"}, test.args...)
+			err := app.RunContext(ctx, args)
+
+			m.cfg.AssertExpectations(t)
+			m.term.AssertExpectations(t)
+			assert.Equal(t, test.called, called)
+			if test.withError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.withError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRecoverCliHome(t *testing.T) {
+	tests := map[string]struct {
+		dirs          []string
+		files         []string
+		venvDirs      []string
+		expected      []string
+		expectedVenvs []string
+	}{
+		"remove partially installed package": {
+			dirs:     []string{"cli-echo", "cli-other"},
+			files:    []string{".cli-echo.installing"},
+			expected: []string{"cli-other"},
+		},
+		"restore package moved aside by interrupted update": {
+			dirs:     []string{".tmp_cli-echo", "cli-other"},
+			expected: []string{"cli-echo", "cli-other"},
+		},
+		"remove partial update and restore previous version": {
+			dirs:     []string{".tmp_cli-echo", "cli-echo"},
+			files:    []string{".cli-echo.installing"},
+			expected: []string{"cli-echo"},
+		},
+		"remove leftover of finished rollback": {
+			dirs:     []string{".rollback_cli-echo", "cli-echo"},
+			expected: []string{"cli-echo"},
+		},
+		"restore virtualenv moved aside by interrupted rollback": {
+			dirs:          []string{"cli-echo"},
+			venvDirs:      []string{".rollback_cli-echo", "cli-other"},
+			expected:      []string{"cli-echo"},
+			expectedVenvs: []string{"cli-echo", "cli-other"},
+		},
+		"remove partial update and restore previous virtualenv": {
+			dirs:          []string{".tmp_cli-echo", "cli-echo"},
+			files:         []string{".cli-echo.installing"},
+			venvDirs:      []string{".tmp_cli-echo", "cli-echo"},
+			expected:      []string{"cli-echo"},
+			expectedVenvs: []string{"cli-echo"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cliHome := t.TempDir()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", cliHome))
+			defer func() {
+				require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", filepath.Join(".", "testdata")))
+			}()
+			srcPath := filepath.Join(cliHome, ".akamai-cli", "src")
+			for _, dir := range test.dirs {
+				require.NoError(t, os.MkdirAll(filepath.Join(srcPath, dir), 0755))
+			}
+			for _, file := range test.files {
+				require.NoError(t, os.WriteFile(filepath.Join(srcPath, file), nil, 0600))
+			}
+			venvPath := filepath.Join(cliHome, ".akamai-cli", "venv")
+			for _, dir := range test.venvDirs {
+				require.NoError(t, os.MkdirAll(filepath.Join(venvPath, dir), 0755))
+			}
+
+			ctx := terminal.Context(context.Background(), &terminal.Mock{})
+			require.NoError(t, recoverCliHome(ctx))
+
+			entries, err := os.ReadDir(srcPath)
+			require.NoError(t, err)
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			assert.Equal(t, test.expected, names)
+
+			entries, err = os.ReadDir(venvPath)
+			if len(test.venvDirs) == 0 {
+				assert.True(t, os.IsNotExist(err))
+				return
+			}
+			require.NoError(t, err)
+			names = nil
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			assert.Equal(t, test.expectedVenvs, names)
+		})
+	}
+}
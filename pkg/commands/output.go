@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// output formats supported by the global --output flag
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// outputFormat returns the output format requested with the global --output flag
+func outputFormat(c *cli.Context) string {
+	if format := c.String("output"); format != "" {
+		return format
+	}
+	return outputText
+}
+
+// isStructuredOutput reports whether a built-in command should write a JSON or YAML document instead of text
+func isStructuredOutput(c *cli.Context) bool {
+	format := outputFormat(c)
+	return format == outputJSON || format == outputYAML
+}
+
+// writeOutput writes v to the terminal as a JSON or YAML document
+func writeOutput(term terminal.Terminal, format string, v interface{}) error {
+	var data []byte
+	var err error
+	switch format {
+	case outputJSON:
+		data, err = json.MarshalIndent(v, "", "  ")
+	case outputYAML:
+		buf := &bytes.Buffer{}
+		enc := yaml.NewEncoder(buf)
+		enc.SetIndent(2)
+		if err = enc.Encode(v); err == nil {
+			err = enc.Close()
+		}
+		data = buf.Bytes()
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to encode %s output: %w", format, err)
+	}
+
+	term.Printf("%s\n", string(bytes.TrimSpace(data)))
+	return nil
+}
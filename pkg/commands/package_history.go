@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/tools"
+)
+
+const (
+	// packageHistoryLimit is the number of previous installs kept for each package
+	packageHistoryLimit = 3
+	historyFileName     = "history.json"
+	historyTreeDir      = "tree"
+	historyVenvDir      = "venv"
+)
+
+type (
+	// packageHistory lists previous installs of a package, oldest first
+	packageHistory struct {
+		Entries []historyEntry `json:"entries"`
+	}
+
+	// historyEntry represents a single previous install of a package.
+	// Source installs are recorded by commit, binary installs by a copy of the package tree.
+	historyEntry struct {
+		ID       string          `json:"id"`
+		Time     time.Time       `json:"time"`
+		Commit   string          `json:"commit,omitempty"`
+		Commands []lockedCommand `json:"commands"`
+		Tree     bool            `json:"tree"`
+		Venv     bool            `json:"venv"`
+	}
+)
+
+func getPackageHistoryPath(pkgName string) (string, error) {
+	historyPath, err := tools.GetAkamaiCliHistoryPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(historyPath, pkgName), nil
+}
+
+func readPackageHistory(pkgName string) (*packageHistory, error) {
+	dir, err := getPackageHistoryPath(pkgName)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, historyFileName))
+	if os.IsNotExist(err) {
+		return &packageHistory{Entries: make([]historyEntry, 0)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var history packageHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal package history: %v", err)
+	}
+
+	return &history, nil
+}
+
+func writePackageHistory(pkgName string, history *packageHistory) error {
+	dir, err := getPackageHistoryPath(pkgName)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return tools.WriteFileAtomic(filepath.Join(dir, historyFileName), data, 0600)
+}
+
+// recordPackageHistory stores the install being replaced by an update.
+// If treeDir is not empty, it is moved into the history. If venvDir is not empty, it is moved into the history
+// as the package virtualenv; otherwise the current package virtualenv, if present, is copied.
+// Only the latest packageHistoryLimit entries are kept.
+func recordPackageHistory(ctx context.Context, pkgName string, cmdPackage subcommands, commit, treeDir, venvDir string) error {
+	logger := log.FromContext(ctx)
+
+	dir, err := getPackageHistoryPath(pkgName)
+	if err != nil {
+		return err
+	}
+
+	history, err := readPackageHistory(pkgName)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	entry := historyEntry{
+		ID:       strconv.FormatInt(now.UnixNano(), 10),
+		Time:     now.UTC(),
+		Commit:   commit,
+		Commands: make([]lockedCommand, 0, len(cmdPackage.Commands)),
+	}
+	for _, cmd := range cmdPackage.Commands {
+		entry.Commands = append(entry.Commands, lockedCommand{Name: cmd.Name, Version: cmd.Version})
+	}
+
+	entryDir := filepath.Join(dir, entry.ID)
+	if err := os.MkdirAll(entryDir, 0700); err != nil {
+		return err
+	}
+
+	if treeDir != "" {
+		logger.Debug(fmt.Sprintf("Moving package tree %s to history", treeDir))
+		if err := os.Rename(treeDir, filepath.Join(entryDir, historyTreeDir)); err != nil {
+			return err
+		}
+		entry.Tree = true
+	}
+
+	if venvDir != "" {
+		logger.Debug(fmt.Sprintf("Moving package virtualenv %s to history", venvDir))
+		if err := os.Rename(venvDir, filepath.Join(entryDir, historyVenvDir)); err != nil {
+			return err
+		}
+		entry.Venv = true
+	} else {
+		venvPath, err := tools.GetPkgVenvPath(pkgName)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(venvPath); err == nil {
+			logger.Debug(fmt.Sprintf("Copying package virtualenv %s to history", venvPath))
+			if err := tools.CopyDir(venvPath, filepath.Join(entryDir, historyVenvDir)); err != nil {
+				return err
+			}
+			entry.Venv = true
+		}
+	}
+
+	history.Entries = append(history.Entries, entry)
+	for len(history.Entries) > packageHistoryLimit {
+		logger.Debug(fmt.Sprintf("Pruning package history entry %s", history.Entries[0].ID))
+		if err := os.RemoveAll(filepath.Join(dir, history.Entries[0].ID)); err != nil {
+			return err
+		}
+		history.Entries = history.Entries[1:]
+	}
+
+	return writePackageHistory(pkgName, history)
+}
+
+// selectHistoryEntry returns the index of the latest entry, or of the latest entry with the given version, or -1
+func selectHistoryEntry(history *packageHistory, version string) int {
+	for i := len(history.Entries) - 1; i >= 0; i-- {
+		if version == "" {
+			return i
+		}
+		for _, cmd := range history.Entries[i].Commands {
+			if cmd.Version == version || "v"+cmd.Version == version {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func removePackageHistory(pkgName string) error {
+	dir, err := getPackageHistoryPath(pkgName)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
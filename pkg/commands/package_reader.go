@@ -35,6 +35,8 @@ type (
 		Issues       string       `json:"issues"`
 		Commands     []command    `json:"commands"`
 		Requirements requirements `json:"requirements"`
+		// Source is the registry the package was read from, empty for the embedded package list
+		Source string `json:"-"`
 	}
 
 	// requirements represents package requirements
@@ -0,0 +1,230 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/tools"
+)
+
+const (
+	registryCacheDir = "registries"
+	// registryTimeout limits how long a registry is waited for, the cached copy is used after that
+	registryTimeout = 30 * time.Second
+)
+
+// registryReader reads the package lists of the configured registries and merges them with the embedded package list.
+// A package found in several registries is taken from the first registry listed; the embedded list has the lowest precedence.
+type registryReader struct {
+	registries []string
+	cachePath  string
+	embedded   string
+	client     *http.Client
+	logger     *slog.Logger
+}
+
+// newRegistryReader returns a package reader for the registries set in the cli.registries config value
+func newRegistryReader(ctx context.Context) *registryReader {
+	cfg := config.Get(ctx)
+	reader := &registryReader{
+		embedded: embeddedPackages,
+		client:   &http.Client{Timeout: registryTimeout},
+		logger:   log.FromContext(ctx),
+	}
+
+	if value, ok := cfg.GetValue("cli", "registries"); ok {
+		reader.registries = parseRegistries(value)
+	}
+
+	reader.cachePath = getCachePath(cfg)
+
+	return reader
+}
+
+// getCachePath returns the cli.cache-path directory, or the cache directory of the CLI home when it is not set
+func getCachePath(cfg config.Config) string {
+	if cachePath, ok := cfg.GetValue("cli", "cache-path"); ok && cachePath != "" {
+		return cachePath
+	}
+	if cliPath, err := tools.GetAkamaiCliPath(); err == nil {
+		return filepath.Join(cliPath, "cache")
+	}
+	return ""
+}
+
+// parseRegistries splits a comma separated list of registry URLs and paths
+func parseRegistries(value string) []string {
+	registries := make([]string, 0)
+	for _, registry := range strings.Split(value, ",") {
+		if registry = strings.TrimSpace(registry); registry != "" {
+			registries = append(registries, registry)
+		}
+	}
+	return registries
+}
+
+// readPackage reads and merges the package lists. Registries that cannot be read are skipped with a warning
+func (rr *registryReader) readPackage() (*packageList, error) {
+	merged := &packageList{Packages: make([]packageListItem, 0)}
+	seen := make(map[string]bool)
+	add := func(list *packageList, source string) {
+		if list.Version > merged.Version {
+			merged.Version = list.Version
+		}
+		for _, pkg := range list.Packages {
+			if seen[pkg.Name] {
+				rr.logger.Debug(fmt.Sprintf("Package %s from %s is overridden by a registry with higher precedence", pkg.Name, sourceName(source)))
+				continue
+			}
+			seen[pkg.Name] = true
+			pkg.Source = source
+			merged.Packages = append(merged.Packages, pkg)
+		}
+	}
+
+	for _, registry := range rr.registries {
+		list, err := rr.readRegistry(registry)
+		if err != nil {
+			rr.logger.Warn(fmt.Sprintf("Unable to read package registry %s: %v", registry, err))
+			continue
+		}
+		add(list, registry)
+	}
+
+	embedded, err := newPackageReader(rr.embedded).readPackage()
+	if err != nil {
+		return nil, err
+	}
+	add(embedded, "")
+
+	return merged, nil
+}
+
+func (rr *registryReader) readRegistry(registry string) (*packageList, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(registry, "http://") || strings.HasPrefix(registry, "https://") {
+		data, err = rr.fetchRegistry(registry)
+	} else {
+		data, err = os.ReadFile(strings.TrimPrefix(registry, "file://"))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return newPackageReader(string(data)).readPackage()
+}
+
+// fetchRegistry downloads a registry, reusing the cached copy if the server reports it has not changed.
+// If the registry cannot be reached, the cached copy is used.
+func (rr *registryReader) fetchRegistry(url string) ([]byte, error) {
+	cacheFile := rr.cacheFile(url)
+	cached, etag := readRegistryCache(cacheFile)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil && etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	res, err := rr.client.Do(req)
+	if err != nil {
+		if cached != nil {
+			rr.logger.Warn(fmt.Sprintf("Unable to fetch package registry %s, using cached copy: %v", url, err))
+			return cached, nil
+		}
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			rr.logger.Error(fmt.Sprintf("Error closing request body: %v", err))
+		}
+	}()
+
+	switch {
+	case res.StatusCode == http.StatusNotModified && cached != nil:
+		rr.logger.Debug(fmt.Sprintf("Package registry %s not modified, using cached copy", url))
+		return cached, nil
+	case res.StatusCode != http.StatusOK:
+		if cached != nil {
+			rr.logger.Warn(fmt.Sprintf("Unable to fetch package registry %s, using cached copy: %s", url, res.Status))
+			return cached, nil
+		}
+		return nil, fmt.Errorf("invalid response status while fetching package registry: %d", res.StatusCode)
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("invalid package registry content")
+	}
+
+	if cacheFile != "" {
+		if err := writeRegistryCache(cacheFile, data, res.Header.Get("ETag")); err != nil {
+			rr.logger.Warn(fmt.Sprintf("Unable to cache package registry %s: %v", url, err))
+		}
+	}
+
+	return data, nil
+}
+
+// cacheFile returns the path of the cached copy of a registry, or an empty string if there is no cache directory
+func (rr *registryReader) cacheFile(url string) string {
+	if rr.cachePath == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(rr.cachePath, registryCacheDir, hex.EncodeToString(sum[:8])+".json")
+}
+
+func readRegistryCache(cacheFile string) ([]byte, string) {
+	if cacheFile == "" {
+		return nil, ""
+	}
+	data, err := os.ReadFile(cacheFile)
+	if err != nil {
+		return nil, ""
+	}
+	etag, _ := os.ReadFile(cacheFile + ".etag")
+	return data, strings.TrimSpace(string(etag))
+}
+
+func writeRegistryCache(cacheFile string, data []byte, etag string) error {
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0700); err != nil {
+		return err
+	}
+	if err := tools.WriteFileAtomic(cacheFile, data, 0600); err != nil {
+		return err
+	}
+	if etag == "" {
+		if err := os.Remove(cacheFile + ".etag"); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return tools.WriteFileAtomic(cacheFile+".etag", []byte(etag), 0600)
+}
+
+// sourceName returns a display name for a package source, where an empty source is the embedded package list
+func sourceName(source string) string {
+	if source == "" {
+		return "built-in package list"
+	}
+	return source
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/config"
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryReader(t *testing.T) {
+	remoteList := `{"version": 1.0, "packages": [
+		{"title": "Internal Tool", "name": "internal", "url": "https://git.example.com/tools/cli-internal", "commands": [{"name": "internal", "version": "1.2.0"}]},
+		{"title": "Sample From Remote", "name": "sample", "url": "https://git.example.com/tools/cli-sample", "commands": [{"name": "sample"}]}
+	]}`
+	localList := `{"version": 1.0, "packages": [
+		{"title": "Sample From File", "name": "sample", "url": "https://git.example.com/other/cli-sample", "commands": [{"name": "sample"}]},
+		{"title": "Local Tool", "name": "local", "url": "https://git.example.com/other/cli-local", "commands": [{"name": "local"}]}
+	]}`
+	embedded := `{"version": 1.0, "packages": [
+		{"title": "Echo", "name": "echo", "url": "https://github.com/akamai/cli-echo", "commands": [{"name": "echo"}]},
+		{"title": "Built-in Local", "name": "local", "url": "https://github.com/akamai/cli-local", "commands": [{"name": "local"}]}
+	]}`
+
+	var requests, notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/packages.json":
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				notModified++
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			_, err := w.Write([]byte(remoteList))
+			assert.NoError(t, err)
+		case "/invalid.json":
+			_, err := w.Write([]byte("invalid"))
+			assert.NoError(t, err)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	localPath := filepath.Join(t.TempDir(), "packages.json")
+	require.NoError(t, os.WriteFile(localPath, []byte(localList), 0644))
+
+	newReader := func(registries string, cachePath string) *registryReader {
+		m := &config.Mock{}
+		m.On("GetValue", "cli", "registries").Return(registries, true).Once()
+		m.On("GetValue", "cli", "cache-path").Return(cachePath, true).Once()
+		ctx := config.Context(context.Background(), m)
+		ctx = log.SetupContext(ctx, os.Stderr)
+		reader := newRegistryReader(ctx)
+		reader.embedded = embedded
+		m.AssertExpectations(t)
+		return reader
+	}
+
+	t.Run("merge registries by precedence", func(t *testing.T) {
+		reader := newReader(srv.URL+"/packages.json, "+localPath+", "+srv.URL+"/missing.json,"+srv.URL+"/invalid.json", t.TempDir())
+
+		list, err := reader.readPackage()
+		require.NoError(t, err)
+
+		sources := make(map[string]string)
+		titles := make(map[string]string)
+		for _, pkg := range list.Packages {
+			sources[pkg.Name] = pkg.Source
+			titles[pkg.Name] = pkg.Title
+		}
+		assert.Equal(t, map[string]string{
+			"internal": srv.URL + "/packages.json",
+			"sample":   srv.URL + "/packages.json",
+			"local":    localPath,
+			"echo":     "",
+		}, sources)
+		assert.Equal(t, "Sample From Remote", titles["sample"])
+		assert.Equal(t, "Local Tool", titles["local"])
+	})
+
+	t.Run("revalidate cached registry", func(t *testing.T) {
+		cachePath := t.TempDir()
+		requests, notModified = 0, 0
+
+		for i := 0; i < 2; i++ {
+			reader := newReader(srv.URL+"/packages.json", cachePath)
+			list, err := reader.readPackage()
+			require.NoError(t, err)
+			require.Len(t, list.Packages, 4)
+			assert.Equal(t, "internal", list.Packages[0].Name)
+		}
+		assert.Equal(t, 2, requests)
+		assert.Equal(t, 1, notModified)
+
+		// the cached copy is used when the registry does not answer in time
+		slow := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer slow.Close()
+		reader := newReader(slow.URL+"/packages.json", cachePath)
+		reader.client.Timeout = 100 * time.Millisecond
+		data, err := os.ReadFile(reader.cacheFile(srv.URL + "/packages.json"))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(reader.cacheFile(slow.URL+"/packages.json"), data, 0600))
+		list, err := reader.readPackage()
+		require.NoError(t, err)
+		assert.Equal(t, "internal", list.Packages[0].Name)
+
+		// the cached copy is used when the registry is not reachable
+		srv.Close()
+		reader = newReader(srv.URL+"/packages.json", cachePath)
+		list, err = reader.readPackage()
+		require.NoError(t, err)
+		assert.Equal(t, "internal", list.Packages[0].Name)
+	})
+}
+
+func TestParseRegistries(t *testing.T) {
+	assert.Equal(t, []string{"https://example.com/packages.json", "/etc/akamai/packages.json"},
+		parseRegistries(" https://example.com/packages.json,, /etc/akamai/packages.json "))
+	assert.Empty(t, parseRegistries(""))
+}
@@ -3,13 +3,17 @@ package commands
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 	"text/template"
 
@@ -19,6 +23,29 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
+const (
+	// pinFileName is the file inside a package directory that records the ref the package was installed from
+	pinFileName = ".akamai-cli-pin"
+	// originFileName is the file inside a binary package directory that records the repository and commit it was installed from
+	originFileName = ".akamai-cli-origin"
+)
+
+// defaultBranches are tried in order when no ref is requested for a package
+var defaultBranches = []string{"main", "master"}
+
+// buildGitHubCommitURL returns the GitHub API URL resolving a ref to its commit
+var buildGitHubCommitURL = func(owner, repo, ref string) string {
+	return fmt.Sprintf("https://api.github.com/repos/%s/%s/commits/%s", owner, repo, ref)
+}
+
+var commitHashRegexp = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// packageOrigin records where a binary package was installed from, as, unlike source packages, it has no git repository to tell
+type packageOrigin struct {
+	Repository string `json:"repository"`
+	Commit     string `json:"commit,omitempty"`
+}
+
 type subcommands struct {
 	Commands     []command                     `json:"commands"`
 	Requirements packages.LanguageRequirements `json:"requirements"`
@@ -88,13 +115,114 @@ func readPackageFromGithub(url, dir string) (subcommands, error) {
 	return subcommands{}, fmt.Errorf("invalid response status while fetching cli.json: %d", response.StatusCode)
 }
 
+// fetchPackageConfig reads cli.json from GitHub, trying each ref in order until one succeeds
+func fetchPackageConfig(owner, repoName, dir string, refs []string) (subcommands, error) {
+	var cmdPackage subcommands
+	var err error
+	for _, ref := range refs {
+		cmdPackage, err = readPackageFromGithub(buildRawGitHubURL(owner, repoName, ref), dir)
+		if err == nil {
+			return cmdPackage, nil
+		}
+	}
+	return subcommands{}, err
+}
+
+// refCandidates returns the refs to try for a requested ref. Version-like refs are also tried with a "v" prefix
+func refCandidates(ref string) []string {
+	if ref == "" {
+		return defaultBranches
+	}
+	if ref[0] >= '0' && ref[0] <= '9' {
+		return []string{ref, "v" + ref}
+	}
+	return []string{ref}
+}
+
+// readPackagePin returns the ref the package in dir is pinned to, or an empty string
+func readPackagePin(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, pinFileName))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func writePackagePin(dir, ref string) error {
+	return os.WriteFile(filepath.Join(dir, pinFileName), []byte(ref+"\n"), 0644)
+}
+
+// readPackageOrigin returns the origin recorded for the binary package in dir
+func readPackageOrigin(dir string) (packageOrigin, error) {
+	var origin packageOrigin
+	data, err := os.ReadFile(filepath.Join(dir, originFileName))
+	if err != nil {
+		return origin, err
+	}
+	if err := json.Unmarshal(data, &origin); err != nil {
+		return origin, fmt.Errorf("invalid package origin: %w", err)
+	}
+	if origin.Repository == "" {
+		return origin, errors.New("invalid package origin: no repository")
+	}
+	return origin, nil
+}
+
+func writePackageOrigin(dir string, origin packageOrigin) error {
+	data, err := json.MarshalIndent(origin, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, originFileName), append(data, '\n'), 0644)
+}
+
+// resolveGitHubCommit returns the commit the first resolvable of refs points to in a GitHub repository
+func resolveGitHubCommit(owner, repoName string, refs []string) (string, error) {
+	var err error
+	for _, ref := range refs {
+		var commit string
+		if commit, err = fetchGitHubCommit(buildGitHubCommitURL(owner, repoName, ref)); err == nil {
+			return commit, nil
+		}
+	}
+	return "", err
+}
+
+func fetchGitHubCommit(url string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github.sha")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve commit: %w", err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("invalid response status while resolving commit: %d", res.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, 128))
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve commit: %w", err)
+	}
+	commit := strings.TrimSpace(string(data))
+	if !commitHashRegexp.MatchString(commit) {
+		return "", fmt.Errorf("invalid commit: %q", commit)
+	}
+	return commit, nil
+}
+
 func getPackagePaths() []string {
 	akamaiCliPath, err := tools.GetAkamaiCliSrcPath()
 	if err == nil && akamaiCliPath != "" {
 		paths, _ := filepath.Glob(filepath.Join(akamaiCliPath, "*"))
-		if len(paths) > 0 {
-			return paths
-		}
+		// hidden entries are temporary dirs and markers of installs in progress
+		return slices.DeleteFunc(paths, func(path string) bool {
+			return strings.HasPrefix(filepath.Base(path), ".")
+		})
 	}
 
 	return []string{}
@@ -130,10 +258,15 @@ func findPackageDir(dir string) string {
 }
 
 func downloadBin(ctx context.Context, dir string, cmd command) error {
+	return downloadPlatformBin(ctx, dir, cmd, runtime.GOOS, runtime.GOARCH)
+}
+
+// downloadPlatformBin downloads the binary of a command built for the given operating system and architecture
+func downloadPlatformBin(ctx context.Context, dir string, cmd command, goos, goarch string) error {
 	logger := log.FromContext(ctx)
-	cmd.Arch = runtime.GOARCH
+	cmd.Arch = goarch
 
-	cmd.OS = runtime.GOOS
+	cmd.OS = goos
 	if cmd.OS == "darwin" {
 		cmd.OS = "mac"
 	}
@@ -142,33 +275,30 @@ func downloadBin(ctx context.Context, dir string, cmd command) error {
 		cmd.BinSuffix = ".exe"
 	}
 
-	t := template.Must(template.New("url").Parse(cmd.Bin))
-	buf := &bytes.Buffer{}
-	if err := t.Execute(buf, cmd); err != nil {
+	url, err := executeURLTemplate(cmd.Bin, cmd)
+	if err != nil {
 		logger.Error(fmt.Sprintf("Unable to create URL. Template: %s; Error: %v", cmd.Bin, err))
 		return err
 	}
-
-	url := buf.String()
 	logger.Debug(fmt.Sprintf("Fetching binary from %s", url))
 
 	binName := filepath.Join(dir, "akamai-"+strings.ToLower(cmd.Name)+cmd.BinSuffix)
-	bin, err := os.Create(binName)
+	// the binary is downloaded next to its final location and moved into place only once verified
+	tmpName := binName + ".download"
+	bin, err := os.Create(tmpName)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Unable to create %s file: %v", binName, err))
+		logger.Error(fmt.Sprintf("Unable to create %s file: %v", tmpName, err))
 		return err
 	}
 	defer func() {
-		if err := bin.Close(); err != nil {
+		if err := bin.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
 			logger.Error(fmt.Sprintf("Error closing file: %v", err))
 		}
+		if err := os.Remove(tmpName); err != nil && !os.IsNotExist(err) {
+			logger.Error(fmt.Sprintf("Unable to remove %s file: %v", tmpName, err))
+		}
 	}()
 
-	if err := os.Chmod(binName, 0775); err != nil {
-		logger.Error(fmt.Sprintf("Unable to change the %s file mode: %v", binName, err))
-		return err
-	}
-
 	res, err := http.Get(url)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Unable to get command binary: %v", err))
@@ -184,11 +314,44 @@ func downloadBin(ctx context.Context, dir string, cmd command) error {
 		return fmt.Errorf("invalid response status while fetching command binary: %d", res.StatusCode)
 	}
 
-	n, err := io.Copy(bin, res.Body)
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(bin, hash), res.Body)
 	if err != nil || n == 0 {
-		logger.Error(fmt.Sprintf("Unable to copy from %s to %s: %v", res.Body, binName, err))
+		logger.Error(fmt.Sprintf("Unable to copy from %s to %s: %v", res.Body, tmpName, err))
+		return err
+	}
+	if err := bin.Close(); err != nil {
+		logger.Error(fmt.Sprintf("Error closing file: %v", err))
+		return err
+	}
+
+	if err := verifyBin(ctx, cmd, url, tmpName, hash.Sum(nil)); err != nil {
+		logger.Error(fmt.Sprintf("Verification of binary %s failed: %v", url, err))
+		return err
+	}
+
+	if err := os.Chmod(tmpName, 0775); err != nil {
+		logger.Error(fmt.Sprintf("Unable to change the %s file mode: %v", tmpName, err))
+		return err
+	}
+
+	if err := os.Rename(tmpName, binName); err != nil {
+		logger.Error(fmt.Sprintf("Unable to move %s to %s: %v", tmpName, binName, err))
 		return err
 	}
 
 	return nil
 }
+
+// executeURLTemplate fills a cli.json URL template with the command data, such as {{.Version}}, {{.OS}} and {{.Arch}}
+func executeURLTemplate(tpl string, cmd command) (string, error) {
+	t, err := template.New("url").Parse(tpl)
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, cmd); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
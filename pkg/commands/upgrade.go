@@ -66,8 +66,7 @@ func checkUpgradeVersion(ctx context.Context, force bool, provider versionProvid
 	}
 
 	if checkForUpgrade {
-		cfg.SetValue("cli", "last-upgrade-check", time.Now().Format(time.RFC3339))
-		err := cfg.Save(ctx)
+		err := SaveConfigValue(ctx, "cli", "last-upgrade-check", time.Now().Format(time.RFC3339))
 		if err != nil {
 			logger.Error(fmt.Sprintf("Error saving config: %v", err))
 			return ""
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AccountSectionPrefix starts the name of sections naming an account switch key, such as [account acme].
+// The alias can be given to --accountkey instead of the key.
+const AccountSectionPrefix = "account "
+
+// Account names an account switch key
+type Account struct {
+	Alias       string `json:"alias" yaml:"alias"`
+	Key         string `json:"key" yaml:"key"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+var (
+	// ErrUnknownAccount is returned when an account alias is not defined in any of the config files
+	ErrUnknownAccount = errors.New("unknown account")
+	// ErrAccountExists is returned when adding an account alias which is already defined
+	ErrAccountExists = errors.New("account already exists")
+)
+
+// AccountSection returns the name of the section defining given account alias
+func AccountSection(alias string) string {
+	return AccountSectionPrefix + alias
+}
+
+// IsAccountSection checks whether the section defines an account alias
+func IsAccountSection(section string) bool {
+	return strings.HasPrefix(section, AccountSectionPrefix)
+}
+
+// Accounts returns the accounts defined in the config, sorted by alias
+func Accounts(cfg Config) []Account {
+	accounts := make([]Account, 0)
+	for section, values := range cfg.Values() {
+		if !IsAccountSection(section) || values["key"] == "" {
+			continue
+		}
+		accounts = append(accounts, Account{
+			Alias:       strings.TrimPrefix(section, AccountSectionPrefix),
+			Key:         values["key"],
+			Description: values["description"],
+		})
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Alias < accounts[j].Alias
+	})
+	return accounts
+}
+
+// GetAccount returns the account of given alias
+func GetAccount(cfg Config, alias string) (Account, error) {
+	for _, a := range Accounts(cfg) {
+		if a.Alias == alias {
+			return a, nil
+		}
+	}
+	return Account{}, fmt.Errorf("%w: %s", ErrUnknownAccount, alias)
+}
+
+// ExpandAccountKey returns the key of the account whose alias is given. False is returned for values which are not an alias,
+// such as account switch keys.
+func ExpandAccountKey(cfg Config, value string) (string, bool) {
+	if value == "" {
+		return "", false
+	}
+	a, err := GetAccount(cfg, value)
+	if err != nil {
+		return "", false
+	}
+	return a.Key, true
+}
+
+// AddAccount adds an account alias to the user config file
+func AddAccount(cfg Config, a Account) error {
+	if !profileNameRegexp.MatchString(a.Alias) {
+		return fmt.Errorf("invalid account alias %q, only letters, digits, '.', '-' and '_' are allowed", a.Alias)
+	}
+	if a.Key == "" || strings.ContainsAny(a.Key, " \t\r\n") {
+		return fmt.Errorf("invalid account switch key %q", a.Key)
+	}
+	if _, err := GetAccount(cfg, a.Alias); err == nil {
+		return fmt.Errorf("%w: %s", ErrAccountExists, a.Alias)
+	}
+	section := AccountSection(a.Alias)
+	cfg.SetValue(section, "key", a.Key)
+	if a.Description != "" {
+		cfg.SetValue(section, "description", a.Description)
+	}
+	return nil
+}
+
+// RemoveAccount removes an account alias from the user config file
+func RemoveAccount(cfg Config, alias string) error {
+	if _, err := GetAccount(cfg, alias); err != nil {
+		return err
+	}
+	section := AccountSection(alias)
+	for _, key := range []string{"key", "description"} {
+		if origin, ok := cfg.Origin(section, key); ok && origin.Scope != ScopeUser {
+			return fmt.Errorf("account %s is defined in %s, remove it from that file instead", alias, origin.Path)
+		}
+	}
+	for _, key := range []string{"key", "description"} {
+		cfg.UnsetValue(section, key)
+	}
+	return nil
+}
@@ -1,12 +1,17 @@
 package config
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
-	"time"
 
 	"github.com/akamai/cli/v2/pkg/log"
 	"github.com/akamai/cli/v2/pkg/terminal"
@@ -15,24 +20,71 @@ import (
 )
 
 const (
-	configVersion string = "1.1"
+	// ScopeSystem is the machine-wide config shared by all users
+	ScopeSystem Scope = "system"
+	// ScopeUser is the config stored in AKAMAI_CLI_HOME
+	ScopeUser Scope = "user"
+	// ScopeProject is the .akamai-cli.ini file found in the current directory or one of its parents
+	ScopeProject Scope = "project"
+	// ScopeProfile holds the values of the active profile, defined in any of the config files
+	ScopeProfile Scope = "profile"
+	// ScopeEnv holds AKAMAI_<SECTION>_<KEY> environment overrides
+	ScopeEnv Scope = "env"
+
+	projectConfigFile = ".akamai-cli.ini"
+
+	// PackageSectionPrefix starts the name of sections holding the settings of a single package, such as [package.property-manager]
+	PackageSectionPrefix = "package."
+	// ProfileSectionPrefix starts the name of sections defining a profile, such as [profile prod].
+	// Keys of a profile section are given as <section>.<key> and override the values of the config files when the profile is used.
+	ProfileSectionPrefix = "profile "
 )
 
 type (
 	// Config contains methods to operate on CLI config
 	Config interface {
 		Save(context.Context) error
+		Reload() error
 		Values() map[string]map[string]string
+		UserValues() map[string]map[string]string
 		GetValue(string, string) (string, bool)
 		SetValue(string, string, string)
 		UnsetValue(string, string)
+		SetScopedValue(Scope, string, string, string) error
+		Origin(string, string) (Origin, bool)
+		Migrate(context.Context, bool) (*MigrationResult, error)
 		ExportEnv(context.Context) error
+		Profiles() []string
+		Profile() string
+		UseProfile(string) error
+		CreateProfile(string) error
+		DeleteProfile(string) error
 	}
 
 	// IniConfig represents a config stored in ini file
+	// Values from the user file are layered on top of the system file and below the project file and environment
 	IniConfig struct {
-		path string
-		file *ini.File
+		path    string
+		file    *ini.File
+		layers  []*layer
+		profile string
+	}
+
+	// Scope identifies one of the config layers
+	Scope string
+
+	// Origin describes where a config value comes from
+	Origin struct {
+		Scope Scope
+		// Path is the file containing the value, or the environment variable name for ScopeEnv
+		Path string
+	}
+
+	layer struct {
+		scope Scope
+		path  string
+		file  *ini.File
+		dirty bool
 	}
 
 	contextType string
@@ -40,21 +92,145 @@ type (
 
 var configContext contextType = "config"
 
+var (
+	// ErrUnknownProfile is returned when a profile is not defined in any of the config files
+	ErrUnknownProfile = errors.New("unknown profile")
+	// ErrProfileExists is returned when creating a profile which is already defined
+	ErrProfileExists = errors.New("profile already exists")
+
+	profileNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+)
+
 // NewIni finds an existing ini file with config or creates new one and returns IniConfig
+// The system and project config files, as well as environment overrides, are loaded alongside the user file
 func NewIni() (*IniConfig, error) {
 	path, err := getConfigFilePath()
 	if err != nil {
 		return nil, err
 	}
-	if _, err = os.Stat(path); os.IsNotExist(err) {
-		iniFile := ini.Empty()
-		return &IniConfig{path: path, file: iniFile}, nil
+	iniFile, err := loadIniFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &IniConfig{path: path, file: iniFile}
+
+	systemFile, err := loadIniFile(getSystemConfigFilePath())
+	if err != nil {
+		return nil, err
 	}
-	iniFile, err := ini.Load(path)
+	cfg.layers = append(cfg.layers,
+		&layer{scope: ScopeSystem, path: getSystemConfigFilePath(), file: systemFile},
+		&layer{scope: ScopeUser, path: path, file: iniFile},
+	)
+
+	projectPath, err := findProjectConfigFile()
 	if err != nil {
 		return nil, err
 	}
-	return &IniConfig{path: path, file: iniFile}, nil
+	projectFile, err := loadIniFile(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg.layers = append(cfg.layers, &layer{scope: ScopeProject, path: projectPath, file: projectFile})
+	cfg.layers = append(cfg.layers, envLayer(cfg.layers))
+
+	return cfg, nil
+}
+
+func loadIniFile(path string) (*ini.File, error) {
+	if path == "" {
+		return ini.Empty(), nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return ini.Empty(), nil
+	}
+	return ini.Load(path)
+}
+
+// envLayer collects AKAMAI_<SECTION>_<KEY> variables overriding known keys, or keys defined in any of the config files
+func envLayer(layers []*layer) *layer {
+	env := &layer{scope: ScopeEnv, file: ini.Empty()}
+	for _, key := range schema {
+		if value, ok := os.LookupEnv(envVarName(key.Section, key.Name)); ok {
+			env.file.Section(key.Section).Key(key.Name).SetValue(value)
+		}
+	}
+	for _, l := range layers {
+		for _, section := range l.file.Sections() {
+			if section.Name() == ini.DefaultSection || IsPackageSection(section.Name()) || IsContextSection(section.Name()) || IsAccountSection(section.Name()) {
+				continue
+			}
+			for _, key := range section.Keys() {
+				sectionName, keyName := section.Name(), key.Name()
+				if isProfileSection(sectionName) {
+					var ok bool
+					if sectionName, keyName, ok = splitProfileKey(keyName); !ok || IsPackageSection(sectionName) {
+						continue
+					}
+				}
+				if value, ok := os.LookupEnv(envVarName(sectionName, keyName)); ok {
+					env.file.Section(sectionName).Key(keyName).SetValue(value)
+				}
+			}
+		}
+	}
+	return env
+}
+
+// ProfileSection returns the name of the section defining given profile
+func ProfileSection(name string) string {
+	return ProfileSectionPrefix + name
+}
+
+func isProfileSection(section string) bool {
+	return strings.HasPrefix(section, ProfileSectionPrefix)
+}
+
+// splitProfileKey splits a <section>.<key> key of a profile section. The key is separated at the last dot,
+// so that keys of package sections, such as package.purge.network, are supported.
+func splitProfileKey(name string) (string, string, bool) {
+	i := strings.LastIndex(name, ".")
+	if i <= 0 || i == len(name)-1 {
+		return "", "", false
+	}
+	return name[:i], name[i+1:], true
+}
+
+func envVarName(section, key string) string {
+	envVar := "AKAMAI_" + strings.ToUpper(section) + "_"
+	envVar += strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+	return envVar
+}
+
+// PackageSection returns the name of the section holding the settings of given package
+func PackageSection(name string) string {
+	return PackageSectionPrefix + name
+}
+
+// IsPackageSection checks whether the section holds the settings of a single package
+func IsPackageSection(section string) bool {
+	return strings.HasPrefix(section, PackageSectionPrefix)
+}
+
+// PackageEnv returns the values of the package section as AKAMAI_<PACKAGE>_<KEY>=<value> entries, to be added to
+// the environment of the package process only. Variables already set in the environment take precedence and are skipped.
+func PackageEnv(cfg Config, name string) []string {
+	values := cfg.Values()[PackageSection(name)]
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	env := make([]string, 0, len(keys))
+	for _, key := range keys {
+		envVar := envVarName(strings.ReplaceAll(name, "-", "_"), key)
+		if _, ok := os.LookupEnv(envVar); ok {
+			continue
+		}
+		env = append(env, envVar+"="+values[key])
+	}
+	return env
 }
 
 // Context sets the config in the context
@@ -72,10 +248,10 @@ func Get(ctx context.Context) Config {
 	return t
 }
 
-// Save stores the ini file in filesystem
+// Save stores the ini file in filesystem, along with any other config file modified using SetScopedValue
 func (c *IniConfig) Save(ctx context.Context) error {
 	term := terminal.Get(ctx)
-	if err := c.file.SaveTo(c.path); err != nil {
+	if err := c.saveFiles(); err != nil {
 		if _, err := term.Writeln(err.Error()); err != nil {
 			return err
 		}
@@ -86,10 +262,83 @@ func (c *IniConfig) Save(ctx context.Context) error {
 	return nil
 }
 
+// Reload reads the config files again, keeping the active profile, so that changes written by other processes
+// since the config was loaded are not overwritten by the next Save
+func (c *IniConfig) Reload() error {
+	reloaded, err := NewIni()
+	if err != nil {
+		return err
+	}
+	if err := reloaded.UseProfile(c.profile); err != nil {
+		return err
+	}
+	*c = *reloaded
+	return nil
+}
+
+func (c *IniConfig) saveFiles() error {
+	if err := writeIniFile(c.path, c.file); err != nil {
+		return err
+	}
+	for _, l := range c.layers {
+		if !l.dirty {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+			return err
+		}
+		if err := writeIniFile(l.path, l.file); err != nil {
+			return err
+		}
+		l.dirty = false
+	}
+	return nil
+}
+
+// writeIniFile replaces the file at path atomically, keeping its permissions
+func writeIniFile(path string, file *ini.File) error {
+	var buf bytes.Buffer
+	if _, err := file.WriteTo(&buf); err != nil {
+		return err
+	}
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	return tools.WriteFileAtomic(path, buf.Bytes(), perm)
+}
+
 // Values returns a map containing sections from the config. Each section contans a key-value map of its contents
+// Values from all layers are merged, with the project file, the active profile and environment taking precedence.
+// Sections defining profiles are not included.
 func (c *IniConfig) Values() map[string]map[string]string {
+	sections := make(map[string]map[string]string)
+	for _, l := range c.effectiveLayers() {
+		for _, section := range l.file.Sections() {
+			if isProfileSection(section.Name()) {
+				continue
+			}
+			values, ok := sections[section.Name()]
+			if !ok {
+				values = make(map[string]string)
+				sections[section.Name()] = values
+			}
+			for _, key := range section.Keys() {
+				values[key.Name()] = key.String()
+			}
+		}
+	}
+	return sections
+}
+
+// UserValues returns the sections of the user config file, including those defining profiles.
+// Values of the other layers are not included.
+func (c *IniConfig) UserValues() map[string]map[string]string {
 	sections := make(map[string]map[string]string)
 	for _, section := range c.file.Sections() {
+		if section.Name() == ini.DefaultSection && len(section.Keys()) == 0 {
+			continue
+		}
 		values := make(map[string]string)
 		for _, key := range section.Keys() {
 			values[key.Name()] = key.String()
@@ -101,11 +350,59 @@ func (c *IniConfig) Values() map[string]map[string]string {
 
 // GetValue fetches a value from provided section under provided key
 func (c *IniConfig) GetValue(section, key string) (string, bool) {
-	s := c.file.Section(section)
-	if !s.HasKey(key) {
-		return "", false
+	layers := c.effectiveLayers()
+	for i := len(layers) - 1; i >= 0; i-- {
+		s, err := layers[i].file.GetSection(section)
+		if err != nil || !s.HasKey(key) {
+			continue
+		}
+		return s.Key(key).String(), true
 	}
-	return s.Key(key).String(), true
+	return "", false
+}
+
+// Origin returns the scope and location of the layer providing the value of given key
+func (c *IniConfig) Origin(section, key string) (Origin, bool) {
+	layers := c.effectiveLayers()
+	for i := len(layers) - 1; i >= 0; i-- {
+		s, err := layers[i].file.GetSection(section)
+		if err != nil || !s.HasKey(key) {
+			continue
+		}
+		if layers[i].scope == ScopeEnv {
+			return Origin{Scope: ScopeEnv, Path: envVarName(section, key)}, true
+		}
+		return Origin{Scope: layers[i].scope, Path: layers[i].path}, true
+	}
+	return Origin{}, false
+}
+
+// SetScopedValue sets a key in provided section of the config file for given scope
+// When no project file exists, a new one is created in the current directory on Save
+func (c *IniConfig) SetScopedValue(scope Scope, section, key, value string) error {
+	if scope == ScopeUser {
+		c.SetValue(section, key, value)
+		return nil
+	}
+	for _, l := range c.layers {
+		if l.scope != scope {
+			continue
+		}
+		if l.scope == ScopeEnv {
+			return errors.New("environment overrides cannot be modified, set the environment variable instead")
+		}
+		if l.path == "" {
+			wd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			l.path = filepath.Join(wd, projectConfigFile)
+		}
+		l.file.Section(section).Key(key).SetValue(value)
+		l.dirty = true
+		return nil
+	}
+	return fmt.Errorf("unknown config scope: %s", scope)
 }
 
 // SetValue sets a key in provided section
@@ -114,24 +411,32 @@ func (c *IniConfig) SetValue(section, key, value string) {
 	s.Key(key).SetValue(value)
 }
 
-// UnsetValue unsets a key in provided section
+// UnsetValue unsets a key in provided section. Sections left without keys are removed.
 func (c *IniConfig) UnsetValue(section, key string) {
 	s := c.file.Section(section)
 	s.DeleteKey(key)
+	if len(s.Keys()) == 0 {
+		c.file.DeleteSection(section)
+	}
 }
 
 // ExportEnv exports values from config file as environmental variables, prefixing each with AKAMAI_<SECTION_NAME>
+// Package sections are skipped, as they are only passed to their package process, see PackageEnv.
+// Context and account sections are skipped too, they only supply the values of the global flags.
+// Values referencing a secret, such as secret://prod/client_secret, are exported as is, so that the vault is only
+// unlocked by the package commands which get them.
 // It also attempts migration from previous config versions
 func (c *IniConfig) ExportEnv(ctx context.Context) error {
-	if err := migrateConfig(ctx, c); err != nil {
+	if _, err := c.Migrate(ctx, false); err != nil {
 		return err
 	}
 
-	for _, section := range c.file.Sections() {
-		for _, key := range section.Keys() {
-			envVar := "AKAMAI_" + strings.ToUpper(section.Name()) + "_"
-			envVar += strings.ToUpper(strings.ReplaceAll(key.Name(), "-", "_"))
-			if err := os.Setenv(envVar, key.String()); err != nil {
+	for section, values := range c.Values() {
+		if IsPackageSection(section) || IsContextSection(section) || IsAccountSection(section) {
+			continue
+		}
+		for key, value := range values {
+			if err := os.Setenv(envVarName(section, key), value); err != nil {
 				return err
 			}
 		}
@@ -139,77 +444,148 @@ func (c *IniConfig) ExportEnv(ctx context.Context) error {
 	return nil
 }
 
-func getConfigFilePath() (string, error) {
-	cliPath, err := tools.GetAkamaiCliPath()
-	if err != nil {
-		return "", err
+// Profiles returns the names of profiles defined in any of the config files, sorted by name
+func (c *IniConfig) Profiles() []string {
+	var names []string
+	for _, l := range c.effectiveLayers() {
+		if l.scope == ScopeEnv || l.scope == ScopeProfile {
+			continue
+		}
+		for _, section := range l.file.Sections() {
+			if name := strings.TrimPrefix(section.Name(), ProfileSectionPrefix); isProfileSection(section.Name()) && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
 	}
+	sort.Strings(names)
+	return names
+}
 
-	return filepath.Join(cliPath, "config"), nil
+// Profile returns the name of the active profile, or an empty string if no profile is used
+func (c *IniConfig) Profile() string {
+	return c.profile
 }
 
-func migrateConfig(ctx context.Context, cfg *IniConfig) error {
-	var currentVersion string
-	if _, err := os.Stat(cfg.path); err == nil {
-		// Do we need to migrate from an older version?
-		currentVersion, _ = cfg.GetValue("cli", "config-version")
-		if currentVersion == configVersion {
-			return nil
-		}
+// UseProfile makes the values of given profile override those of the config files. An empty name stops using a profile.
+func (c *IniConfig) UseProfile(name string) error {
+	if name != "" && !slices.Contains(c.Profiles(), name) {
+		return fmt.Errorf("%w: %s", ErrUnknownProfile, name)
 	}
+	c.profile = name
+	return nil
+}
 
-	switch currentVersion {
-	case "":
-		// Create v1
-		cliPath, err := tools.GetAkamaiCliPath()
-		if err != nil {
-			return err
-		}
+// CreateProfile adds an empty profile to the user config file
+func (c *IniConfig) CreateProfile(name string) error {
+	if !profileNameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q, only letters, digits, '.', '-' and '_' are allowed", name)
+	}
+	if slices.Contains(c.Profiles(), name) {
+		return fmt.Errorf("%w: %s", ErrProfileExists, name)
+	}
+	_, err := c.file.NewSection(ProfileSection(name))
+	return err
+}
 
-		var data []byte
-		upgradeFile := filepath.Join(cliPath, ".upgrade-check")
-		if _, err := os.Stat(upgradeFile); err == nil {
-			data, err = os.ReadFile(upgradeFile)
-			if err != nil {
-				return err
-			}
-		} else {
-			upgradeFile = filepath.Join(cliPath, ".update-check")
-			if _, err := os.Stat(upgradeFile); err == nil {
-				data, err = os.ReadFile(upgradeFile)
-				if err != nil {
-					return err
-				}
+// DeleteProfile removes a profile from the user config file, and stops using it by default
+func (c *IniConfig) DeleteProfile(name string) error {
+	if _, err := c.file.GetSection(ProfileSection(name)); err != nil {
+		for _, l := range c.layers {
+			if _, err := l.file.GetSection(ProfileSection(name)); err == nil && l.scope != ScopeUser {
+				return fmt.Errorf("profile %s is defined in %s, remove it from that file instead", name, l.path)
 			}
 		}
+		return fmt.Errorf("%w: %s", ErrUnknownProfile, name)
+	}
+	c.file.DeleteSection(ProfileSection(name))
+	if s, err := c.file.GetSection("cli"); err == nil && s.HasKey("profile") && s.Key("profile").String() == name {
+		s.DeleteKey("profile")
+	}
+	if c.profile == name {
+		c.profile = ""
+	}
+	return nil
+}
 
-		if len(data) != 0 {
-			date := string(data)
-			if date == "never" || date == "ignore" {
-				cfg.SetValue("cli", "last-upgrade-check", date)
-			} else {
-				if m := strings.LastIndex(date, "m="); m != -1 {
-					date = date[0 : m-1]
-				}
-				lastUpgrade, err := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", date)
-				if err == nil {
-					cfg.SetValue("cli", "last-upgrade-check", lastUpgrade.Format(time.RFC3339))
-				}
-			}
+// effectiveLayers returns config layers ordered from the lowest to the highest precedence
+// Values of the active profile are placed above the config files and below environment overrides
+func (c *IniConfig) effectiveLayers() []*layer {
+	layers := c.layers
+	if len(layers) == 0 {
+		layers = []*layer{{scope: ScopeUser, path: c.path, file: c.file}}
+	}
+	if c.profile == "" {
+		return layers
+	}
 
-			if err := os.Remove(upgradeFile); err != nil {
-				return err
-			}
+	effective := make([]*layer, 0, len(layers)+1)
+	var profiles []*layer
+	for _, l := range layers {
+		if l.scope == ScopeEnv {
+			effective = append(effective, profiles...)
+			profiles = nil
+		}
+		effective = append(effective, l)
+		if l.scope == ScopeEnv {
+			continue
+		}
+		if p := profileLayer(l, c.profile); p != nil {
+			profiles = append(profiles, p)
+		}
+	}
+	return append(effective, profiles...)
+}
+
+// profileLayer returns the values the profile defines in the given config file, or nil if the file does not define the profile
+func profileLayer(l *layer, profile string) *layer {
+	section, err := l.file.GetSection(ProfileSection(profile))
+	if err != nil {
+		return nil
+	}
+	p := &layer{scope: ScopeProfile, path: l.path, file: ini.Empty()}
+	for _, key := range section.Keys() {
+		if sectionName, keyName, ok := splitProfileKey(key.Name()); ok {
+			p.file.Section(sectionName).Key(keyName).SetValue(key.String())
 		}
+	}
+	return p
+}
 
-		cfg.SetValue("cli", "config-version", "1")
-	case "1":
-		// Upgrade to v1.1
-		cfg.SetValue("cli", "config-version", "1.1")
+func getConfigFilePath() (string, error) {
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return "", err
 	}
 
-	if err := cfg.Save(ctx); err != nil {
-		return err
+	return filepath.Join(cliPath, "config"), nil
+}
+
+// getSystemConfigFilePath returns the machine-wide config location, which can be overridden with AKAMAI_CLI_SYSTEM_CONFIG
+func getSystemConfigFilePath() string {
+	if path := os.Getenv("AKAMAI_CLI_SYSTEM_CONFIG"); path != "" {
+		return path
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("ProgramData"), "akamai-cli", "config")
+	}
+	return filepath.Join("/etc", "akamai-cli", "config")
+}
+
+// findProjectConfigFile walks up from the current directory looking for a project config file
+func findProjectConfigFile() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, projectConfigFile)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
 	}
-	return migrateConfig(ctx, cfg)
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/akamai/cli/v2/pkg/vault"
 	"github.com/go-ini/ini"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -322,3 +323,546 @@ func TestExportConfigEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestLayeredConfig(t *testing.T) {
+	root := t.TempDir()
+	systemPath := filepath.Join(root, "system", "config")
+	userHome := filepath.Join(root, "home")
+	projectDir := filepath.Join(root, "project")
+	workDir := filepath.Join(projectDir, "sub", "dir")
+	require.NoError(t, os.MkdirAll(filepath.Dir(systemPath), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(userHome, ".akamai-cli"), 0755))
+	require.NoError(t, os.MkdirAll(workDir, 0755))
+	require.NoError(t, os.WriteFile(systemPath, []byte("[cli]\nsystem-key = system\nshared = system\n[edgerc]\nsection = system\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(userHome, ".akamai-cli", "config"), []byte("[cli]\nshared = user\nuser-key = user\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".akamai-cli.ini"), []byte("[cli]\nuser-key = project\n[edgerc]\nsection = project\n"), 0644))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(workDir))
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", userHome))
+	require.NoError(t, os.Setenv("AKAMAI_CLI_SYSTEM_CONFIG", systemPath))
+	require.NoError(t, os.Setenv("AKAMAI_EDGERC_SECTION", "env"))
+	defer func() {
+		require.NoError(t, os.Chdir(wd))
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_HOME"))
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_SYSTEM_CONFIG"))
+		require.NoError(t, os.Unsetenv("AKAMAI_EDGERC_SECTION"))
+	}()
+
+	cfg, err := NewIni()
+	require.NoError(t, err)
+	projectPath := filepath.Join(projectDir, ".akamai-cli.ini")
+	if resolved, err := filepath.EvalSymlinks(projectDir); err == nil {
+		projectPath = filepath.Join(resolved, ".akamai-cli.ini")
+	}
+
+	tests := map[string]struct {
+		section, key string
+		value        string
+		origin       Origin
+	}{
+		"value from system file": {
+			section: "cli", key: "system-key", value: "system",
+			origin: Origin{Scope: ScopeSystem, Path: systemPath},
+		},
+		"user file overrides system file": {
+			section: "cli", key: "shared", value: "user",
+			origin: Origin{Scope: ScopeUser, Path: filepath.Join(userHome, ".akamai-cli", "config")},
+		},
+		"project file overrides user file": {
+			section: "cli", key: "user-key", value: "project",
+			origin: Origin{Scope: ScopeProject, Path: projectPath},
+		},
+		"environment overrides project file": {
+			section: "edgerc", key: "section", value: "env",
+			origin: Origin{Scope: ScopeEnv, Path: "AKAMAI_EDGERC_SECTION"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			val, ok := cfg.GetValue(test.section, test.key)
+			assert.True(t, ok)
+			assert.Equal(t, test.value, val)
+			assert.Equal(t, test.value, cfg.Values()[test.section][test.key])
+			origin, ok := cfg.Origin(test.section, test.key)
+			assert.True(t, ok)
+			assert.Equal(t, test.origin, origin)
+		})
+	}
+	assert.Equal(t, map[string]map[string]string{"cli": {"shared": "user", "user-key": "user"}}, cfg.UserValues())
+
+	ctx := terminal.Context(context.Background(), &terminal.Mock{})
+	require.NoError(t, cfg.SetScopedValue(ScopeProject, "cli", "new-key", "project"))
+	require.NoError(t, cfg.SetScopedValue(ScopeSystem, "cli", "system-key", "updated"))
+	assert.EqualError(t, cfg.SetScopedValue(ScopeEnv, "cli", "new-key", "env"), "environment overrides cannot be modified, set the environment variable instead")
+	assert.EqualError(t, cfg.SetScopedValue("other", "cli", "new-key", "env"), "unknown config scope: other")
+	require.NoError(t, cfg.Save(ctx))
+
+	project, err := ini.Load(projectPath)
+	require.NoError(t, err)
+	assert.Equal(t, "project", project.Section("cli").Key("new-key").String())
+	system, err := ini.Load(systemPath)
+	require.NoError(t, err)
+	assert.Equal(t, "updated", system.Section("cli").Key("system-key").String())
+	user, err := ini.Load(filepath.Join(userHome, ".akamai-cli", "config"))
+	require.NoError(t, err)
+	assert.False(t, user.Section("cli").HasKey("new-key"))
+}
+
+func TestSetScopedValueCreatesProjectFile(t *testing.T) {
+	root := t.TempDir()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(root))
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", root))
+	require.NoError(t, os.Setenv("AKAMAI_CLI_SYSTEM_CONFIG", filepath.Join(root, "system")))
+	defer func() {
+		require.NoError(t, os.Chdir(wd))
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_HOME"))
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_SYSTEM_CONFIG"))
+	}()
+
+	cfg, err := NewIni()
+	require.NoError(t, err)
+	require.NoError(t, cfg.SetScopedValue(ScopeProject, "cli", "key", "value"))
+	require.NoError(t, cfg.Save(terminal.Context(context.Background(), &terminal.Mock{})))
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(cwd, ".akamai-cli.ini"))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(root, "system"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestPackageEnv(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".akamai-cli"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".akamai-cli", "config"),
+		[]byte("[cli]\nconfig-version = 1.1\n[package.property-manager]\ndefault-contract = ctr_1\nformat = json\n[package.purge]\nnetwork = staging\n"), 0644))
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", root))
+	require.NoError(t, os.Setenv("AKAMAI_CLI_SYSTEM_CONFIG", filepath.Join(root, "system")))
+	require.NoError(t, os.Setenv("AKAMAI_PROPERTY_MANAGER_FORMAT", "text"))
+	defer func() {
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_HOME"))
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_SYSTEM_CONFIG"))
+		require.NoError(t, os.Unsetenv("AKAMAI_PROPERTY_MANAGER_FORMAT"))
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_CONFIG_VERSION"))
+	}()
+
+	cfg, err := NewIni()
+	require.NoError(t, err)
+	require.NoError(t, cfg.ExportEnv(terminal.Context(context.Background(), &terminal.Mock{})))
+
+	assert.Equal(t, []string{"AKAMAI_PROPERTY_MANAGER_DEFAULT_CONTRACT=ctr_1"}, PackageEnv(cfg, "property-manager"))
+	assert.Equal(t, []string{"AKAMAI_PURGE_NETWORK=staging"}, PackageEnv(cfg, "purge"))
+	assert.Empty(t, PackageEnv(cfg, "echo"))
+	_, ok := os.LookupEnv("AKAMAI_PROPERTY_MANAGER_DEFAULT_CONTRACT")
+	assert.False(t, ok)
+	_, ok = os.LookupEnv("AKAMAI_PURGE_NETWORK")
+	assert.False(t, ok)
+}
+
+func TestExportEnvSecrets(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".akamai-cli"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".akamai-cli", "config"),
+		[]byte("[cli]\nconfig-version = 1.1\n[api]\ntoken = secret://api/token\n[other]\nmissing = secret://other/missing\nplain = value\n"), 0644))
+	t.Setenv("AKAMAI_CLI_HOME", root)
+	t.Setenv("AKAMAI_CLI_SYSTEM_CONFIG", filepath.Join(root, "system"))
+	defer func() {
+		for _, name := range []string{"AKAMAI_CLI_CONFIG_VERSION", "AKAMAI_API_TOKEN", "AKAMAI_OTHER_MISSING", "AKAMAI_OTHER_PLAIN"} {
+			require.NoError(t, os.Unsetenv(name))
+		}
+	}()
+	vaultPath := filepath.Join(root, ".akamai-cli", vault.FileName)
+	v, err := vault.Open(vaultPath, "passphrase")
+	require.NoError(t, err)
+	require.NoError(t, v.Set("api/token", "s3cret"))
+	require.NoError(t, v.Save())
+
+	cfg, err := NewIni()
+	require.NoError(t, err)
+	ctx := terminal.Context(context.Background(), &terminal.Mock{})
+	ctx = vault.Context(ctx, vault.NewSession(vaultPath, func(bool) (string, error) {
+		t.Fatal("vault must not be unlocked")
+		return "", nil
+	}))
+	require.NoError(t, cfg.ExportEnv(ctx))
+
+	assert.Equal(t, "secret://api/token", os.Getenv("AKAMAI_API_TOKEN"))
+	assert.Equal(t, "secret://other/missing", os.Getenv("AKAMAI_OTHER_MISSING"))
+	assert.Equal(t, "value", os.Getenv("AKAMAI_OTHER_PLAIN"))
+	value, _ := cfg.GetValue("api", "token")
+	assert.Equal(t, "secret://api/token", value)
+}
+
+func TestMigrate(t *testing.T) {
+	tests := map[string]struct {
+		configVersion   string
+		upgradeFile     string
+		dryRun          bool
+		expected        *MigrationResult
+		expectedVersion string
+		withBackup      bool
+		withError       error
+	}{
+		"up to date": {
+			configVersion:   "1.1",
+			expected:        &MigrationResult{Version: "1.1", Migrations: []Migration{}, Changes: []Change{}},
+			expectedVersion: "1.1",
+		},
+		"dry run does not change config": {
+			configVersion: "1",
+			dryRun:        true,
+			expected: &MigrationResult{
+				Version:    "1.1",
+				Migrations: []Migration{migrations[1].Migration},
+				Changes:    []Change{{Section: "cli", Key: "config-version", Old: "1", New: "1.1"}},
+			},
+			expectedVersion: "1",
+		},
+		"migrate from first version with backup": {
+			upgradeFile: "never",
+			expected: &MigrationResult{
+				Version:    "1.1",
+				Migrations: []Migration{migrations[0].Migration, migrations[1].Migration},
+				Changes: []Change{
+					{Section: "cli", Key: "config-version", New: "1.1"},
+					{Section: "cli", Key: "last-upgrade-check", New: "never"},
+				},
+			},
+			expectedVersion: "1.1",
+			withBackup:      true,
+		},
+		"config from newer cli": {
+			configVersion: "2.0",
+			withError:     ErrUnsupportedConfigVersion,
+		},
+		"unknown config version": {
+			configVersion: "beta",
+			withError:     ErrUnsupportedConfigVersion,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", dir))
+			defer func() {
+				require.NoError(t, os.Unsetenv("AKAMAI_CLI_HOME"))
+			}()
+			cliPath := filepath.Join(dir, ".akamai-cli")
+			require.NoError(t, os.MkdirAll(cliPath, 0755))
+			content := "[cli]\nsome-key = test\n"
+			if test.configVersion != "" {
+				content += "config-version = " + test.configVersion + "\n"
+			}
+			require.NoError(t, os.WriteFile(filepath.Join(cliPath, "config"), []byte(content), 0644))
+			upgradeFile := filepath.Join(cliPath, ".upgrade-check")
+			if test.upgradeFile != "" {
+				require.NoError(t, os.WriteFile(upgradeFile, []byte(test.upgradeFile), 0644))
+				test.expected.RemovedFiles = []string{upgradeFile}
+			}
+
+			cfg, err := NewIni()
+			require.NoError(t, err)
+			ctx := terminal.Context(context.Background(), &terminal.Mock{})
+			result, err := cfg.Migrate(ctx, test.dryRun)
+			if test.withError != nil {
+				assert.ErrorIs(t, err, test.withError)
+				return
+			}
+			require.NoError(t, err)
+
+			if test.withBackup {
+				require.NotEmpty(t, result.Backup)
+				backup, err := os.ReadFile(result.Backup)
+				require.NoError(t, err)
+				assert.Equal(t, content, string(backup))
+				test.expected.Backup = result.Backup
+				_, err = os.Stat(upgradeFile)
+				assert.True(t, os.IsNotExist(err))
+			}
+			assert.Equal(t, test.expected, result)
+
+			saved, err := ini.Load(filepath.Join(cliPath, "config"))
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedVersion, saved.Section("cli").Key("config-version").String())
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		section, key, value string
+		withError           string
+	}{
+		"package section is not validated": {section: "echo", key: "any-key", value: "any value"},
+		"valid duration":                   {section: "cli", key: "lock-timeout", value: "1m30s"},
+		"invalid duration": {
+			section: "cli", key: "lock-timeout", value: "soon",
+			withError: `invalid config value for cli.lock-timeout: "soon", expected a duration, such as 30s or 5m`,
+		},
+		"valid timestamp":       {section: "cli", key: "last-upgrade-check", value: "2021-02-03T16:46:43Z"},
+		"allowed special value": {section: "cli", key: "last-upgrade-check", value: "ignore"},
+		"invalid timestamp": {
+			section: "cli", key: "last-upgrade-check", value: "2021-02-03 16:46:43",
+			withError: `invalid config value for cli.last-upgrade-check: "2021-02-03 16:46:43", expected an RFC 3339 timestamp, such as 2021-02-03T16:46:43Z, or one of: "never", "ignore"`,
+		},
+		"value not allowed": {
+			section: "cli", key: "install-in-path", value: "yes",
+			withError: `invalid config value for cli.install-in-path: "yes", allowed values are: "", "no"`,
+		},
+		"valid list": {section: "cli", key: "registries", value: "https://example.com/packages.json,/etc/packages.json"},
+		"list with empty item": {
+			section: "cli", key: "registries", value: "https://example.com/packages.json,",
+			withError: `invalid config value for cli.registries: "https://example.com/packages.json,", expected a comma separated list without empty items`,
+		},
+		"typo in key": {
+			section: "cli", key: "cache-pth", value: "/tmp",
+			withError: "unknown config key cli.cache-pth, did you mean cli.cache-path?",
+		},
+		"unknown key": {
+			section: "cli", key: "something-else", value: "value",
+			withError: "unknown config key cli.something-else",
+		},
+		"read-only key": {
+			section: "cli", key: "config-version", value: "1.1",
+			withError: "cli.config-version is managed by Akamai CLI and cannot be set",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := Validate(test.section, test.key, test.value)
+			if test.withError != "" {
+				assert.EqualError(t, err, test.withError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	root := t.TempDir()
+	userHome := filepath.Join(root, "home")
+	projectDir := filepath.Join(root, "project")
+	require.NoError(t, os.MkdirAll(filepath.Join(userHome, ".akamai-cli"), 0755))
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(userHome, ".akamai-cli", "config"),
+		[]byte("[cli]\ncache-path = /cache\nprofile = prod\n[edgerc]\nsection = default\n[profile prod]\ncli.cache-path = /cache/prod\nedgerc.section = prod\npackage.purge.network = production\n[profile staging]\nedgerc.section = staging\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".akamai-cli.ini"), []byte("[profile prod]\nedgerc.section = project-prod\n[profile local]\n"), 0644))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(projectDir))
+	require.NoError(t, os.Setenv("AKAMAI_CLI_HOME", userHome))
+	require.NoError(t, os.Setenv("AKAMAI_CLI_SYSTEM_CONFIG", filepath.Join(root, "system")))
+	require.NoError(t, os.Setenv("AKAMAI_CLI_CACHE_PATH", "/env"))
+	defer func() {
+		require.NoError(t, os.Chdir(wd))
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_HOME"))
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_SYSTEM_CONFIG"))
+		require.NoError(t, os.Unsetenv("AKAMAI_CLI_CACHE_PATH"))
+	}()
+
+	cfg, err := NewIni()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"local", "prod", "staging"}, cfg.Profiles())
+	assert.Equal(t, "", cfg.Profile())
+	value, _ := cfg.GetValue("edgerc", "section")
+	assert.Equal(t, "default", value)
+
+	assert.ErrorIs(t, cfg.UseProfile("dev"), ErrUnknownProfile)
+	require.NoError(t, cfg.UseProfile("prod"))
+	assert.Equal(t, "prod", cfg.Profile())
+	require.NoError(t, cfg.Reload())
+	assert.Equal(t, "prod", cfg.Profile(), "the profile is kept when the config is reloaded")
+
+	value, _ = cfg.GetValue("edgerc", "section")
+	assert.Equal(t, "project-prod", value)
+	origin, _ := cfg.Origin("edgerc", "section")
+	assert.Equal(t, ScopeProfile, origin.Scope)
+	value, _ = cfg.GetValue("cli", "cache-path")
+	assert.Equal(t, "/env", value, "environment overrides take precedence over profiles")
+	values := cfg.Values()
+	assert.Equal(t, "production", values["package.purge"]["network"])
+	assert.NotContains(t, values, "profile prod")
+	assert.Equal(t, "/cache/prod", cfg.UserValues()["profile prod"]["cli.cache-path"])
+
+	require.NoError(t, cfg.CreateProfile("dev"))
+	assert.ErrorIs(t, cfg.CreateProfile("dev"), ErrProfileExists)
+	assert.Error(t, cfg.CreateProfile("bad name"))
+	assert.Error(t, cfg.DeleteProfile("local"), "profiles defined in other files are not removed")
+	assert.ErrorIs(t, cfg.DeleteProfile("unknown"), ErrUnknownProfile)
+	require.NoError(t, cfg.DeleteProfile("staging"))
+	require.NoError(t, cfg.DeleteProfile("prod"))
+	assert.Equal(t, "", cfg.Profile())
+	require.NoError(t, cfg.Save(terminal.Context(context.Background(), &terminal.Mock{})))
+
+	saved, err := ini.Load(filepath.Join(userHome, ".akamai-cli", "config"))
+	require.NoError(t, err)
+	assert.False(t, saved.Section("cli").HasKey("profile"))
+	_, err = saved.GetSection("profile dev")
+	assert.NoError(t, err)
+	_, err = saved.GetSection("profile staging")
+	assert.Error(t, err)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	values := map[string]map[string]string{
+		"cli":           {"cache-path": "/home/user/.akamai-cli/cache", "lock-timeout": "30s"},
+		"edgerc":        {"section": "my section"},
+		"package.purge": {"network": "staging"},
+	}
+	tests := map[string]struct {
+		format   string
+		encoded  string
+		expected map[string]map[string]string
+	}{
+		"ini": {
+			format:   FormatINI,
+			encoded:  "[cli]\ncache-path   = /home/user/.akamai-cli/cache\nlock-timeout = 30s\n\n[edgerc]\nsection = my section\n\n[package.purge]\nnetwork = staging\n",
+			expected: values,
+		},
+		"json": {
+			format: FormatJSON,
+			encoded: `{
+  "cli": {
+    "cache-path": "/home/user/.akamai-cli/cache",
+    "lock-timeout": "30s"
+  },
+  "edgerc": {
+    "section": "my section"
+  },
+  "package.purge": {
+    "network": "staging"
+  }
+}
+`,
+			expected: values,
+		},
+		"env skips package sections": {
+			format:  FormatEnv,
+			encoded: "AKAMAI_CLI_CACHE_PATH=/home/user/.akamai-cli/cache\nAKAMAI_CLI_LOCK_TIMEOUT=30s\nAKAMAI_EDGERC_SECTION=\"my section\"\n",
+			expected: map[string]map[string]string{
+				"cli":    {"cache-path": "/home/user/.akamai-cli/cache", "lock-timeout": "30s"},
+				"edgerc": {"section": "my section"},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := Encode(values, test.format)
+			require.NoError(t, err)
+			assert.Equal(t, test.encoded, string(data))
+			decoded, err := Decode(data, test.format)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, decoded)
+		})
+	}
+
+	t.Run("env with comments, export and quotes", func(t *testing.T) {
+		decoded, err := Decode([]byte("# laptop defaults\nexport AKAMAI_CLI_LOCK_TIMEOUT='1m'\n\nAKAMAI_PURGE_DEFAULT_NETWORK=production\n"), FormatEnv)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]map[string]string{
+			"cli":   {"lock-timeout": "1m"},
+			"purge": {"default-network": "production"},
+		}, decoded)
+	})
+	t.Run("invalid env variable", func(t *testing.T) {
+		_, err := Decode([]byte("HOME=/root\n"), FormatEnv)
+		assert.EqualError(t, err, "invalid env config on line 1, HOME is not an AKAMAI_<SECTION>_<KEY> variable")
+	})
+	t.Run("nested json value", func(t *testing.T) {
+		_, err := Decode([]byte(`{"cli": {"registries": ["a", "b"]}}`), FormatJSON)
+		assert.EqualError(t, err, "invalid JSON config, value of cli.registries is not a string")
+	})
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := Encode(values, "toml")
+		assert.EqualError(t, err, `unsupported config format "toml", expected one of: ini, json, env`)
+	})
+	assert.Equal(t, FormatEnv, FormatFromPath("laptop/.env"))
+	assert.Equal(t, FormatJSON, FormatFromPath("config.JSON"))
+	assert.Equal(t, FormatINI, FormatFromPath("akamai.conf"))
+}
+
+func TestDiffValues(t *testing.T) {
+	before := map[string]map[string]string{"cli": {"a": "1", "b": "2"}, "old": {"c": "3"}}
+	after := map[string]map[string]string{"cli": {"a": "1", "b": "20"}, "new": {"d": "4"}}
+	assert.Equal(t, []Change{
+		{Section: "cli", Key: "b", Old: "2", New: "20"},
+		{Section: "new", Key: "d", New: "4"},
+		{Section: "old", Key: "c", Old: "3"},
+	}, DiffValues(before, after))
+}
+
+func TestCredentialContexts(t *testing.T) {
+	file, err := ini.Load([]byte("[cli]\ncontext = prod\n[context prod]\nedgerc = /creds/.edgerc\nsection = production\naccount-key = 1-ABCD\n[context staging]\nsection = staging\n"))
+	require.NoError(t, err)
+	cfg := &IniConfig{path: "test", file: file}
+
+	assert.Equal(t, []CredentialContext{
+		{Name: "prod", Edgerc: "/creds/.edgerc", Section: "production", AccountKey: "1-ABCD"},
+		{Name: "staging", Section: "staging"},
+	}, CredentialContexts(cfg))
+	current, ok, err := CurrentCredentialContext(cfg)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "prod", current.Name)
+
+	assert.ErrorIs(t, CreateCredentialContext(cfg, CredentialContext{Name: "prod", Section: "other"}), ErrContextExists)
+	assert.Error(t, CreateCredentialContext(cfg, CredentialContext{Name: "bad name", Section: "other"}))
+	assert.Error(t, CreateCredentialContext(cfg, CredentialContext{Name: "empty"}))
+	require.NoError(t, CreateCredentialContext(cfg, CredentialContext{Name: "dev", AccountKey: "1-DEV"}))
+	dev, err := GetCredentialContext(cfg, "dev")
+	require.NoError(t, err)
+	assert.Equal(t, "1-DEV", dev.AccountKey)
+
+	assert.ErrorIs(t, DeleteCredentialContext(cfg, "unknown"), ErrUnknownContext)
+	require.NoError(t, DeleteCredentialContext(cfg, "prod"))
+	_, ok, err = CurrentCredentialContext(cfg)
+	require.NoError(t, err)
+	assert.False(t, ok, "deleting the active context stops using it")
+	_, err = cfg.file.GetSection("context prod")
+	assert.Error(t, err, "empty context section is removed")
+
+	cfg.SetValue("cli", "context", "prod")
+	_, _, err = CurrentCredentialContext(cfg)
+	assert.ErrorIs(t, err, ErrUnknownContext)
+}
+
+func TestAccounts(t *testing.T) {
+	file, err := ini.Load([]byte("[account acme]\nkey = 1-ABC:1-2XYZ\ndescription = ACME production\n[account beta]\nkey = 1-BETA\n[account empty]\ndescription = no key\n"))
+	require.NoError(t, err)
+	cfg := &IniConfig{path: "test", file: file}
+
+	assert.Equal(t, []Account{
+		{Alias: "acme", Key: "1-ABC:1-2XYZ", Description: "ACME production"},
+		{Alias: "beta", Key: "1-BETA"},
+	}, Accounts(cfg))
+	key, ok := ExpandAccountKey(cfg, "acme")
+	assert.True(t, ok)
+	assert.Equal(t, "1-ABC:1-2XYZ", key)
+	_, ok = ExpandAccountKey(cfg, "1-ABC:1-2XYZ")
+	assert.False(t, ok)
+	_, ok = ExpandAccountKey(cfg, "")
+	assert.False(t, ok)
+
+	assert.ErrorIs(t, AddAccount(cfg, Account{Alias: "acme", Key: "1-OTHER"}), ErrAccountExists)
+	assert.Error(t, AddAccount(cfg, Account{Alias: "bad alias", Key: "1-OTHER"}))
+	assert.Error(t, AddAccount(cfg, Account{Alias: "nokey"}))
+	assert.Error(t, AddAccount(cfg, Account{Alias: "spaces", Key: "1-A 1-B"}))
+	require.NoError(t, AddAccount(cfg, Account{Alias: "dev", Key: "1-DEV"}))
+	dev, err := GetAccount(cfg, "dev")
+	require.NoError(t, err)
+	assert.Equal(t, Account{Alias: "dev", Key: "1-DEV"}, dev)
+
+	assert.ErrorIs(t, RemoveAccount(cfg, "unknown"), ErrUnknownAccount)
+	require.NoError(t, RemoveAccount(cfg, "acme"))
+	_, err = cfg.file.GetSection("account acme")
+	assert.Error(t, err, "empty account section is removed")
+}
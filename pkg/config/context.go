@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ContextSectionPrefix starts the name of sections defining a credential context, such as [context prod].
+// A context holds the edgerc, section and account-key values used as defaults of the global flags of the same name.
+const ContextSectionPrefix = "context "
+
+// CredentialContext bundles the credentials file, section and account switch key used to call Akamai APIs
+type CredentialContext struct {
+	Name       string `json:"name" yaml:"name"`
+	Edgerc     string `json:"edgerc,omitempty" yaml:"edgerc,omitempty"`
+	Section    string `json:"section,omitempty" yaml:"section,omitempty"`
+	AccountKey string `json:"account-key,omitempty" yaml:"account-key,omitempty"`
+}
+
+var (
+	// ErrUnknownContext is returned when a context is not defined in any of the config files
+	ErrUnknownContext = errors.New("unknown context")
+	// ErrContextExists is returned when creating a context which is already defined
+	ErrContextExists = errors.New("context already exists")
+)
+
+// ContextSection returns the name of the section defining given context
+func ContextSection(name string) string {
+	return ContextSectionPrefix + name
+}
+
+// IsContextSection checks whether the section defines a credential context
+func IsContextSection(section string) bool {
+	return strings.HasPrefix(section, ContextSectionPrefix)
+}
+
+// CredentialContexts returns the contexts defined in the config, sorted by name
+func CredentialContexts(cfg Config) []CredentialContext {
+	contexts := make([]CredentialContext, 0)
+	for section, values := range cfg.Values() {
+		if !IsContextSection(section) || len(values) == 0 {
+			continue
+		}
+		contexts = append(contexts, CredentialContext{
+			Name:       strings.TrimPrefix(section, ContextSectionPrefix),
+			Edgerc:     values["edgerc"],
+			Section:    values["section"],
+			AccountKey: values["account-key"],
+		})
+	}
+	sort.Slice(contexts, func(i, j int) bool {
+		return contexts[i].Name < contexts[j].Name
+	})
+	return contexts
+}
+
+// GetCredentialContext returns the context of given name
+func GetCredentialContext(cfg Config, name string) (CredentialContext, error) {
+	for _, c := range CredentialContexts(cfg) {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return CredentialContext{}, fmt.Errorf("%w: %s", ErrUnknownContext, name)
+}
+
+// CurrentCredentialContext returns the context selected with the cli.context value.
+// False is returned when no context is selected.
+func CurrentCredentialContext(cfg Config) (CredentialContext, bool, error) {
+	name, ok := cfg.GetValue("cli", "context")
+	if !ok || name == "" {
+		return CredentialContext{}, false, nil
+	}
+	c, err := GetCredentialContext(cfg, name)
+	if err != nil {
+		return CredentialContext{}, false, err
+	}
+	return c, true, nil
+}
+
+// CreateCredentialContext adds a context to the user config file. At least one of its values has to be given.
+func CreateCredentialContext(cfg Config, c CredentialContext) error {
+	if !profileNameRegexp.MatchString(c.Name) {
+		return fmt.Errorf("invalid context name %q, only letters, digits, '.', '-' and '_' are allowed", c.Name)
+	}
+	if _, err := GetCredentialContext(cfg, c.Name); err == nil {
+		return fmt.Errorf("%w: %s", ErrContextExists, c.Name)
+	}
+	if c.Edgerc == "" && c.Section == "" && c.AccountKey == "" {
+		return errors.New("at least one of edgerc, section or account key has to be provided")
+	}
+	section := ContextSection(c.Name)
+	for _, kv := range [][2]string{{"edgerc", c.Edgerc}, {"section", c.Section}, {"account-key", c.AccountKey}} {
+		if kv[1] != "" {
+			cfg.SetValue(section, kv[0], kv[1])
+		}
+	}
+	return nil
+}
+
+// DeleteCredentialContext removes a context from the user config file, and stops using it by default
+func DeleteCredentialContext(cfg Config, name string) error {
+	if _, err := GetCredentialContext(cfg, name); err != nil {
+		return err
+	}
+	section := ContextSection(name)
+	for _, key := range []string{"edgerc", "section", "account-key"} {
+		if origin, ok := cfg.Origin(section, key); ok && origin.Scope != ScopeUser {
+			return fmt.Errorf("context %s is defined in %s, remove it from that file instead", name, origin.Path)
+		}
+	}
+	for _, key := range []string{"edgerc", "section", "account-key"} {
+		cfg.UnsetValue(section, key)
+	}
+	if current, ok := cfg.GetValue("cli", "context"); ok && current == name {
+		cfg.UnsetValue("cli", "context")
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-ini/ini"
+)
+
+const (
+	// FormatINI is the format of the config file
+	FormatINI = "ini"
+	// FormatJSON is an object of sections, each being an object of keys and values
+	FormatJSON = "json"
+	// FormatEnv is a dotenv file of AKAMAI_<SECTION>_<KEY>=<value> lines
+	FormatEnv = "env"
+)
+
+// Formats lists the formats supported by Encode and Decode
+var Formats = []string{FormatINI, FormatJSON, FormatEnv}
+
+// FormatFromPath guesses the format of a file from its extension, defaulting to ini
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".env":
+		return FormatEnv
+	}
+	if strings.HasPrefix(filepath.Base(path), ".env") {
+		return FormatEnv
+	}
+	return FormatINI
+}
+
+// Encode writes config values in given format, with sections and keys sorted by name.
+// Package, context, account and profile sections are left out of the env format, as their variables are not exported to the environment.
+func Encode(values map[string]map[string]string, format string) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+		buf.WriteString("\n")
+	case FormatEnv:
+		for _, section := range sortedKeys(values) {
+			if IsPackageSection(section) || IsContextSection(section) || IsAccountSection(section) || isProfileSection(section) {
+				continue
+			}
+			for _, key := range sortedKeys(values[section]) {
+				_, _ = fmt.Fprintf(&buf, "%s=%s\n", envVarName(section, key), quoteEnvValue(values[section][key]))
+			}
+		}
+	case FormatINI:
+		file := ini.Empty()
+		for _, section := range sortedKeys(values) {
+			for _, key := range sortedKeys(values[section]) {
+				file.Section(section).Key(key).SetValue(values[section][key])
+			}
+		}
+		if _, err := file.WriteTo(&buf); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format %q, expected one of: %s", format, strings.Join(Formats, ", "))
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode reads config values written in given format
+func Decode(data []byte, format string) (map[string]map[string]string, error) {
+	switch format {
+	case FormatJSON:
+		var raw map[string]map[string]interface{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("invalid JSON config, expected an object of sections: %w", err)
+		}
+		values := make(map[string]map[string]string, len(raw))
+		for section, keys := range raw {
+			values[section] = make(map[string]string, len(keys))
+			for key, value := range keys {
+				switch v := value.(type) {
+				case string:
+					values[section][key] = v
+				case float64, bool:
+					values[section][key] = fmt.Sprint(v)
+				default:
+					return nil, fmt.Errorf("invalid JSON config, value of %s.%s is not a string", section, key)
+				}
+			}
+		}
+		return values, nil
+	case FormatEnv:
+		return decodeEnv(data)
+	case FormatINI:
+		file, err := ini.Load(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ini config: %w", err)
+		}
+		values := make(map[string]map[string]string)
+		for _, section := range file.Sections() {
+			if section.Name() == ini.DefaultSection && len(section.Keys()) == 0 {
+				continue
+			}
+			values[section.Name()] = make(map[string]string)
+			for _, key := range section.Keys() {
+				values[section.Name()][key.Name()] = key.String()
+			}
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unsupported config format %q, expected one of: %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// DiffValues lists values which differ between before and after, sorted by section and key
+func DiffValues(before, after map[string]map[string]string) []Change {
+	changes := make([]Change, 0)
+	for section, keys := range after {
+		for key, value := range keys {
+			old, ok := before[section][key]
+			if ok && old == value {
+				continue
+			}
+			changes = append(changes, Change{Section: section, Key: key, Old: old, New: value})
+		}
+	}
+	for section, keys := range before {
+		for key, value := range keys {
+			if _, ok := after[section][key]; !ok {
+				changes = append(changes, Change{Section: section, Key: key, Old: value})
+			}
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Section != changes[j].Section {
+			return changes[i].Section < changes[j].Section
+		}
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
+
+// decodeEnv reads AKAMAI_<SECTION>_<KEY>=<value> lines. Lines may start with 'export', and values may be quoted.
+// Known keys are matched by their variable name, other variables are split at the first underscore after AKAMAI_.
+func decodeEnv(data []byte) (map[string]map[string]string, error) {
+	values := make(map[string]map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimPrefix(text, "export ")
+		name, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid env config on line %d, expected NAME=value", line)
+		}
+		section, key, ok := parseEnvVarName(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("invalid env config on line %d, %s is not an AKAMAI_<SECTION>_<KEY> variable", line, name)
+		}
+		value, err := unquoteEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid env config on line %d: %w", line, err)
+		}
+		if values[section] == nil {
+			values[section] = make(map[string]string)
+		}
+		values[section][key] = value
+	}
+	return values, scanner.Err()
+}
+
+func parseEnvVarName(name string) (string, string, bool) {
+	for _, key := range schema {
+		if envVarName(key.Section, key.Name) == name {
+			return key.Section, key.Name, true
+		}
+	}
+	rest, ok := strings.CutPrefix(name, "AKAMAI_")
+	if !ok {
+		return "", "", false
+	}
+	section, key, ok := strings.Cut(rest, "_")
+	if !ok || section == "" || key == "" {
+		return "", "", false
+	}
+	return strings.ToLower(section), strings.ToLower(strings.ReplaceAll(key, "_", "-")), true
+}
+
+func quoteEnvValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\"'#$\\`") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+func unquoteEnvValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		return strconv.Unquote(value)
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") {
+			return "", fmt.Errorf("unterminated quoted value %s", value)
+		}
+		return value[1 : len(value)-1], nil
+	}
+	return value, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
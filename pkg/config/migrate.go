@@ -0,0 +1,230 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/log"
+	"github.com/akamai/cli/v2/pkg/tools"
+	"github.com/akamai/cli/v2/pkg/version"
+	"github.com/go-ini/ini"
+)
+
+type (
+	// Migration describes a single step upgrading the config from one version to the next
+	Migration struct {
+		From        string `json:"from" yaml:"from"`
+		To          string `json:"to" yaml:"to"`
+		Description string `json:"description" yaml:"description"`
+	}
+
+	// Change is a config value modified by a migration
+	Change struct {
+		Section string `json:"section" yaml:"section"`
+		Key     string `json:"key" yaml:"key"`
+		Old     string `json:"old,omitempty" yaml:"old,omitempty"`
+		New     string `json:"new,omitempty" yaml:"new,omitempty"`
+	}
+
+	// MigrationResult lists the migrations applied to the config, or pending ones on a dry run
+	MigrationResult struct {
+		Version      string      `json:"version" yaml:"version"`
+		Migrations   []Migration `json:"migrations" yaml:"migrations"`
+		Changes      []Change    `json:"changes" yaml:"changes"`
+		RemovedFiles []string    `json:"removed-files,omitempty" yaml:"removed-files,omitempty"`
+		Backup       string      `json:"backup,omitempty" yaml:"backup,omitempty"`
+	}
+
+	migration struct {
+		Migration
+		// apply modifies the config file in memory and returns files to remove once the config is saved
+		apply func(cfg *ini.File, cliPath string) ([]string, error)
+	}
+)
+
+// ErrUnsupportedConfigVersion is returned when the config was written by a newer version of the CLI
+var ErrUnsupportedConfigVersion = errors.New("unsupported config version")
+
+// migrations are applied in order, starting from the one matching the current config version
+var migrations = []migration{
+	{
+		Migration: Migration{From: "", To: "1", Description: "Import the last upgrade check from the .upgrade-check or .update-check file"},
+		apply:     importUpgradeCheck,
+	},
+	{
+		Migration: Migration{From: "1", To: "1.1", Description: "Update the config version, no values change"},
+		apply: func(_ *ini.File, _ string) ([]string, error) {
+			return nil, nil
+		},
+	},
+}
+
+// configVersion is the latest config version known to this CLI
+var configVersion = migrations[len(migrations)-1].To
+
+// Migrate upgrades the user config file to the latest version, saving a timestamped backup of the file first.
+// On a dry run, the pending migrations and changes are returned without modifying anything.
+func (c *IniConfig) Migrate(ctx context.Context, dryRun bool) (*MigrationResult, error) {
+	logger := log.FromContext(ctx)
+	var currentVersion string
+	if s, err := c.file.GetSection("cli"); err == nil && s.HasKey("config-version") {
+		currentVersion = s.Key("config-version").String()
+	}
+	pending, err := pendingMigrations(currentVersion)
+	if err != nil {
+		return nil, err
+	}
+	result := &MigrationResult{Version: currentVersion, Migrations: []Migration{}, Changes: []Change{}}
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return nil, err
+	}
+	file, err := copyIniFile(c.file)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range pending {
+		logger.Debug(fmt.Sprintf("Migrating config from version %q to %q: %s", m.From, m.To, m.Description))
+		removed, err := m.apply(file, cliPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to migrate config to version %s: %w", m.To, err)
+		}
+		file.Section("cli").Key("config-version").SetValue(m.To)
+		result.Migrations = append(result.Migrations, m.Migration)
+		result.RemovedFiles = append(result.RemovedFiles, removed...)
+	}
+	result.Version = configVersion
+	result.Changes = diffIniFiles(c.file, file)
+	if dryRun {
+		return result, nil
+	}
+
+	if _, err := os.Stat(c.path); err == nil {
+		result.Backup = fmt.Sprintf("%s.%s.bak", c.path, time.Now().Format("20060102150405"))
+		logger.Debug(fmt.Sprintf("Backing up config to %s", result.Backup))
+		data, err := os.ReadFile(c.path)
+		if err != nil {
+			return nil, err
+		}
+		if err := tools.WriteFileAtomic(result.Backup, data, 0600); err != nil {
+			return nil, fmt.Errorf("unable to back up config: %w", err)
+		}
+	}
+
+	c.file = file
+	for _, l := range c.layers {
+		if l.scope == ScopeUser {
+			l.file = file
+		}
+	}
+	if err := c.Save(ctx); err != nil {
+		return nil, err
+	}
+	for _, name := range result.RemovedFiles {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// pendingMigrations returns the migrations needed to upgrade the config from the given version
+func pendingMigrations(currentVersion string) ([]migration, error) {
+	for i, m := range migrations {
+		if m.From == currentVersion {
+			return migrations[i:], nil
+		}
+	}
+	if currentVersion == configVersion {
+		return nil, nil
+	}
+	if version.Compare(configVersion, currentVersion) == version.Smaller {
+		return nil, fmt.Errorf("%w: config version %s is newer than %s supported by Akamai CLI %s, upgrade Akamai CLI or restore a backup of the config file",
+			ErrUnsupportedConfigVersion, currentVersion, configVersion, version.Version)
+	}
+	return nil, fmt.Errorf("%w: unknown config version %s", ErrUnsupportedConfigVersion, currentVersion)
+}
+
+func importUpgradeCheck(cfg *ini.File, cliPath string) ([]string, error) {
+	var data []byte
+	var upgradeFile string
+	for _, name := range []string{".upgrade-check", ".update-check"} {
+		path := filepath.Join(cliPath, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		data, upgradeFile = content, path
+		break
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	date := string(data)
+	if date == "never" || date == "ignore" {
+		cfg.Section("cli").Key("last-upgrade-check").SetValue(date)
+	} else {
+		if m := strings.LastIndex(date, "m="); m != -1 {
+			date = date[0 : m-1]
+		}
+		lastUpgrade, err := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", date)
+		if err == nil {
+			cfg.Section("cli").Key("last-upgrade-check").SetValue(lastUpgrade.Format(time.RFC3339))
+		}
+	}
+	return []string{upgradeFile}, nil
+}
+
+func copyIniFile(file *ini.File) (*ini.File, error) {
+	var buf bytes.Buffer
+	if _, err := file.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return ini.Load(buf.Bytes())
+}
+
+// diffIniFiles lists values which differ between the files, sorted by section and key
+func diffIniFiles(before, after *ini.File) []Change {
+	changes := make([]Change, 0)
+	for _, section := range after.Sections() {
+		for _, key := range section.Keys() {
+			old := ""
+			if s, err := before.GetSection(section.Name()); err == nil && s.HasKey(key.Name()) {
+				old = s.Key(key.Name()).String()
+				if old == key.String() {
+					continue
+				}
+			}
+			changes = append(changes, Change{Section: section.Name(), Key: key.Name(), Old: old, New: key.String()})
+		}
+	}
+	for _, section := range before.Sections() {
+		for _, key := range section.Keys() {
+			if s, err := after.GetSection(section.Name()); err != nil || !s.HasKey(key.Name()) {
+				changes = append(changes, Change{Section: section.Name(), Key: key.Name(), Old: key.String()})
+			}
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Section != changes[j].Section {
+			return changes[i].Section < changes[j].Section
+		}
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
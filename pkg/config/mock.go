@@ -17,6 +17,12 @@ func (m *Mock) Save(_ context.Context) error {
 	return args.Error(0)
 }
 
+// Reload mock
+func (m *Mock) Reload() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
 // Values mock
 func (m *Mock) Values() map[string]map[string]string {
 	args := m.Called()
@@ -26,6 +32,15 @@ func (m *Mock) Values() map[string]map[string]string {
 	return args.Get(0).(map[string]map[string]string)
 }
 
+// UserValues mock
+func (m *Mock) UserValues() map[string]map[string]string {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(map[string]map[string]string)
+}
+
 // GetValue mock
 func (m *Mock) GetValue(section string, key string) (string, bool) {
 	args := m.Called(section, key)
@@ -42,8 +57,62 @@ func (m *Mock) UnsetValue(section string, key string) {
 	m.Called(section, key)
 }
 
+// SetScopedValue mock
+func (m *Mock) SetScopedValue(scope Scope, section string, key string, value string) error {
+	args := m.Called(scope, section, key, value)
+	return args.Error(0)
+}
+
+// Origin mock
+func (m *Mock) Origin(section string, key string) (Origin, bool) {
+	args := m.Called(section, key)
+	return args.Get(0).(Origin), args.Bool(1)
+}
+
+// Migrate mock
+func (m *Mock) Migrate(_ context.Context, dryRun bool) (*MigrationResult, error) {
+	args := m.Called(dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*MigrationResult), args.Error(1)
+}
+
 // ExportEnv mock
 func (m *Mock) ExportEnv(_ context.Context) error {
 	args := m.Called()
 	return args.Error(0)
 }
+
+// Profiles mock
+func (m *Mock) Profiles() []string {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).([]string)
+}
+
+// Profile mock
+func (m *Mock) Profile() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+// UseProfile mock
+func (m *Mock) UseProfile(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+// CreateProfile mock
+func (m *Mock) CreateProfile(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
+// DeleteProfile mock
+func (m *Mock) DeleteProfile(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
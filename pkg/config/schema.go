@@ -0,0 +1,262 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// KeyType describes the format of a config value
+	KeyType string
+
+	// Key describes a config key known to the CLI
+	Key struct {
+		Section  string   `json:"section" yaml:"section"`
+		Name     string   `json:"name" yaml:"name"`
+		Type     KeyType  `json:"type" yaml:"type"`
+		Default  string   `json:"default" yaml:"default"`
+		Allowed  []string `json:"allowed,omitempty" yaml:"allowed,omitempty"`
+		ReadOnly bool     `json:"read-only,omitempty" yaml:"read-only,omitempty"`
+		// Local keys hold values which only apply to the machine they are set on, they are not exported
+		Local       bool   `json:"local,omitempty" yaml:"local,omitempty"`
+		Description string `json:"description" yaml:"description"`
+	}
+)
+
+const (
+	// TypeString accepts any value, unless allowed values are listed
+	TypeString KeyType = "string"
+	// TypePath is a file system path
+	TypePath KeyType = "path"
+	// TypeList is a comma separated list of values
+	TypeList KeyType = "list"
+	// TypeDuration is a Go duration, such as 30s or 5m
+	TypeDuration KeyType = "duration"
+	// TypeTimestamp is an RFC 3339 timestamp
+	TypeTimestamp KeyType = "timestamp"
+)
+
+var (
+	// ErrUnknownKey is returned when a key is not part of the schema of its section
+	ErrUnknownKey = errors.New("unknown config key")
+	// ErrInvalidValue is returned when a value does not match the schema of its key
+	ErrInvalidValue = errors.New("invalid config value")
+)
+
+// schema lists the keys of sections owned by the CLI. Sections not listed here, such as those of packages, accept any key.
+var schema = []Key{
+	{
+		Section:     "cli",
+		Name:        "cache-path",
+		Type:        TypePath,
+		Local:       true,
+		Description: "Directory where the CLI caches downloaded data, such as package registries. Defaults to the cache directory in the CLI root directory.",
+	},
+	{
+		Section:     "cli",
+		Name:        "config-version",
+		Type:        TypeString,
+		Default:     configVersion,
+		ReadOnly:    true,
+		Description: "Version of the config file format. It is updated by 'akamai config migrate'.",
+	},
+	{
+		Section:     "cli",
+		Name:        "context",
+		Type:        TypeString,
+		Description: "Credential context whose edgerc, section and account switch key are used when the matching global flags are not given. It is set by 'akamai context use'.",
+	},
+	{
+		Section:     "cli",
+		Name:        "credential-broker",
+		Type:        TypeString,
+		Default:     "off",
+		Allowed:     []string{"on", "off"},
+		Description: "Set to 'on' to serve the credentials of the selected section to package commands over a local socket, given in the AKAMAI_CLI_CREDENTIAL_BROKER environment variable of the command.",
+	},
+	{
+		Section:     "cli",
+		Name:        "install-in-path",
+		Type:        TypeString,
+		Allowed:     []string{"", "no"},
+		Description: "Set to 'no' to stop asking whether to install the CLI in a directory on PATH.",
+	},
+	{
+		Section:     "cli",
+		Name:        "last-upgrade-check",
+		Type:        TypeTimestamp,
+		Allowed:     []string{"never", "ignore"},
+		Description: "Time of the last check for a new CLI version. Set to 'ignore' to disable upgrade checks, or to 'never' to check on the next run.",
+	},
+	{
+		Section:     "cli",
+		Name:        "lock-timeout",
+		Type:        TypeDuration,
+		Default:     "5m",
+		Description: "How long to wait for another akamai process to release the lock on the CLI root directory.",
+	},
+	{
+		Section:     "cli",
+		Name:        "profile",
+		Type:        TypeString,
+		Description: "Profile used when neither the --profile flag nor the AKAMAI_CLI_PROFILE environment variable is given. It is set by 'akamai profile use'.",
+	},
+	{
+		Section:     "cli",
+		Name:        "proxy",
+		Type:        TypeString,
+		Description: "Proxy used when the --proxy flag is not given, such as http://proxy.example.com:3128.",
+	},
+	{
+		Section:     "cli",
+		Name:        "registries",
+		Type:        TypeList,
+		Description: "Additional package registries searched before the built-in one, as URLs or local file paths.",
+	},
+}
+
+// Keys returns the known config keys, sorted by section and name
+func Keys() []Key {
+	keys := slices.Clone(schema)
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Section != keys[j].Section {
+			return keys[i].Section < keys[j].Section
+		}
+		return keys[i].Name < keys[j].Name
+	})
+	return keys
+}
+
+// LookupKey returns the schema of given key
+func LookupKey(section, name string) (Key, bool) {
+	for _, key := range schema {
+		if key.Section == section && key.Name == name {
+			return key, true
+		}
+	}
+	return Key{}, false
+}
+
+// IsLocal reports whether the value of given key only applies to the machine it is set on
+func IsLocal(section, name string) bool {
+	key, _ := LookupKey(section, name)
+	return key.Local
+}
+
+// DefaultValue returns the default value of a known key, or an empty string
+func DefaultValue(section, name string) string {
+	key, _ := LookupKey(section, name)
+	return key.Default
+}
+
+// Validate checks whether value can be set for given key.
+// Keys of sections which are not part of the schema are not validated.
+func Validate(section, name, value string) error {
+	key, ok := LookupKey(section, name)
+	if !ok {
+		if !isKnownSection(section) {
+			return nil
+		}
+		if suggestion := suggestKey(section, name); suggestion != "" {
+			return fmt.Errorf("%w %s.%s, did you mean %s.%s?", ErrUnknownKey, section, name, section, suggestion)
+		}
+		return fmt.Errorf("%w %s.%s", ErrUnknownKey, section, name)
+	}
+	if key.ReadOnly {
+		return fmt.Errorf("%s.%s is managed by Akamai CLI and cannot be set", section, name)
+	}
+	return key.Validate(value)
+}
+
+// Validate checks whether value matches the type and allowed values of the key
+func (k Key) Validate(value string) error {
+	if slices.Contains(k.Allowed, value) {
+		return nil
+	}
+
+	var err error
+	switch k.Type {
+	case TypeString:
+		if len(k.Allowed) > 0 {
+			err = fmt.Errorf("allowed values are: %s", quoteValues(k.Allowed))
+		}
+	case TypeDuration:
+		if _, parseErr := time.ParseDuration(value); parseErr != nil {
+			err = fmt.Errorf("expected a duration, such as 30s or 5m")
+		}
+	case TypeTimestamp:
+		if _, parseErr := time.Parse(time.RFC3339, value); parseErr != nil {
+			err = errors.New("expected an RFC 3339 timestamp, such as 2021-02-03T16:46:43Z")
+			if len(k.Allowed) > 0 {
+				err = fmt.Errorf("%w, or one of: %s", err, quoteValues(k.Allowed))
+			}
+		}
+	case TypeList:
+		for _, item := range strings.Split(value, ",") {
+			if strings.TrimSpace(item) == "" && value != "" {
+				err = errors.New("expected a comma separated list without empty items")
+				break
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%w for %s.%s: %q, %s", ErrInvalidValue, k.Section, k.Name, value, err)
+	}
+	return nil
+}
+
+func isKnownSection(section string) bool {
+	for _, key := range schema {
+		if key.Section == section {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestKey returns the known key of the section closest to name, if it is likely a typo
+func suggestKey(section, name string) string {
+	var suggestion string
+	best := 3
+	for _, key := range schema {
+		if key.Section != section {
+			continue
+		}
+		if d := editDistance(name, key.Name); d <= best {
+			suggestion, best = key.Name, d
+		}
+	}
+	return suggestion
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func quoteValues(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", v))
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,129 @@
+// Package edgegrid signs HTTP requests to Akamai APIs with the EdgeGrid authentication scheme
+package edgegrid
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/edgerc"
+)
+
+const (
+	// DefaultMaxBody is the number of body bytes included in the signature when the credentials do not set max_body
+	DefaultMaxBody = 131072
+
+	authPrefix      = "EG1-HMAC-SHA256"
+	timestampFormat = "20060102T15:04:05-0700"
+)
+
+// Signer adds the EdgeGrid Authorization header to requests
+type Signer struct {
+	credentials edgerc.Credentials
+	maxBody     int
+
+	// now and nonce are replaced in tests to produce stable signatures
+	now   func() time.Time
+	nonce func() (string, error)
+}
+
+// NewSigner creates a Signer for given credentials
+func NewSigner(creds edgerc.Credentials) (*Signer, error) {
+	for _, kv := range [][2]string{
+		{"client_secret", creds.ClientSecret},
+		{"host", creds.Host},
+		{"access_token", creds.AccessToken},
+		{"client_token", creds.ClientToken},
+	} {
+		if kv[1] == "" {
+			return nil, fmt.Errorf("%s is required to sign requests", kv[0])
+		}
+	}
+	maxBody := DefaultMaxBody
+	if creds.MaxBody != "" {
+		var err error
+		if maxBody, err = strconv.Atoi(creds.MaxBody); err != nil || maxBody <= 0 {
+			return nil, fmt.Errorf("invalid max_body %q, expected a positive number of bytes", creds.MaxBody)
+		}
+	}
+	return &Signer{credentials: creds, maxBody: maxBody, now: time.Now, nonce: newNonce}, nil
+}
+
+// Sign sets the Authorization header of the request. The body, if any, is read and restored so that the request can be sent.
+func (s *Signer) Sign(req *http.Request) error {
+	nonce, err := s.nonce()
+	if err != nil {
+		return err
+	}
+	contentHash, err := s.contentHash(req)
+	if err != nil {
+		return err
+	}
+
+	timestamp := s.now().UTC().Format(timestampFormat)
+	authHeader := fmt.Sprintf("%s client_token=%s;access_token=%s;timestamp=%s;nonce=%s;",
+		authPrefix, s.credentials.ClientToken, s.credentials.AccessToken, timestamp, nonce)
+	dataToSign := strings.Join([]string{
+		strings.ToUpper(req.Method),
+		strings.ToLower(req.URL.Scheme),
+		req.URL.Host,
+		req.URL.RequestURI(),
+		"",
+		contentHash,
+		authHeader,
+	}, "\t")
+
+	signingKey := sign(timestamp, []byte(s.credentials.ClientSecret))
+	signature := sign(dataToSign, []byte(signingKey))
+	req.Header.Set("Authorization", authHeader+"signature="+signature)
+	return nil
+}
+
+// contentHash returns the hash of the first maxBody bytes of a POST body, other requests are signed without it
+func (s *Signer) contentHash(req *http.Request) (string, error) {
+	if req.Method != http.MethodPost || req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return "", fmt.Errorf("unable to read request body: %w", err)
+	}
+	if err := req.Body.Close(); err != nil {
+		return "", err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) == 0 {
+		return "", nil
+	}
+	if len(body) > s.maxBody {
+		body = body[:s.maxBody]
+	}
+	sum := sha256.Sum256(body)
+	return base64.StdEncoding.EncodeToString(sum[:]), nil
+}
+
+func sign(data string, key []byte) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// newNonce returns a random version 4 UUID
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.New("unable to generate request nonce")
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,77 @@
+package edgegrid
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akamai/cli/v2/pkg/edgerc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	creds := edgerc.Credentials{
+		Host:         "akaa-baseurl-xxxxxxxxxxx-xxxxxxxxxxxxx.luna.akamaiapis.net",
+		ClientToken:  "akab-client-token-xxx-xxxxxxxxxxxxxxxx",
+		AccessToken:  "akab-access-token-xxx-xxxxxxxxxxxxxxxx",
+		ClientSecret: "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx=",
+	}
+	tests := map[string]struct {
+		method    string
+		path      string
+		body      string
+		signature string
+	}{
+		"simple GET": {
+			method:    http.MethodGet,
+			path:      "/",
+			signature: "tL+y4hxyHxgWVD30X3pWnGKHcPzmrIF+LThiAOhMxYU=",
+		},
+		"GET with query": {
+			method:    http.MethodGet,
+			path:      "/testapi/v1/t1?p1=1&p2=2",
+			signature: "hKDH1UlnQySSHjvIcZpDMbQHihTQ0XyVAKZaApabdeA=",
+		},
+		"POST with body": {
+			method:    http.MethodPost,
+			path:      "/testapi/v1/t3",
+			body:      "datadatadatadatadatadatadatadata",
+			signature: "hXm4iCxtpN22m4cbZb4lVLW5rhX8Ca82vCFqXzSTPe4=",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			signer, err := NewSigner(creds)
+			require.NoError(t, err)
+			signer.now = func() time.Time { return time.Date(2014, 3, 21, 19, 34, 21, 0, time.UTC) }
+			signer.nonce = func() (string, error) { return "nonce-xx-xxxx-xxxx-xxxx-xxxxxxxxxxxx", nil }
+
+			req, err := http.NewRequest(test.method, "https://"+creds.Host+test.path, strings.NewReader(test.body))
+			require.NoError(t, err)
+			require.NoError(t, signer.Sign(req))
+
+			assert.Equal(t, "EG1-HMAC-SHA256 client_token=akab-client-token-xxx-xxxxxxxxxxxxxxxx;access_token=akab-access-token-xxx-xxxxxxxxxxxxxxxx;"+
+				"timestamp=20140321T19:34:21+0000;nonce=nonce-xx-xxxx-xxxx-xxxx-xxxxxxxxxxxx;signature="+test.signature, req.Header.Get("Authorization"))
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, test.body, string(body), "body is restored after signing")
+		})
+	}
+}
+
+func TestNewSigner(t *testing.T) {
+	_, err := NewSigner(edgerc.Credentials{Host: "akab-host.luna.akamaiapis.net"})
+	assert.EqualError(t, err, "client_secret is required to sign requests")
+
+	_, err = NewSigner(edgerc.Credentials{Host: "h", ClientToken: "c", AccessToken: "a", ClientSecret: "s", MaxBody: "lots"})
+	assert.EqualError(t, err, `invalid max_body "lots", expected a positive number of bytes`)
+
+	signer, err := NewSigner(edgerc.Credentials{Host: "h", ClientToken: "c", AccessToken: "a", ClientSecret: "s"})
+	require.NoError(t, err)
+	nonce, err := signer.nonce()
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, nonce)
+}
@@ -0,0 +1,231 @@
+// Package edgerc reads and writes the .edgerc file holding EdgeGrid API credentials
+package edgerc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/akamai/cli/v2/pkg/tools"
+	"github.com/akamai/cli/v2/pkg/vault"
+	"github.com/go-ini/ini"
+	"github.com/mitchellh/go-homedir"
+)
+
+type (
+	// File is an ini formatted credentials file, with a section per set of credentials
+	File struct {
+		path string
+		file *ini.File
+	}
+
+	// Credentials is a section of the credentials file
+	Credentials struct {
+		Host         string `ini:"host" json:"host" yaml:"host"`
+		ClientToken  string `ini:"client_token" json:"client-token" yaml:"client-token"`
+		ClientSecret string `ini:"client_secret" json:"client-secret" yaml:"client-secret"`
+		AccessToken  string `ini:"access_token" json:"access-token" yaml:"access-token"`
+		AccountKey   string `ini:"account_key" json:"account-key,omitempty" yaml:"account-key,omitempty"`
+		MaxBody      string `ini:"max_body" json:"max-body,omitempty" yaml:"max-body,omitempty"`
+	}
+)
+
+// DefaultSection is used when no section is given
+const DefaultSection = "default"
+
+var (
+	// ErrSectionNotFound is returned when the credentials file does not contain a section
+	ErrSectionNotFound = errors.New("section not found")
+
+	hostRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*$`)
+)
+
+// DefaultPath returns the location of the credentials file in the home directory
+func DefaultPath() (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".edgerc"), nil
+}
+
+// Load reads the credentials file. A missing file is treated as an empty one, so that sections can be added to it.
+func Load(path string) (*File, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &File{path: path, file: ini.Empty()}, nil
+	}
+	file, err := ini.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read credentials file %s: %w", path, err)
+	}
+	return &File{path: path, file: file}, nil
+}
+
+// Path returns the location of the credentials file
+func (f *File) Path() string {
+	return f.path
+}
+
+// Sections returns the names of the sections in the file, sorted by name
+func (f *File) Sections() []string {
+	names := make([]string, 0)
+	for _, section := range f.file.Sections() {
+		if section.Name() == ini.DefaultSection && len(section.Keys()) == 0 {
+			continue
+		}
+		names = append(names, section.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Credentials returns the credentials of given section
+func (f *File) Credentials(section string) (Credentials, error) {
+	s, err := f.file.GetSection(section)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("%w: %s in %s", ErrSectionNotFound, section, f.path)
+	}
+	var c Credentials
+	if err := s.MapTo(&c); err != nil {
+		return Credentials{}, fmt.Errorf("unable to read section %s: %w", section, err)
+	}
+	return c, nil
+}
+
+// SetCredentials replaces the section with given credentials. Empty optional values are left out.
+func (f *File) SetCredentials(section string, c Credentials) {
+	f.file.DeleteSection(section)
+	s := f.file.Section(section)
+	for _, kv := range [][2]string{
+		{"client_secret", c.ClientSecret},
+		{"host", c.Host},
+		{"access_token", c.AccessToken},
+		{"client_token", c.ClientToken},
+		{"account_key", c.AccountKey},
+		{"max_body", c.MaxBody},
+	} {
+		if kv[1] != "" {
+			s.Key(kv[0]).SetValue(kv[1])
+		}
+	}
+}
+
+// RemoveSection deletes a section from the file
+func (f *File) RemoveSection(section string) error {
+	if _, err := f.file.GetSection(section); err != nil {
+		return fmt.Errorf("%w: %s in %s", ErrSectionNotFound, section, f.path)
+	}
+	f.file.DeleteSection(section)
+	return nil
+}
+
+// Save writes the file atomically, readable by the owner only
+func (f *File) Save() error {
+	var buf bytes.Buffer
+	if _, err := f.file.WriteTo(&buf); err != nil {
+		return err
+	}
+	perm := os.FileMode(0600)
+	if info, err := os.Stat(f.path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return err
+	}
+	return tools.WriteFileAtomic(f.path, buf.Bytes(), perm)
+}
+
+// ResolveSecrets returns the credentials with values referencing a secret, such as secret://prod/client_secret,
+// replaced by the resolved secret
+func (c Credentials) ResolveSecrets(resolve func(string) (string, error)) (Credentials, error) {
+	for _, kv := range []struct {
+		name  string
+		value *string
+	}{
+		{"host", &c.Host},
+		{"client_token", &c.ClientToken},
+		{"client_secret", &c.ClientSecret},
+		{"access_token", &c.AccessToken},
+		{"account_key", &c.AccountKey},
+		{"max_body", &c.MaxBody},
+	} {
+		if !vault.IsRef(*kv.value) {
+			continue
+		}
+		resolved, err := resolve(*kv.value)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("unable to resolve %s: %w", kv.name, err)
+		}
+		*kv.value = resolved
+	}
+	return c, nil
+}
+
+// Validate returns the problems found in the credentials: missing required values and malformed host or tokens.
+// Values referencing a secret are only checked for presence, as the secret is not read.
+func (c Credentials) Validate() []error {
+	var problems []error
+	for _, kv := range [][2]string{
+		{"client_secret", c.ClientSecret},
+		{"host", c.Host},
+		{"access_token", c.AccessToken},
+		{"client_token", c.ClientToken},
+	} {
+		if strings.TrimSpace(kv[1]) == "" {
+			problems = append(problems, fmt.Errorf("%s is required", kv[0]))
+		}
+	}
+
+	if c.Host != "" && !vault.IsRef(c.Host) {
+		if err := validateHost(c.Host); err != nil {
+			problems = append(problems, err)
+		}
+	}
+	for _, kv := range [][2]string{{"access_token", c.AccessToken}, {"client_token", c.ClientToken}} {
+		if kv[1] != "" && !vault.IsRef(kv[1]) && !strings.HasPrefix(kv[1], "akab-") {
+			problems = append(problems, fmt.Errorf("%s should start with akab-", kv[0]))
+		}
+	}
+	return problems
+}
+
+func validateHost(host string) error {
+	if strings.Contains(host, "://") {
+		return fmt.Errorf("host %q should not include the scheme, use the host name only, such as akab-xxx.luna.akamaiapis.net", host)
+	}
+	name := strings.TrimSuffix(host, "/")
+	if strings.ContainsAny(name, "/:?#") {
+		return fmt.Errorf("host %q should not include a path or port, use the host name only, such as akab-xxx.luna.akamaiapis.net", host)
+	}
+	if !hostRegexp.MatchString(name) {
+		return fmt.Errorf("host %q is not a valid host name", host)
+	}
+	if !strings.HasSuffix(name, ".akamaiapis.net") {
+		return fmt.Errorf("host %q should be an Akamai API host ending with .akamaiapis.net", host)
+	}
+	return nil
+}
+
+// Redacted returns the credentials with secret and tokens hidden, so they can be shown
+func (c Credentials) Redacted() Credentials {
+	c.ClientSecret = Redact(c.ClientSecret, 0)
+	c.ClientToken = Redact(c.ClientToken, 4)
+	c.AccessToken = Redact(c.AccessToken, 4)
+	return c
+}
+
+// Redact hides a secret value, keeping its last visible characters if the value is long enough to stay secret
+func Redact(value string, visible int) string {
+	if value == "" {
+		return ""
+	}
+	if len(value) < 16 {
+		visible = 0
+	}
+	return strings.Repeat("*", 8) + value[len(value)-visible:]
+}
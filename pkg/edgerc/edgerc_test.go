@@ -0,0 +1,160 @@
+package edgerc
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile(t *testing.T) {
+	file, err := Load(filepath.Join("testdata", ".edgerc"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"broken", "default"}, file.Sections())
+
+	creds, err := file.Credentials("default")
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{
+		Host:         "akab-h05tnam3wl42son7-nktm4ycinn3urmz4.luna.akamaiapis.net",
+		ClientToken:  "akab-c113ntt0k3n4qtari252bfxxbsl-yvsdj",
+		ClientSecret: "C113nt53KR3TN6N90yVuAgICxIRwsObLi0E67/N8eRN=",
+		AccessToken:  "akab-acc35t0k3nodujqunph3w7hzp7-gtm6ij",
+	}, creds)
+	_, err = file.Credentials("missing")
+	assert.ErrorIs(t, err, ErrSectionNotFound)
+
+	path := filepath.Join(t.TempDir(), "dir", ".edgerc")
+	file.path = path
+	file.SetCredentials("new", Credentials{Host: "akab-new.luna.akamaiapis.net", ClientToken: "akab-a", ClientSecret: "s", AccessToken: "akab-b", AccountKey: "1-ABC"})
+	require.NoError(t, file.RemoveSection("broken"))
+	assert.ErrorIs(t, file.RemoveSection("broken"), ErrSectionNotFound)
+	require.NoError(t, file.Save())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	if os.PathSeparator == '/' {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+	saved, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "new"}, saved.Sections())
+	creds, err = saved.Credentials("new")
+	require.NoError(t, err)
+	assert.Equal(t, "1-ABC", creds.AccountKey)
+
+	empty, err := Load(filepath.Join(t.TempDir(), ".edgerc"))
+	require.NoError(t, err)
+	assert.Empty(t, empty.Sections())
+}
+
+func TestValidate(t *testing.T) {
+	valid := Credentials{
+		Host:         "akab-h05tnam3wl42son7-nktm4ycinn3urmz4.luna.akamaiapis.net",
+		ClientToken:  "akab-c113ntt0k3n4qtari252bfxxbsl-yvsdj",
+		ClientSecret: "C113nt53KR3TN6N90yVuAgICxIRwsObLi0E67/N8eRN=",
+		AccessToken:  "akab-acc35t0k3nodujqunph3w7hzp7-gtm6ij",
+	}
+	tests := map[string]struct {
+		modify   func(*Credentials)
+		expected []string
+	}{
+		"valid": {
+			modify: func(_ *Credentials) {},
+		},
+		"trailing slash in host": {
+			modify: func(c *Credentials) { c.Host += "/" },
+		},
+		"missing values": {
+			modify:   func(c *Credentials) { c.ClientSecret, c.AccessToken = "", " " },
+			expected: []string{"client_secret is required", "access_token is required", "access_token should start with akab-"},
+		},
+		"host with scheme": {
+			modify:   func(c *Credentials) { c.Host = "https://" + c.Host },
+			expected: []string{`host "https://akab-h05tnam3wl42son7-nktm4ycinn3urmz4.luna.akamaiapis.net" should not include the scheme, use the host name only, such as akab-xxx.luna.akamaiapis.net`},
+		},
+		"host with path": {
+			modify:   func(c *Credentials) { c.Host = "akab-xxx.luna.akamaiapis.net/papi/v1" },
+			expected: []string{`host "akab-xxx.luna.akamaiapis.net/papi/v1" should not include a path or port, use the host name only, such as akab-xxx.luna.akamaiapis.net`},
+		},
+		"invalid host name": {
+			modify:   func(c *Credentials) { c.Host = "akab_xxx luna" },
+			expected: []string{`host "akab_xxx luna" is not a valid host name`},
+		},
+		"not an Akamai host": {
+			modify:   func(c *Credentials) { c.Host = "example.com" },
+			expected: []string{`host "example.com" should be an Akamai API host ending with .akamaiapis.net`},
+		},
+		"secret references": {
+			modify: func(c *Credentials) {
+				c.Host, c.ClientToken, c.AccessToken = "secret://prod/host", "secret://prod/client_token", "secret://prod/access_token"
+			},
+		},
+		"malformed token": {
+			modify:   func(c *Credentials) { c.ClientToken = "c113ntt0k3n" },
+			expected: []string{"client_token should start with akab-"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			creds := valid
+			test.modify(&creds)
+			var problems []string
+			for _, problem := range creds.Validate() {
+				problems = append(problems, problem.Error())
+			}
+			assert.Equal(t, test.expected, problems)
+		})
+	}
+}
+
+func TestResolveSecrets(t *testing.T) {
+	creds := Credentials{
+		Host:         "akab-xxx.luna.akamaiapis.net",
+		ClientToken:  "akab-c113ntt0k3n4qtari252bfxxbsl-yvsdj",
+		ClientSecret: "secret://prod/client_secret",
+		AccessToken:  "secret://prod/access_token",
+	}
+	secrets := map[string]string{
+		"secret://prod/client_secret": "C113nt53KR3TN6N90yVuAgICxIRwsObLi0E67/N8eRN=",
+		"secret://prod/access_token":  "akab-acc35t0k3nodujqunph3w7hzp7-gtm6ij",
+	}
+	resolve := func(ref string) (string, error) {
+		if secret, ok := secrets[ref]; ok {
+			return secret, nil
+		}
+		return "", errors.New("secret not found")
+	}
+
+	resolved, err := creds.ResolveSecrets(resolve)
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{
+		Host:         "akab-xxx.luna.akamaiapis.net",
+		ClientToken:  "akab-c113ntt0k3n4qtari252bfxxbsl-yvsdj",
+		ClientSecret: "C113nt53KR3TN6N90yVuAgICxIRwsObLi0E67/N8eRN=",
+		AccessToken:  "akab-acc35t0k3nodujqunph3w7hzp7-gtm6ij",
+	}, resolved)
+
+	creds.AccountKey = "secret://prod/account_key"
+	_, err = creds.ResolveSecrets(resolve)
+	assert.EqualError(t, err, "unable to resolve account_key: secret not found")
+}
+
+func TestRedacted(t *testing.T) {
+	creds := Credentials{
+		Host:         "akab-xxx.luna.akamaiapis.net",
+		ClientToken:  "akab-c113ntt0k3n4qtari252bfxxbsl-yvsdj",
+		ClientSecret: "C113nt53KR3TN6N90yVuAgICxIRwsObLi0E67/N8eRN=",
+		AccessToken:  "short",
+		AccountKey:   "1-ABC",
+	}
+	assert.Equal(t, Credentials{
+		Host:         "akab-xxx.luna.akamaiapis.net",
+		ClientToken:  "********vsdj",
+		ClientSecret: "********",
+		AccessToken:  "********",
+		AccountKey:   "1-ABC",
+	}, creds.Redacted())
+}
@@ -17,6 +17,11 @@ type (
 	}
 )
 
+// New mock returns the same mock, which can be shared between goroutines
+func (m *MockRepo) New() Repository {
+	return m
+}
+
 // Open mock
 func (m *MockRepo) Open(path string) error {
 	args := m.Called(path)
@@ -67,3 +72,36 @@ func (m *MockRepo) Reset(opts *git.ResetOptions) error {
 	args := m.Called(opts)
 	return args.Error(0)
 }
+
+// Checkout mock
+func (m *MockRepo) Checkout(opts *git.CheckoutOptions) error {
+	args := m.Called(opts)
+	return args.Error(0)
+}
+
+// RemoteURL mock
+func (m *MockRepo) RemoteURL(name string) (string, error) {
+	args := m.Called(name)
+	return args.String(0), args.Error(1)
+}
+
+// Fetch mock
+func (m *MockRepo) Fetch(_ context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// ResolveRevision mock
+func (m *MockRepo) ResolveRevision(rev string) (plumbing.Hash, error) {
+	args := m.Called(rev)
+	return args.Get(0).(plumbing.Hash), args.Error(1)
+}
+
+// ReadFile mock
+func (m *MockRepo) ReadFile(h plumbing.Hash, name string) ([]byte, error) {
+	args := m.Called(h, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
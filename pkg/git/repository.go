@@ -31,6 +31,13 @@ type Repository interface {
 	Worktree() (*git.Worktree, error)
 	CommitObject(h plumbing.Hash) (*object.Commit, error)
 	Reset(opts *git.ResetOptions) error
+	Checkout(opts *git.CheckoutOptions) error
+	RemoteURL(name string) (string, error)
+	Fetch(ctx context.Context) error
+	ResolveRevision(rev string) (plumbing.Hash, error)
+	ReadFile(h plumbing.Hash, name string) ([]byte, error)
+	// New returns a repository of the same kind which is not opened yet. A repository must not be shared between goroutines.
+	New() Repository
 }
 
 type repository struct {
@@ -42,6 +49,10 @@ func NewRepository() Repository {
 	return &repository{}
 }
 
+func (r *repository) New() Repository {
+	return NewRepository()
+}
+
 func (r *repository) Open(path string) error {
 	gitRepo, err := git.PlainOpen(path)
 	if err != nil {
@@ -99,6 +110,73 @@ func (r *repository) Reset(opts *git.ResetOptions) error {
 	return nil
 }
 
+func (r *repository) Checkout(opts *git.CheckoutOptions) error {
+	w, err := r.Worktree()
+	if err != nil {
+		return err
+	}
+	if err := w.Checkout(opts); err != nil {
+		return fmt.Errorf("unable to perform `git checkout`: %s", err.Error())
+	}
+	return nil
+}
+
+func (r *repository) RemoteURL(name string) (string, error) {
+	if r.gitRepo == nil {
+		return "", fmt.Errorf("repository is not yet initialized")
+	}
+	remote, err := r.gitRepo.Remote(name)
+	if err != nil {
+		return "", err
+	}
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", fmt.Errorf("remote %q has no URL", name)
+	}
+	return urls[0], nil
+}
+
+func (r *repository) Fetch(ctx context.Context) error {
+	if r.gitRepo == nil {
+		return fmt.Errorf("repository is not yet initialized")
+	}
+	return translateError(r.gitRepo.FetchContext(ctx, &git.FetchOptions{RemoteName: DefaultRemoteName, Tags: git.AllTags}), "Unable to fetch updates (%w)")
+}
+
+// ResolveRevision resolves a branch, tag or commit to a commit hash.
+// Remote-tracking branches take precedence over local ones, so that a fetched branch resolves to its latest commit.
+func (r *repository) ResolveRevision(rev string) (plumbing.Hash, error) {
+	if r.gitRepo == nil {
+		return plumbing.ZeroHash, fmt.Errorf("repository is not yet initialized")
+	}
+	for _, candidate := range []string{DefaultRemoteName + "/" + rev, rev} {
+		if h, err := r.gitRepo.ResolveRevision(plumbing.Revision(candidate)); err == nil {
+			return *h, nil
+		}
+	}
+	return plumbing.ZeroHash, fmt.Errorf("unable to resolve revision %q", rev)
+}
+
+// ReadFile returns the content of a file in the tree of the given commit
+func (r *repository) ReadFile(h plumbing.Hash, name string) ([]byte, error) {
+	if r.gitRepo == nil {
+		return nil, fmt.Errorf("repository is not yet initialized")
+	}
+	commit, err := r.gitRepo.CommitObject(h)
+	if err != nil {
+		return nil, err
+	}
+	file, err := commit.File(name)
+	if err != nil {
+		return nil, err
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
 func translateError(err error, defaultErrorFormat string) error {
 	if err == nil {
 		return nil
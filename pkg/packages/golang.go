@@ -54,15 +54,11 @@ func (l *langManager) installGolang(ctx context.Context, dir, ver string, comman
 	}
 
 	if goPath := os.Getenv("GOPATH"); goPath != "" {
-		cliPath = fmt.Sprintf("%s%d%s", goPath, os.PathListSeparator, cliPath)
+		cliPath = goPath + string(os.PathListSeparator) + cliPath
 	}
+	env := append(os.Environ(), "GOPATH="+cliPath)
 
-	if err := os.Setenv("GOPATH", cliPath); err != nil {
-		logger.Error(fmt.Sprintf("Unable to set GOPATH: %v", err))
-		return err
-	}
-
-	if err = installGolangModules(logger, l.commandExecutor, dir); err != nil {
+	if err = installGolangModules(logger, l.commandExecutor, dir, env); err != nil {
 		return err
 	}
 
@@ -87,6 +83,7 @@ func (l *langManager) installGolang(ctx context.Context, dir, ver string, comman
 		cmd = exec.Command(goBin, params...)
 
 		cmd.Dir = dir
+		cmd.Env = env
 		logger.Debug(fmt.Sprintf("building with command: %+v", cmd))
 		_, err = l.commandExecutor.ExecCommand(cmd)
 		if err != nil {
@@ -101,7 +98,7 @@ func (l *langManager) installGolang(ctx context.Context, dir, ver string, comman
 	return nil
 }
 
-func installGolangModules(logger *slog.Logger, cmdExecutor executor, dir string) error {
+func installGolangModules(logger *slog.Logger, cmdExecutor executor, dir string, env []string) error {
 	bin, err := cmdExecutor.LookPath("go")
 	if err != nil {
 		err = fmt.Errorf("%w: %s. Please verify if the executable is included in your PATH", ErrRuntimeNotFound, "go")
@@ -117,6 +114,7 @@ func installGolangModules(logger *slog.Logger, cmdExecutor executor, dir string)
 		moduleName := filepath.Base(dir)
 		cmd := exec.Command(bin, "mod", "init", moduleName)
 		cmd.Dir = dir
+		cmd.Env = env
 		_, err = cmdExecutor.ExecCommand(cmd)
 		if err != nil {
 			var exitErr *exec.ExitError
@@ -129,6 +127,7 @@ func installGolangModules(logger *slog.Logger, cmdExecutor executor, dir string)
 	logger.Info("go.sum found, running go module package manager")
 	cmd := exec.Command(bin, "mod", "tidy")
 	cmd.Dir = dir
+	cmd.Env = env
 	_, err = cmdExecutor.ExecCommand(cmd)
 	if err != nil {
 		var exitErr *exec.ExitError
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
@@ -13,6 +14,10 @@ import (
 )
 
 func TestInstallGolang(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("AKAMAI_CLI_HOME", home)
+	t.Setenv("GOPATH", "/test/gopath")
+	goEnv := append(os.Environ(), "GOPATH=/test/gopath"+string(os.PathListSeparator)+filepath.Join(home, ".akamai-cli"))
 	tests := map[string]struct {
 		givenDir      string
 		givenVer      string
@@ -34,11 +39,13 @@ func TestInstallGolang(t *testing.T) {
 					Path: "/test/go",
 					Args: []string{"/test/go", "mod", "tidy"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test", "."},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 			},
 		},
@@ -55,11 +62,13 @@ func TestInstallGolang(t *testing.T) {
 					Path: "/test/go",
 					Args: []string{"/test/go", "mod", "tidy"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test", `-ldflags=-X 'github.com/akamai/cli-test/cli.Version=0.1.0'`, "."},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 			},
 		},
@@ -76,16 +85,19 @@ func TestInstallGolang(t *testing.T) {
 					Path: "/test/go",
 					Args: []string{"/test/go", "mod", "tidy"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test1", "./test1"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test2", "./test2"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 			},
 		},
@@ -113,6 +125,7 @@ func TestInstallGolang(t *testing.T) {
 					Path: "/test/go",
 					Args: []string{"/test/go", "mod", "tidy"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, &exec.ExitError{})
 			},
 			withError: ErrPackageManagerExec,
@@ -134,11 +147,13 @@ func TestInstallGolang(t *testing.T) {
 					Path: "/test/go",
 					Args: []string{"/test/go", "mod", "tidy"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test", "."},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 			},
 		},
@@ -193,11 +208,13 @@ func TestInstallGolang(t *testing.T) {
 					Path: "/test/go",
 					Args: []string{"/test/go", "mod", "tidy"},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, nil)
 				m.On("ExecCommand", &exec.Cmd{
 					Path: "/test/go",
 					Args: []string{"/test/go", "build", "-o", "akamai-test", "."},
 					Dir:  "testDir",
+					Env:  goEnv,
 				}).Return(nil, &exec.ExitError{})
 			},
 			withError: ErrPackageCompileFailure,
@@ -211,6 +228,7 @@ func TestInstallGolang(t *testing.T) {
 			l := langManager{m}
 			err := l.installGolang(context.Background(), test.givenDir, test.givenVer, test.givenCommands, test.givenLdFlags)
 			m.AssertExpectations(t)
+			assert.Equal(t, "/test/gopath", os.Getenv("GOPATH"))
 			if test.withError != nil {
 				assert.True(t, errors.Is(err, test.withError), "want: %s; got: %s", test.withError, err)
 				return
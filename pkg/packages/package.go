@@ -203,6 +203,12 @@ func (l *langManager) GetOS() string {
 	return l.commandExecutor.GetOS()
 }
 
+// Runtime returns the language the package is installed with, or Undefined if there are no requirements,
+// and the minimum version of its runtime
+func (reqs LanguageRequirements) Runtime() (string, string) {
+	return determineLangAndRequirements(reqs)
+}
+
 func determineLangAndRequirements(reqs LanguageRequirements) (string, string) {
 	if reqs.Php != "" {
 		return PHP, reqs.Php
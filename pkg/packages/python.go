@@ -479,13 +479,10 @@ func installPythonDepsPip(ctx context.Context, cmdExecutor executor, bin, dir st
 	}
 	logger.Info("requirements.txt found, running pip package manager")
 
-	if err := os.Setenv("PYTHONUSERBASE", dir); err != nil {
-		logger.Error(fmt.Sprintf("Failed to set environment for the key %s in %s: %v", "PYTHONUSERBASE", dir, err))
-		return err
-	}
 	args := []string{bin, "install", "--user", "--ignore-installed", "-r", filepath.Join(dir, "requirements.txt")}
 	cmd := exec.Command(args[0], args[1:]...)
 	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "PYTHONUSERBASE="+dir)
 	if _, err := cmdExecutor.ExecCommand(cmd); err != nil {
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
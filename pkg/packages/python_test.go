@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"testing"
@@ -469,6 +470,7 @@ python3.14 -m venv: error: the following arguments are required: ENV_DIR
 				m.On("ExecCommand", &exec.Cmd{
 					Path: pip2Bin,
 					Args: []string{pip2Bin, "install", "--user", "--ignore-installed", "-r", requirementsFile},
+					Env:  append(os.Environ(), "PYTHONUSERBASE=testDir"),
 					Dir:  "testDir",
 				}).Return([]byte(py2Version), nil).Once()
 			},
@@ -486,6 +488,7 @@ python3.14 -m venv: error: the following arguments are required: ENV_DIR
 				m.On("ExecCommand", &exec.Cmd{
 					Path: pip2Bin,
 					Args: []string{pip2Bin, "install", "--user", "--ignore-installed", "-r", requirementsFile},
+					Env:  append(os.Environ(), "PYTHONUSERBASE="+srcDir),
 					Dir:  srcDir,
 				}).Return([]byte(py2Version), nil).Once()
 				m.On("LookPath", "pip2").Return(pip2Bin, nil).Once()
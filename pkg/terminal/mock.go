@@ -50,6 +50,12 @@ func (m *Mock) Confirm(p string, d bool) (bool, error) {
 	return args.Bool(0), args.Error(1)
 }
 
+// Password mock implementation
+func (m *Mock) Password(p string) (string, error) {
+	args := m.Called(p)
+	return args.String(0), args.Error(1)
+}
+
 // Spinner mock implementation
 func (m *Mock) Spinner() Spinner {
 	args := m.Called()
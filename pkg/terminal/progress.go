@@ -0,0 +1,238 @@
+package terminal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	spnr "github.com/briandowns/spinner"
+)
+
+const progressRefreshInterval = 100 * time.Millisecond
+
+type (
+	// Progress displays the status of several concurrent tasks, one line per task.
+	// When live, the lines are redrawn in place; otherwise a line is written each time a task step finishes.
+	Progress struct {
+		mu    sync.Mutex
+		out   io.Writer
+		live  bool
+		lines []*ProgressLine
+		drawn int
+		frame int
+		stop  chan struct{}
+		done  chan struct{}
+	}
+
+	// ProgressLine is a Spinner showing the status of a single task of a Progress
+	ProgressLine struct {
+		progress *Progress
+		label    string
+		prefix   string
+		suffix   string
+		status   SpinnerStatus
+		started  bool
+	}
+
+	progressTerminal struct {
+		Terminal
+		line *ProgressLine
+	}
+)
+
+// NewProgress returns a progress display writing to out
+func NewProgress(out io.Writer, live bool) *Progress {
+	return &Progress{out: out, live: live}
+}
+
+// Start starts redrawing a live progress display
+func (p *Progress) Start() {
+	if !p.live {
+		return
+	}
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(progressRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.mu.Lock()
+				p.frame++
+				p.render()
+				p.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// Stop stops redrawing the progress display, leaving the final status of each task on screen
+func (p *Progress) Stop() {
+	if !p.live || p.stop == nil {
+		return
+	}
+	close(p.stop)
+	<-p.done
+	p.stop = nil
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.render()
+}
+
+// Line adds a line for a task with the given label
+func (p *Progress) Line(label string) *ProgressLine {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	line := &ProgressLine{progress: p, label: label}
+	p.lines = append(p.lines, line)
+	return line
+}
+
+// Terminal returns a terminal whose spinner is a new line of the progress display labeled with label.
+// Anything written to the terminal is printed above the progress display.
+func (p *Progress) Terminal(term Terminal, label string) Terminal {
+	return &progressTerminal{Terminal: term, line: p.Line(label)}
+}
+
+// Write prints v above the progress display
+func (p *Progress) Write(v []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	n, err := p.out.Write(v)
+	p.render()
+	return n, err
+}
+
+// clear erases the lines drawn by the last render. It must be called with the lock held.
+func (p *Progress) clear() {
+	if !p.live || p.drawn == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(p.out, "\033[%dA\033[J", p.drawn)
+	p.drawn = 0
+}
+
+// render draws all started lines. It must be called with the lock held.
+func (p *Progress) render() {
+	if !p.live {
+		return
+	}
+	p.clear()
+	chars := spnr.CharSets[33]
+	for _, line := range p.lines {
+		if !line.started {
+			continue
+		}
+		text := line.text()
+		if line.status == "" {
+			text += " " + chars[p.frame%len(chars)] + line.suffix + "\n"
+		}
+		_, _ = fmt.Fprint(p.out, text)
+		p.drawn++
+	}
+}
+
+func (l *ProgressLine) text() string {
+	text := l.prefix
+	if l.label != "" {
+		text = l.label + ": " + text
+	}
+	if l.status != "" {
+		text += " " + string(l.status)
+	}
+	return text
+}
+
+// Start sets the current step of the task
+func (l *ProgressLine) Start(f string, args ...interface{}) {
+	p := l.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	l.prefix = fmt.Sprintf(f, args...)
+	l.suffix = ""
+	l.status = ""
+	l.started = true
+}
+
+// Stop finishes the current step of the task with the given status
+func (l *ProgressLine) Stop(status SpinnerStatus) {
+	p := l.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	l.suffix = ""
+	l.status = status
+	l.started = true
+	if !p.live {
+		_, _ = fmt.Fprint(p.out, l.text())
+	}
+}
+
+// Write implements the io.Writer interface and updates the suffix of the line
+func (l *ProgressLine) Write(v []byte) (int, error) {
+	p := l.progress
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	l.suffix = " " + strings.TrimSpace(string(v))
+	return len(v), nil
+}
+
+// OK stops the line with ok status
+func (l *ProgressLine) OK() {
+	l.Stop(SpinnerStatusOK)
+}
+
+// WarnOK stops the line with WarnOK status
+func (l *ProgressLine) WarnOK() {
+	l.Stop(SpinnerStatusWarnOK)
+}
+
+// Warn stops the line with Warn status
+func (l *ProgressLine) Warn() {
+	l.Stop(SpinnerStatusWarn)
+}
+
+// Fail stops the line with fail status
+func (l *ProgressLine) Fail() {
+	l.Stop(SpinnerStatusFail)
+}
+
+// Spinner returns the progress line of the terminal
+func (t *progressTerminal) Spinner() Spinner {
+	return t.line
+}
+
+// Printf writes a formatted message above the progress display
+func (t *progressTerminal) Printf(f string, args ...interface{}) {
+	_, _ = fmt.Fprintf(t.line.progress, f, args...)
+}
+
+// Writeln writes a line above the progress display
+func (t *progressTerminal) Writeln(args ...interface{}) (int, error) {
+	return fmt.Fprintln(t.line.progress, args...)
+}
+
+func (t *progressTerminal) Write(v []byte) (int, error) {
+	return t.line.progress.Write(v)
+}
+
+// WriteErrorf writes a formatted message above the progress display
+func (t *progressTerminal) WriteErrorf(f string, args ...interface{}) {
+	t.Printf(f, args...)
+}
+
+// WriteError writes a message above the progress display
+func (t *progressTerminal) WriteError(v interface{}) {
+	message := fmt.Sprint(v)
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+	t.Printf("%s", message)
+}
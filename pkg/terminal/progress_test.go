@@ -0,0 +1,53 @@
+package terminal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress(t *testing.T) {
+	t.Run("write finished steps when not live", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		progress := NewProgress(out, false)
+		progress.Start()
+
+		first := progress.Terminal(New(DiscardWriter(), nil, DiscardWriter()), "cli-first")
+		second := progress.Terminal(New(DiscardWriter(), nil, DiscardWriter()), "cli-second")
+
+		first.Spinner().Start("Updating %s...", "first")
+		second.Spinner().Start("Updating %s...", "second")
+		second.Printf("message from %s\n", "second")
+		second.Spinner().Fail()
+		first.Spinner().OK()
+		progress.Stop()
+
+		assert.Equal(t, "message from second\n"+
+			"cli-second: Updating second... "+string(SpinnerStatusFail)+
+			"cli-first: Updating first... "+string(SpinnerStatusOK), out.String())
+	})
+
+	t.Run("redraw lines in place when live", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		progress := NewProgress(out, true)
+
+		first := progress.Line("cli-first")
+		second := progress.Line("cli-second")
+		first.Start("Updating...")
+		second.Start("Updating...")
+
+		progress.mu.Lock()
+		progress.render()
+		progress.mu.Unlock()
+		assert.Equal(t, 2, progress.drawn)
+
+		first.OK()
+		_, err := progress.Write([]byte("message\n"))
+		assert.NoError(t, err)
+
+		lastRender := out.String()[strings.LastIndex(out.String(), "\033[2A\033[J"):]
+		assert.True(t, strings.HasPrefix(lastRender, "\033[2A\033[Jmessage\ncli-first: Updating... "+string(SpinnerStatusOK)+"cli-second: Updating... "))
+	})
+}
@@ -41,6 +41,7 @@ type (
 	Prompter interface {
 		Prompt(p string, options ...string) (string, error)
 		Confirm(p string, d bool) (bool, error)
+		Password(p string) (string, error)
 	}
 
 	// Writer provides a minimal interface for Stdin.
@@ -171,6 +172,13 @@ func (t *DefaultTerminal) Prompt(p string, options ...string) (string, error) {
 	return answers.Q, nil
 }
 
+// Password asks the user for a value which is not echoed, such as a passphrase
+func (t *DefaultTerminal) Password(p string) (string, error) {
+	var answer string
+	err := survey.AskOne(&survey.Password{Message: p}, &answer, survey.WithStdio(t.in, t.out, t.err), survey.WithValidator(survey.Required))
+	return answer, err
+}
+
 // Confirm asks the user for a Y/n response, with a default
 func (t *DefaultTerminal) Confirm(p string, def bool) (bool, error) {
 	rval := def
@@ -265,3 +273,27 @@ func ShowBanner(ctx context.Context) {
 		term.WriteError(err.Error())
 	}
 }
+
+type errorOnlyTerminal struct {
+	Terminal
+}
+
+// ErrorOnly returns a terminal writing everything to the error stream of term.
+// It keeps the output stream free for documents meant to be read by other programs.
+func ErrorOnly(term Terminal) Terminal {
+	return &errorOnlyTerminal{Terminal: term}
+}
+
+// Printf writes a formatted message to the error stream
+func (t *errorOnlyTerminal) Printf(f string, args ...interface{}) {
+	t.WriteErrorf(f, args...)
+}
+
+// Writeln writes a line to the error stream
+func (t *errorOnlyTerminal) Writeln(args ...interface{}) (int, error) {
+	return fmt.Fprintln(t.Error(), args...)
+}
+
+func (t *errorOnlyTerminal) Write(v []byte) (int, error) {
+	return t.Error().Write(v)
+}
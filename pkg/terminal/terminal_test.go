@@ -1,6 +1,7 @@
 package terminal
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"os"
@@ -245,3 +246,27 @@ func TestShowBanner(t *testing.T) {
 	require.NoError(t, err)
 	assert.Contains(t, string(data), "Welcome to Akamai CLI")
 }
+
+func TestErrorOnly(t *testing.T) {
+	out, err := os.CreateTemp("", t.Name())
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, out.Close())
+		require.NoError(t, os.Remove(out.Name())) // clean up
+	}()
+
+	errOut := &bytes.Buffer{}
+	term := ErrorOnly(New(out, nil, errOut))
+
+	term.Printf("test: %s\n", "abc")
+	_, err = term.Writeln("line")
+	require.NoError(t, err)
+	_, err = term.Write([]byte("raw"))
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(out)
+	require.NoError(t, err)
+	assert.Empty(t, data)
+	assert.Equal(t, "test: abc\nline\nraw", errOut.String())
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 )
 
 // MoveFile must copy+unlink the file because moving files is broken across filesystems
@@ -52,3 +53,85 @@ func MoveFile(src, dst string) (err error) {
 	err = os.Remove(src)
 	return err
 }
+
+// WriteFileAtomic writes data to a temporary file next to path and renames it into place,
+// so readers never see a partially written file
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	if _, err = f.Write(data); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(f.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// CopyDir recursively copies the src directory to dst, preserving file modes and symbolic links
+func CopyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		default:
+			return copyRegularFile(path, target, info.Mode().Perm())
+		}
+	})
+}
+
+func copyRegularFile(src, dst string, perm os.FileMode) (err error) {
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if e := source.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	destination, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if e := destination.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	_, err = io.Copy(destination, source)
+	return err
+}
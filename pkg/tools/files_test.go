@@ -0,0 +1,38 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	tests := map[string]struct {
+		existing string
+	}{
+		"new file":         {},
+		"replace existing": {existing: "previous content which is longer"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "config")
+			if test.existing != "" {
+				require.NoError(t, os.WriteFile(path, []byte(test.existing), 0644))
+			}
+
+			require.NoError(t, WriteFileAtomic(path, []byte("content"), 0600))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, "content", string(data))
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Len(t, entries, 1)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// ErrLockTimeout is returned when a lock held by another process is not released in time
+var ErrLockTimeout = errors.New("timed out waiting for another akamai process to finish")
+
+const lockRetryInterval = 100 * time.Millisecond
+
+// FileLock is an advisory lock on a file, held until Unlock is called or the process exits
+type FileLock struct {
+	file *os.File
+}
+
+// LockFile acquires an exclusive advisory lock on the file at path, creating it if needed.
+// If another process holds the lock, onWait is called once and the lock is retried until timeout elapses.
+func LockFile(ctx context.Context, path string, timeout time.Duration, onWait func()) (*FileLock, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for waiting := false; ; waiting = true {
+		ok, err := tryLock(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		if ok {
+			return &FileLock{file: f}, nil
+		}
+		if !time.Now().Before(deadline) {
+			_ = f.Close()
+			return nil, ErrLockTimeout
+		}
+		if !waiting && onWait != nil {
+			onWait()
+		}
+		select {
+		case <-ctx.Done():
+			_ = f.Close()
+			return nil, ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// Unlock releases the lock
+func (l *FileLock) Unlock() error {
+	if err := unlock(l.file); err != nil {
+		_ = l.file.Close()
+		return err
+	}
+	return l.file.Close()
+}
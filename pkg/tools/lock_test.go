@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".lock")
+	ctx := context.Background()
+
+	lock, err := LockFile(ctx, path, 0, nil)
+	require.NoError(t, err)
+
+	var waited bool
+	_, err = LockFile(ctx, path, 200*time.Millisecond, func() { waited = true })
+	assert.ErrorIs(t, err, ErrLockTimeout)
+	assert.True(t, waited)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = LockFile(cancelled, path, time.Minute, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	released := make(chan struct{})
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		assert.NoError(t, lock.Unlock())
+		close(released)
+	}()
+	second, err := LockFile(ctx, path, time.Minute, nil)
+	require.NoError(t, err)
+	<-released
+	assert.NoError(t, second.Unlock())
+}
@@ -50,6 +50,26 @@ func GetAkamaiCliSrcPath() (string, error) {
 	return filepath.Join(cliHome, "src"), nil
 }
 
+// GetAkamaiCliHistoryPath returns $AKAMAI_CLI_HOME/.akamai-cli/history, where previous package installs are kept
+func GetAkamaiCliHistoryPath() (string, error) {
+	cliHome, err := GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cliHome, "history"), nil
+}
+
+// GetAkamaiCliKeysPath returns $AKAMAI_CLI_HOME/.akamai-cli/keys, where public keys trusted to sign package binaries are kept
+func GetAkamaiCliKeysPath() (string, error) {
+	cliHome, err := GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cliHome, "keys"), nil
+}
+
 // GetAkamaiCliVenvPath - returns the .akamai-cli/venv path, for Python virtualenv
 func GetAkamaiCliVenvPath() (string, error) {
 	cliHome, err := GetAkamaiCliPath()
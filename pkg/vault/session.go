@@ -0,0 +1,126 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/akamai/cli/v2/pkg/terminal"
+)
+
+// EnvPassphrase is the environment variable holding the vault passphrase, used instead of prompting for it
+const EnvPassphrase = "AKAMAI_CLI_VAULT_PASSPHRASE"
+
+type (
+	// PassphraseFunc returns the passphrase of the vault. Create is true when the vault does not exist yet,
+	// so that the passphrase can be confirmed.
+	PassphraseFunc func(create bool) (string, error)
+
+	// Session unlocks the vault on first use, so that the passphrase is asked for at most once per run,
+	// and only when a secret is actually needed
+	Session struct {
+		path       string
+		passphrase PassphraseFunc
+
+		mu    sync.Mutex
+		vault *Vault
+		err   error
+	}
+
+	contextType string
+)
+
+var sessionContext contextType = "vault"
+
+// NewSession creates a session for the vault file at given path
+func NewSession(path string, passphrase PassphraseFunc) *Session {
+	return &Session{path: path, passphrase: passphrase}
+}
+
+// Path returns the location of the vault file
+func (s *Session) Path() string {
+	return s.path
+}
+
+// Exists checks whether the vault file was created
+func (s *Session) Exists() bool {
+	return Exists(s.path)
+}
+
+// Unlock asks for the passphrase and decrypts the vault, or returns the vault unlocked earlier.
+// A failed unlock is not retried, so that a wrong passphrase is not asked for again for every secret.
+func (s *Session) Unlock() (*Vault, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.vault != nil || s.err != nil {
+		return s.vault, s.err
+	}
+	passphrase, err := s.passphrase(!s.Exists())
+	if err == nil {
+		s.vault, err = Open(s.path, passphrase)
+	}
+	if err != nil {
+		s.err = fmt.Errorf("unable to unlock vault: %w", err)
+	}
+	return s.vault, s.err
+}
+
+// Resolve replaces a secret reference with the secret. Other values are returned as is.
+func (s *Session) Resolve(value string) (string, error) {
+	name, ok := ParseRef(value)
+	if !ok {
+		return value, nil
+	}
+	if !s.Exists() {
+		return "", fmt.Errorf("%w: %s, no vault at %s", ErrSecretNotFound, name, s.path)
+	}
+	v, err := s.Unlock()
+	if err != nil {
+		return "", err
+	}
+	return v.Get(name)
+}
+
+// PromptPassphrase reads the passphrase from AKAMAI_CLI_VAULT_PASSPHRASE, or asks for it in interactive sessions.
+// A new passphrase is asked for twice.
+func PromptPassphrase(term terminal.Terminal) PassphraseFunc {
+	return func(create bool) (string, error) {
+		if passphrase := os.Getenv(EnvPassphrase); passphrase != "" {
+			return passphrase, nil
+		}
+		if !term.IsTTY() {
+			return "", fmt.Errorf("set %s to unlock the vault in non-interactive sessions", EnvPassphrase)
+		}
+		if !create {
+			return term.Password("Vault passphrase")
+		}
+		passphrase, err := term.Password("New vault passphrase")
+		if err != nil {
+			return "", err
+		}
+		confirmed, err := term.Password("Confirm vault passphrase")
+		if err != nil {
+			return "", err
+		}
+		if passphrase != confirmed {
+			return "", errors.New("passphrases do not match")
+		}
+		return passphrase, nil
+	}
+}
+
+// Context sets the vault session in the context
+func Context(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, sessionContext, s)
+}
+
+// Get gets the vault session from the context
+func Get(ctx context.Context) *Session {
+	s, ok := ctx.Value(sessionContext).(*Session)
+	if !ok {
+		panic(errors.New("context does not have a vault Session"))
+	}
+	return s
+}
@@ -0,0 +1,227 @@
+// Package vault stores secrets in a local file encrypted with a key derived from a passphrase.
+// Config and credentials file values can reference a secret as secret://<name>, the reference being replaced by the
+// secret when the value is used.
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/akamai/cli/v2/pkg/tools"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// RefPrefix starts values referencing a secret, such as secret://prod/client_secret
+	RefPrefix = "secret://"
+	// FileName is the name of the vault file in the CLI home
+	FileName = "secrets.vault"
+
+	fileVersion = 1
+	kdfArgon2id = "argon2id"
+	keyLength   = 32
+	saltLength  = 16
+)
+
+type (
+	// Vault holds the decrypted secrets of a vault file
+	Vault struct {
+		path    string
+		key     []byte
+		params  kdfParams
+		secrets map[string]string
+	}
+
+	// file is the encrypted form of a vault, the secrets being sealed with AES-256-GCM
+	file struct {
+		Version int       `json:"version"`
+		KDF     kdfParams `json:"kdf"`
+		Nonce   []byte    `json:"nonce"`
+		Data    []byte    `json:"data"`
+	}
+
+	kdfParams struct {
+		Name    string `json:"name"`
+		Salt    []byte `json:"salt"`
+		Time    uint32 `json:"time"`
+		Memory  uint32 `json:"memory"`
+		Threads uint8  `json:"threads"`
+	}
+)
+
+var (
+	// ErrWrongPassphrase is returned when the vault cannot be decrypted with given passphrase
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupted vault")
+	// ErrSecretNotFound is returned when the vault does not contain a secret
+	ErrSecretNotFound = errors.New("secret not found")
+
+	nameRegexp = regexp.MustCompile(`^[A-Za-z0-9_.-]+(/[A-Za-z0-9_.-]+)*$`)
+
+	// defaultParams are used when creating a vault, tests lower them to keep key derivation fast
+	defaultParams = kdfParams{Name: kdfArgon2id, Time: 3, Memory: 64 * 1024, Threads: 4}
+)
+
+// DefaultPath returns the location of the vault file in the CLI home
+func DefaultPath() (string, error) {
+	cliPath, err := tools.GetAkamaiCliPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cliPath, FileName), nil
+}
+
+// Exists checks whether a vault file was created at given path
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Open decrypts the vault file with the passphrase. A missing file is treated as an empty vault,
+// which is encrypted with the passphrase when saved.
+func Open(path, passphrase string) (*Vault, error) {
+	if passphrase == "" {
+		return nil, errors.New("vault passphrase cannot be empty")
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		params := defaultParams
+		params.Salt = make([]byte, saltLength)
+		if _, err := rand.Read(params.Salt); err != nil {
+			return nil, errors.New("unable to generate vault salt")
+		}
+		return &Vault{path: path, key: deriveKey(passphrase, params), params: params, secrets: make(map[string]string)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read vault %s: %w", path, err)
+	}
+
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid vault %s: %w", path, err)
+	}
+	if f.Version != fileVersion || f.KDF.Name != kdfArgon2id {
+		return nil, fmt.Errorf("unsupported vault %s: version %d, key derivation %s", path, f.Version, f.KDF.Name)
+	}
+	key := deriveKey(passphrase, f.KDF)
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, f.Nonce, f.Data, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	secrets := make(map[string]string)
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("invalid vault %s: %w", path, err)
+	}
+	return &Vault{path: path, key: key, params: f.KDF, secrets: secrets}, nil
+}
+
+// Path returns the location of the vault file
+func (v *Vault) Path() string {
+	return v.path
+}
+
+// Names returns the names of the secrets, sorted by name
+func (v *Vault) Names() []string {
+	names := make([]string, 0, len(v.secrets))
+	for name := range v.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the value of a secret
+func (v *Vault) Get(name string) (string, error) {
+	value, ok := v.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	return value, nil
+}
+
+// Set adds or replaces a secret. Names are made of letters, digits, '.', '-' and '_', and may be grouped with '/', such as prod/client_secret.
+func (v *Vault) Set(name, value string) error {
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q, only letters, digits, '.', '-', '_' and '/' separating groups are allowed", name)
+	}
+	v.secrets[name] = value
+	return nil
+}
+
+// Delete removes a secret
+func (v *Vault) Delete(name string) error {
+	if _, ok := v.secrets[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	delete(v.secrets, name)
+	return nil
+}
+
+// Save encrypts the secrets with a new nonce and writes the vault atomically, readable by the owner only
+func (v *Vault) Save() error {
+	plaintext, err := json.Marshal(v.secrets)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(v.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return errors.New("unable to generate vault nonce")
+	}
+	data, err := json.MarshalIndent(file{
+		Version: fileVersion,
+		KDF:     v.params,
+		Nonce:   nonce,
+		Data:    gcm.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(v.path), 0700); err != nil {
+		return err
+	}
+	return tools.WriteFileAtomic(v.path, data, 0600)
+}
+
+// Ref returns the value referencing a secret
+func Ref(name string) string {
+	return RefPrefix + name
+}
+
+// ParseRef returns the name of the secret referenced by a value. False is returned for values which are not references.
+func ParseRef(value string) (string, bool) {
+	name, ok := strings.CutPrefix(value, RefPrefix)
+	return name, ok
+}
+
+// IsRef checks whether a value references a secret
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, RefPrefix)
+}
+
+func deriveKey(passphrase string, p kdfParams) []byte {
+	return argon2.IDKey([]byte(passphrase), p.Salt, p.Time, p.Memory, p.Threads, keyLength)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,174 @@
+package vault
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/akamai/cli/v2/pkg/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	defaultParams = kdfParams{Name: kdfArgon2id, Time: 1, Memory: 64, Threads: 1}
+}
+
+func TestVault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+
+	v, err := Open(path, "passphrase")
+	require.NoError(t, err)
+	assert.Empty(t, v.Names())
+	require.NoError(t, v.Set("prod/client_secret", "s3cret"))
+	require.NoError(t, v.Set("token", "abc"))
+	assert.False(t, Exists(path))
+	require.NoError(t, v.Save())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cret")
+	assert.NotContains(t, string(data), "client_secret")
+
+	v, err = Open(path, "passphrase")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod/client_secret", "token"}, v.Names())
+	secret, err := v.Get("prod/client_secret")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", secret)
+
+	require.NoError(t, v.Delete("token"))
+	assert.True(t, errors.Is(v.Delete("token"), ErrSecretNotFound))
+	_, err = v.Get("token")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+
+	_, err = Open(path, "wrong")
+	assert.True(t, errors.Is(err, ErrWrongPassphrase))
+	_, err = Open(path, "")
+	assert.Error(t, err)
+}
+
+func TestVaultSetInvalidName(t *testing.T) {
+	v, err := Open(filepath.Join(t.TempDir(), FileName), "passphrase")
+	require.NoError(t, err)
+	for _, name := range []string{"", "/prod", "prod/", "prod//secret", "prod secret", "secret://prod"} {
+		assert.Error(t, v.Set(name, "value"), name)
+	}
+}
+
+func TestRef(t *testing.T) {
+	assert.Equal(t, "secret://prod/client_secret", Ref("prod/client_secret"))
+	name, ok := ParseRef("secret://prod/client_secret")
+	assert.True(t, ok)
+	assert.Equal(t, "prod/client_secret", name)
+	_, ok = ParseRef("akab-client-token")
+	assert.False(t, ok)
+	assert.True(t, IsRef("secret://token"))
+	assert.False(t, IsRef("token"))
+}
+
+func TestSessionResolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	calls := 0
+	s := NewSession(path, func(bool) (string, error) {
+		calls++
+		return "passphrase", nil
+	})
+	value, err := s.Resolve("plain")
+	require.NoError(t, err)
+	assert.Equal(t, "plain", value)
+	_, err = s.Resolve("secret://prod/client_secret")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+	assert.Equal(t, 0, calls)
+
+	v, err := Open(path, "passphrase")
+	require.NoError(t, err)
+	require.NoError(t, v.Set("prod/client_secret", "s3cret"))
+	require.NoError(t, v.Save())
+
+	for i := 0; i < 2; i++ {
+		value, err = s.Resolve("secret://prod/client_secret")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", value)
+	}
+	_, err = s.Resolve("secret://prod/missing")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+	assert.Equal(t, 1, calls)
+
+	wrong := NewSession(path, func(bool) (string, error) {
+		calls++
+		return "wrong", nil
+	})
+	for i := 0; i < 2; i++ {
+		_, err = wrong.Resolve("secret://prod/client_secret")
+		assert.True(t, errors.Is(err, ErrWrongPassphrase))
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestPromptPassphrase(t *testing.T) {
+	tests := map[string]struct {
+		env       string
+		create    bool
+		init      func(*terminal.Mock)
+		expected  string
+		withError bool
+	}{
+		"from environment": {
+			env:      "from-env",
+			init:     func(*terminal.Mock) {},
+			expected: "from-env",
+		},
+		"prompt": {
+			init: func(m *terminal.Mock) {
+				m.On("IsTTY").Return(true).Once()
+				m.On("Password", "Vault passphrase").Return("typed", nil).Once()
+			},
+			expected: "typed",
+		},
+		"prompt new passphrase": {
+			create: true,
+			init: func(m *terminal.Mock) {
+				m.On("IsTTY").Return(true).Once()
+				m.On("Password", "New vault passphrase").Return("typed", nil).Once()
+				m.On("Password", "Confirm vault passphrase").Return("typed", nil).Once()
+			},
+			expected: "typed",
+		},
+		"new passphrases do not match": {
+			create: true,
+			init: func(m *terminal.Mock) {
+				m.On("IsTTY").Return(true).Once()
+				m.On("Password", "New vault passphrase").Return("typed", nil).Once()
+				m.On("Password", "Confirm vault passphrase").Return("mistyped", nil).Once()
+			},
+			withError: true,
+		},
+		"not interactive": {
+			init: func(m *terminal.Mock) {
+				m.On("IsTTY").Return(false).Once()
+			},
+			withError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(EnvPassphrase, test.env)
+			term := &terminal.Mock{}
+			test.init(term)
+			passphrase, err := PromptPassphrase(term)(test.create)
+			term.AssertExpectations(t)
+			if test.withError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, passphrase)
+		})
+	}
+}